@@ -10,8 +10,25 @@ import (
 	"net/http"
 	"time"
 
+	"gitlab.com/pardalis/pardalis-api/configs"
 	"gitlab.com/pardalis/pardalis-api/middleware"
+	"gitlab.com/pardalis/pardalis-api/services/analytics"
+	"gitlab.com/pardalis/pardalis-api/services/category"
+	"gitlab.com/pardalis/pardalis-api/services/collaborator"
+	"gitlab.com/pardalis/pardalis-api/services/comment"
+	"gitlab.com/pardalis/pardalis-api/services/feed"
+	"gitlab.com/pardalis/pardalis-api/services/media"
+	"gitlab.com/pardalis/pardalis-api/services/moderation"
 	"gitlab.com/pardalis/pardalis-api/services/personalization"
+	"gitlab.com/pardalis/pardalis-api/services/reaction"
+	"gitlab.com/pardalis/pardalis-api/services/readinglist"
+	"gitlab.com/pardalis/pardalis-api/services/related"
+	"gitlab.com/pardalis/pardalis-api/services/search"
+	"gitlab.com/pardalis/pardalis-api/services/seo"
+	"gitlab.com/pardalis/pardalis-api/services/series"
+	"gitlab.com/pardalis/pardalis-api/services/tag"
+	"gitlab.com/pardalis/pardalis-api/services/transfer"
+	"gitlab.com/pardalis/pardalis-api/services/trash"
 
 	"github.com/gorilla/mux"
 	"gitlab.com/pardalis/pardalis-api/services/user"
@@ -53,17 +70,90 @@ func (s *APIServer) Start() error {
 
 	// Iniciamos la tienda de usuarios, que no tiene nada que ver con Amazon. 🛒
 	userStore := user.NewStore(s.db)
-	blogStore := blog.NewBlogStore(s.db)
-	personalizationStore := personalization.NewStore(s.db)
+	mediaStore := media.NewStore(s.db)
+	mediaStorage, err := media.NewStorage(configs.Envs)
+	if err != nil {
+		return err
+	}
+	// Las portadas y las fotos de perfil subidas llevan sus variantes
+	imageResolver := media.NewResolver(mediaStore, mediaStorage)
+	searchIndex := search.NewMemoryIndex()
+	blogStore := media.NewImageBlogStore(search.NewIndexedBlogStore(blog.NewBlogStore(s.db), searchIndex), imageResolver)
+	personalizationStore := media.NewImagePersonalizationStore(personalization.NewStore(s.db), imageResolver)
+	categoryStore := category.NewStore(s.db)
+	commentStore := comment.NewStore(s.db)
+	reactionStore := reaction.NewStore(s.db)
+	readingListStore := readinglist.NewStore(s.db)
+	analyticsStore := analytics.NewStore(s.db)
+	tagStore := tag.NewStore(s.db)
+	seriesStore := series.NewStore(s.db)
+	relatedStore := related.NewStore(s.db)
+	mediaProcessor := media.NewProcessor(mediaStore, mediaStorage)
+	collaboratorStore := collaborator.NewStore(s.db)
+	// Todos los permisos sobre un blog pasan por aquí: autor, coautores y editores
+	authorizer := collaborator.NewAuthorizer(collaboratorStore, blogStore)
+	moderator, err := moderation.NewModerator(configs.Envs)
+	if err != nil {
+		return err
+	}
+	moderationStore := moderation.NewStore(s.db)
+	// Blogs, comentarios y descripciones pasan por la moderación antes de guardarse
+	moderationPipeline := moderation.NewPipeline(moderator, moderationStore)
 	// Creamos el handler para los usuarios. Este será quien maneje todas esas solicitudes incómodas de registro. 🙇‍♂️
 	userHandler := user.NewHandler(userStore)
-	blogHandler := blog.NewBlogHandler(blogStore, userStore)
-	personalizationHandler := personalization.NewHandler(personalizationStore, userStore)
+	blogHandler := blog.NewBlogHandler(blogStore, userStore, categoryStore, seriesStore, searchIndex, analytics.NewTracker(analyticsStore), authorizer, moderationPipeline)
+	personalizationHandler := personalization.NewHandler(personalizationStore, userStore, moderationPipeline)
+	categoryHandler := category.NewHandler(categoryStore, userStore)
+	feedHandler := feed.NewHandler(blogStore)
+	seoHandler := seo.NewHandler(blogStore)
+	commentHandler := comment.NewHandler(commentStore, blogStore, userStore, authorizer, moderationPipeline)
+	reactionHandler := reaction.NewHandler(reactionStore, blogStore, userStore)
+	readingListHandler := readinglist.NewHandler(readingListStore, blogStore, userStore)
+	analyticsHandler := analytics.NewHandler(analyticsStore, blogStore, userStore, authorizer)
+	tagHandler := tag.NewHandler(tagStore, blogStore, userStore, searchIndex)
+	seriesHandler := series.NewHandler(seriesStore, blogStore, userStore)
+	relatedHandler := related.NewHandler(relatedStore, blogStore, searchIndex)
+	mediaHandler := media.NewHandler(mediaStore, mediaStorage, mediaProcessor, blogStore, personalizationStore, userStore, authorizer)
+	collaboratorHandler := collaborator.NewHandler(collaboratorStore, authorizer, userStore)
+	// Lo eliminado se puede restaurar durante TrashRetentionDays días
+	trashRetention := time.Duration(configs.Envs.TrashRetentionDays) * 24 * time.Hour
+	trashHandler := trash.NewHandler(blogStore, userStore, authorizer, searchIndex, trashRetention)
+	trashPurger := trash.NewPurger(blogStore, userStore, trashRetention)
+	transferHandler := transfer.NewHandler(blogStore, categoryStore, userStore)
+	moderationHandler := moderation.NewHandler(moderationStore, blogStore, commentStore, personalizationStore, userStore)
+
+	// Construimos el índice de búsqueda con los blogs ya publicados
+	if err := search.Rebuild(searchIndex, blogStore); err != nil {
+		return err
+	}
+
+	// Las variantes de las imágenes se generan en segundo plano
+	mediaProcessor.Start()
+	// La papelera se vacía sola pasado el periodo de retención
+	trashPurger.Start()
 
 	// Registramos todas las rutas relacionadas con usuarios, para que el subrouter pueda manejarlas como el ninja que es. 🥷
 	userHandler.RegisterRoutes(subrouter)
 	blogHandler.RegisterRoutes(subrouter)
 	personalizationHandler.RegisterRoutes(subrouter)
+	categoryHandler.RegisterRoutes(subrouter)
+	feedHandler.RegisterRoutes(subrouter)
+	seoHandler.RegisterRoutes(subrouter)
+	commentHandler.RegisterRoutes(subrouter)
+	reactionHandler.RegisterRoutes(subrouter)
+	readingListHandler.RegisterRoutes(subrouter)
+	analyticsHandler.RegisterRoutes(subrouter)
+	tagHandler.RegisterRoutes(subrouter)
+	seriesHandler.RegisterRoutes(subrouter)
+	relatedHandler.RegisterRoutes(subrouter)
+	mediaHandler.RegisterRoutes(subrouter)
+	collaboratorHandler.RegisterRoutes(subrouter)
+	trashHandler.RegisterRoutes(subrouter)
+	transferHandler.RegisterRoutes(subrouter)
+	moderationHandler.RegisterRoutes(subrouter)
+
+	// sitemap.xml y robots.txt viven en la raíz, donde los buscan los rastreadores
+	seoHandler.RegisterRootRoutes(router)
 
 	// Configurar el servidor con CORS
 	handler := corsMiddleware.Handler(router)
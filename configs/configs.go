@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv" // godotenv 🐄 – La herramienta que asegura que tus secretos nunca se queden secretos.
 )
@@ -15,14 +16,28 @@ import (
 // justo el tiempo suficiente para que lleguen las quejas de los usuarios.
 // Contiene todos esos hermosos parámetros que se perderán en la nube. ☁️🔥
 type Config struct {
-	PublicHost             string // PublicHost 🐄 – Dónde estará "disponible" tu aplicación, asumiendo que a alguien le importe.
-	Port                   string // Port 🐄 – El puerto favorito de tu aplicación, probablemente uno que ya esté en uso. 🙄
-	DBUser                 string // DBUser 🐄 – Porque usar 'root' como usuario de la base de datos siempre es una buena idea, ¿verdad? 😅
-	DBPassword             string // DBPassword 🐄 – La contraseña súper segura que compartes con todo el equipo... y con el mundo.
-	DBAddress              string // DBAddress 🐄 – La dirección de tu base de datos. ¿Qué podría salir mal?
-	DBName                 string // DBName 🐄 – El nombre de tu base de datos, porque 'default' es demasiado original.
-	JWTSecret              string // JWTSecret 🐄 – ¡Shhh! Es un secreto... excepto cuando no lo es. 🤐
-	JWTExpirationInSeconds int64  // JWTExpirationInSeconds 🐄 – Cuántos segundos durarán tus tokens JWT antes de expirar, o lo que es lo mismo, cuánto tiempo tienes hasta que todo se rompa. 🕒💥
+	PublicHost               string   // PublicHost 🐄 – Dónde estará "disponible" tu aplicación, asumiendo que a alguien le importe.
+	Port                     string   // Port 🐄 – El puerto favorito de tu aplicación, probablemente uno que ya esté en uso. 🙄
+	DBUser                   string   // DBUser 🐄 – Porque usar 'root' como usuario de la base de datos siempre es una buena idea, ¿verdad? 😅
+	DBPassword               string   // DBPassword 🐄 – La contraseña súper segura que compartes con todo el equipo... y con el mundo.
+	DBAddress                string   // DBAddress 🐄 – La dirección de tu base de datos. ¿Qué podría salir mal?
+	DBName                   string   // DBName 🐄 – El nombre de tu base de datos, porque 'default' es demasiado original.
+	JWTSecret                string   // JWTSecret 🐄 – ¡Shhh! Es un secreto... excepto cuando no lo es. 🤐
+	JWTExpirationInSeconds   int64    // JWTExpirationInSeconds 🐄 – Cuántos segundos durarán tus tokens JWT antes de expirar, o lo que es lo mismo, cuánto tiempo tienes hasta que todo se rompa. 🕒💥
+	MediaStorage             string   // MediaStorage 🐄 – Dónde acaban las imágenes subidas: "local" o "s3". Spoiler: en el disco que nadie respalda. 💾
+	MediaDir                 string   // MediaDir 🐄 – El directorio del almacenamiento local, a un rm -rf de desaparecer. 🗑️
+	MediaPublicURL           string   // MediaPublicURL 🐄 – La URL base de las imágenes. Vacía significa "que las sirva la API, que para eso está". 🖼️
+	S3Endpoint               string   // S3Endpoint 🐄 – El endpoint S3 o compatible (MinIO, por ejemplo), porque AWS no es la única nube que cobra. ☁️
+	S3Region                 string   // S3Region 🐄 – La región del bucket, que MinIO ignora con mucha elegancia. 🌍
+	S3Bucket                 string   // S3Bucket 🐄 – El bucket donde viven las imágenes, público o no según lo que recuerdes configurar. 🪣
+	S3AccessKey              string   // S3AccessKey 🐄 – La clave de acceso, compañera inseparable de la siguiente. 🔑
+	S3SecretKey              string   // S3SecretKey 🐄 – La clave secreta, tan secreta como el JWTSecret. 🤫
+	TrashRetentionDays       int64    // TrashRetentionDays 🐄 – Los días que pasa algo en la papelera antes de desaparecer de verdad. Después, ni llorando. 😭
+	Locales                  []string // Locales 🐄 – Los idiomas en los que se publica, el primero es el de siempre. El resto, para cuando alguien traduzca algo. 🌎
+	ModerationPolicy         []string // ModerationPolicy 🐄 – Qué se hace con cada regla de moderación: permitir, marcar, retener o rechazar. Para adultos, que se supone que saben lo que escriben. 🧐
+	ModerationPolicyMinors   []string // ModerationPolicyMinors 🐄 – Lo mismo para menores, con bastante menos paciencia. 🧒
+	ModerationWordsFile      string   // ModerationWordsFile 🐄 – Un archivo con más palabras prohibidas, por si la lista de serie se queda corta. Se quedará. 🤬
+	ModerationAllowedDomains []string // ModerationAllowedDomains 🐄 – Los dominios a los que se puede enlazar sin que salte la alarma. 🔗
 }
 
 // Envs 🐄 – Porque la palabra "environments" es demasiado larga.
@@ -36,14 +51,28 @@ func initConfig() Config {
 	godotenv.Load() // Carga el archivo .env, porque confiar en el entorno es sobrevalorado.
 
 	return Config{
-		PublicHost:             getEnv("PUBLIC_HOST", "http://localhost"),                                       // Configura el host público, que será ignorado por completo en producción.
-		Port:                   getEnv("PORT", "8080"),                                                          // Selecciona un puerto... que probablemente ya esté en uso. 🎉
-		DBUser:                 getEnv("DB_USER", "root"),                                                       // Usuario de la base de datos, porque 'root' es la elección de los campeones. 🏆
-		DBPassword:             getEnv("DB_PASSWORD", "mypassword"),                                             // Contraseña ultra segura. Definitivamente nadie la adivinará. 🙄
-		DBAddress:              fmt.Sprintf("%s:%s", getEnv("DB_HOST", "127.0.0.1"), getEnv("DB_PORT", "3306")), // Dirección de la base de datos, ¡esperemos que no haya cortafuegos! 🚧
-		DBName:                 getEnv("DB_NAME", "padalis"),                                                    // El nombre de tu base de datos, ¿Por qué Pardalis tendra futuro? 🐄
-		JWTSecret:              getEnv("JWT_SECRET", "not-so-secret-now-is-it?"),                                // Un secreto tan seguro que lo estamos documentando aquí. 🤫
-		JWTExpirationInSeconds: getEnvAsInt("JWT_EXPIRATION_IN_SECONDS", 3600*24*7),                             // Tiempo de expiración de los JWT, suficiente para que los hackers lo disfruten. 😈
+		PublicHost:               getEnv("PUBLIC_HOST", "http://localhost"),                                                              // Configura el host público, que será ignorado por completo en producción.
+		Port:                     getEnv("PORT", "8080"),                                                                                 // Selecciona un puerto... que probablemente ya esté en uso. 🎉
+		DBUser:                   getEnv("DB_USER", "root"),                                                                              // Usuario de la base de datos, porque 'root' es la elección de los campeones. 🏆
+		DBPassword:               getEnv("DB_PASSWORD", "mypassword"),                                                                    // Contraseña ultra segura. Definitivamente nadie la adivinará. 🙄
+		DBAddress:                fmt.Sprintf("%s:%s", getEnv("DB_HOST", "127.0.0.1"), getEnv("DB_PORT", "3306")),                        // Dirección de la base de datos, ¡esperemos que no haya cortafuegos! 🚧
+		DBName:                   getEnv("DB_NAME", "padalis"),                                                                           // El nombre de tu base de datos, ¿Por qué Pardalis tendra futuro? 🐄
+		JWTSecret:                getEnv("JWT_SECRET", "not-so-secret-now-is-it?"),                                                       // Un secreto tan seguro que lo estamos documentando aquí. 🤫
+		JWTExpirationInSeconds:   getEnvAsInt("JWT_EXPIRATION_IN_SECONDS", 3600*24*7),                                                    // Tiempo de expiración de los JWT, suficiente para que los hackers lo disfruten. 😈
+		MediaStorage:             getEnv("MEDIA_STORAGE", "local"),                                                                       // Almacenamiento de imágenes, local hasta que se llene el disco. 📦
+		MediaDir:                 getEnv("MEDIA_DIR", "uploads"),                                                                         // Directorio de subidas, relativo a donde sea que arranques esto. 📁
+		MediaPublicURL:           getEnv("MEDIA_PUBLIC_URL", ""),                                                                         // URL pública de las imágenes, o la propia API si la dejas vacía. 🔗
+		S3Endpoint:               getEnv("S3_ENDPOINT", ""),                                                                              // Endpoint S3, obligatorio si eliges s3 y te acuerdas. 🛰️
+		S3Region:                 getEnv("S3_REGION", "us-east-1"),                                                                       // La región por defecto de toda la vida. 🗺️
+		S3Bucket:                 getEnv("S3_BUCKET", ""),                                                                                // El bucket, que tendrás que crear tú. 🪣
+		S3AccessKey:              getEnv("S3_ACCESS_KEY", ""),                                                                            // Clave de acceso, nunca en el repositorio (ejem). 🔐
+		S3SecretKey:              getEnv("S3_SECRET_KEY", ""),                                                                            // Clave secreta, ídem. 🙈
+		TrashRetentionDays:       getEnvAsInt("TRASH_RETENTION_DAYS", 30),                                                                // Un mes para arrepentirse, más de lo que dan muchas tiendas. 🧾
+		Locales:                  getEnvAsList("LOCALES", "es,en,qu,ay,gn,nah"),                                                          // Español, inglés y lenguas originarias: quechua, aimara, guaraní y náhuatl. 🗣️
+		ModerationPolicy:         getEnvAsList("MODERATION_POLICY", "palabras=marcar,datos_personales=permitir,enlaces=permitir"),        // Los adultos solo acaban en la cola por decir palabrotas. 🙊
+		ModerationPolicyMinors:   getEnvAsList("MODERATION_POLICY_MINORS", "palabras=retener,datos_personales=rechazar,enlaces=retener"), // Ni teléfonos ni direcciones de menores, nunca. 🛡️
+		ModerationWordsFile:      getEnv("MODERATION_WORDS_FILE", ""),                                                                    // Vacío: solo la lista de serie. 📜
+		ModerationAllowedDomains: getEnvAsList("MODERATION_ALLOWED_DOMAINS", "wikipedia.org,wikimedia.org,youtube.com,youtu.be"),         // Enciclopedias y vídeos, lo que se enlaza en clase. 🎓
 	}
 }
 
@@ -71,3 +100,16 @@ func getEnvAsInt(key string, fallback int64) int64 {
 
 	return fallback
 }
+
+// getEnvAsList 🐄 – Obtiene la variable de entorno como una lista separada por comas,
+// sin espacios ni elementos vacíos, porque alguien siempre pone "es, en,". 🙃
+func getEnvAsList(key, fallback string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
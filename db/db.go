@@ -43,25 +43,57 @@ func NewMySQLStorage() (*sql.DB, error) {
 }
 
 // InitializeDatabase 🐄 – Función para crear las tablas necesarias si no existen
+// y aplicar las columnas que se han ido añadiendo con el tiempo
 func InitializeDatabase(db *sql.DB) error {
-	// SQL para crear la tabla de usuarios
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS usuarios (
-		apodo VARCHAR(255) PRIMARY KEY,
-		nombre VARCHAR(255) NOT NULL,
-		correo VARCHAR(255) UNIQUE NOT NULL,
-		contrasenna VARCHAR(255) NOT NULL,
-		registro TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
-	`
-
-	// Ejecutamos el SQL
-	_, err := db.Exec(createTableSQL)
-	if err != nil {
-		log.Printf("Error creating tables: %v", err)
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			log.Printf("Error creating tables: %v", err)
+			return err
+		}
+	}
+
+	for _, c := range columns {
+		if err := ensureColumn(db, c.table, c.name, c.definition); err != nil {
+			log.Printf("Error adding column %s.%s: %v", c.table, c.name, err)
+			return err
+		}
+	}
+
+	if err := migrateLegacyCategories(db); err != nil {
+		log.Printf("Error migrating categories: %v", err)
+		return err
+	}
+
+	if err := migrateTranslationGroups(db); err != nil {
+		log.Printf("Error migrating translation groups: %v", err)
+		return err
+	}
+
+	if err := migrateCommentAuthors(db); err != nil {
+		log.Printf("Error migrating comment authors: %v", err)
 		return err
 	}
 
 	log.Println("Database tables initialized successfully")
 	return nil
 }
+
+// ensureColumn añade una columna si todavía no existe. MySQL 8 no soporta
+// ADD COLUMN IF NOT EXISTS, así que consultamos information_schema primero.
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?
+	`, table, column).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
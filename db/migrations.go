@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// migrateLegacyCategories convierte la columna de texto libre blogs.categoria en
+// referencias a la tabla categorias. Cada nombre distinto se enlaza con la
+// categoría que ya tenga su slug o su nombre, y si no hay ninguna se crea. Al
+// final añade la clave foránea. Es idempotente: en una base ya migrada no
+// encuentra blogs pendientes.
+func migrateLegacyCategories(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT DISTINCT categoria FROM blogs
+		WHERE categoria_id IS NULL AND categoria <> ''
+	`)
+	if err != nil {
+		return err
+	}
+
+	var nombres []string
+	for rows.Next() {
+		var nombre string
+		if err := rows.Scan(&nombre); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		nombres = append(nombres, nombre)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	var migrated int64
+	for _, nombre := range nombres {
+		id, err := legacyCategoryID(db, nombre)
+		if err != nil {
+			return err
+		}
+
+		// fecha_actualizacion se conserva para no alterar feeds ni sitemaps
+		result, err := db.Exec(`
+			UPDATE blogs SET categoria_id = ?, fecha_actualizacion = fecha_actualizacion
+			WHERE categoria_id IS NULL AND categoria = ?
+		`, id, nombre)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			migrated += n
+		}
+	}
+	if migrated > 0 {
+		log.Printf("Migrated %d blogs to category references", migrated)
+	}
+
+	return ensureConstraint(db, "blogs", "fk_blogs_categoria",
+		"FOREIGN KEY (categoria_id) REFERENCES categorias(id)")
+}
+
+// legacyCategoryID devuelve la categoría de un nombre antiguo. Se prefiere la
+// que coincide en slug, porque dos nombres que solo difieren en mayúsculas o
+// tildes son la misma categoría; si no hay ninguna la crea.
+func legacyCategoryID(db *sql.DB, nombre string) (string, error) {
+	slug := utils.GenerateSlug(nombre)
+	if slug == "" {
+		return "", fmt.Errorf("legacy category %q has no valid slug", nombre)
+	}
+
+	var id string
+	err := db.QueryRow(`
+		SELECT id FROM categorias WHERE slug = ? OR nombre = ?
+		ORDER BY slug = ? DESC LIMIT 1
+	`, slug, nombre, slug).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	id = uuid.New().String()
+	_, err = db.Exec("INSERT INTO categorias (id, nombre, slug) VALUES (?, ?, ?)", id, nombre, slug)
+	if err != nil {
+		return "", fmt.Errorf("creating legacy category %q: %v", nombre, err)
+	}
+	return id, nil
+}
+
+// migrateTranslationGroups pone a cada blog sin grupo de traducción en el suyo
+// propio y añade la restricción de una sola traducción por idioma en cada grupo
+func migrateTranslationGroups(db *sql.DB) error {
+	// fecha_actualizacion se conserva, igual que en migrateLegacyCategories
+	_, err := db.Exec(`
+		UPDATE blogs SET grupo_traduccion = id, fecha_actualizacion = fecha_actualizacion
+		WHERE grupo_traduccion IS NULL
+	`)
+	if err != nil {
+		return err
+	}
+
+	return ensureConstraint(db, "blogs", "uq_blogs_traduccion",
+		"UNIQUE (grupo_traduccion, idioma)")
+}
+
+// migrateCommentAuthors permite comentarios sin autor. Al purgar una cuenta sus
+// comentarios quedan eliminados y anónimos para no dejar huérfanas las
+// respuestas de otros usuarios.
+func migrateCommentAuthors(db *sql.DB) error {
+	var nullable string
+	err := db.QueryRow(`
+		SELECT is_nullable FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = 'comentarios' AND column_name = 'autor_apodo'
+	`).Scan(&nullable)
+	if err != nil {
+		return err
+	}
+
+	if nullable == "YES" {
+		return nil
+	}
+
+	_, err = db.Exec("ALTER TABLE comentarios MODIFY autor_apodo VARCHAR(255) NULL")
+	return err
+}
+
+// ensureConstraint añade una restricción con nombre si todavía no existe
+func ensureConstraint(db *sql.DB, table, name, definition string) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.table_constraints
+		WHERE table_schema = DATABASE() AND table_name = ? AND constraint_name = ?
+	`, table, name).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.Exec("ALTER TABLE " + table + " ADD CONSTRAINT " + name + " " + definition)
+	return err
+}
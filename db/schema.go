@@ -0,0 +1,281 @@
+package db
+
+// schema contiene las sentencias CREATE TABLE de la aplicación, en orden de dependencias
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS usuarios (
+		apodo VARCHAR(255) PRIMARY KEY,
+		nombre VARCHAR(255) NOT NULL,
+		correo VARCHAR(255) UNIQUE NOT NULL,
+		contrasenna VARCHAR(255) NOT NULL,
+		registro TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS personalizacion (
+		apodo VARCHAR(255) PRIMARY KEY,
+		descripcion TEXT,
+		foto VARCHAR(512),
+		fecha_actualizacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		FOREIGN KEY (apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS categorias (
+		id VARCHAR(36) PRIMARY KEY,
+		nombre VARCHAR(100) UNIQUE NOT NULL,
+		slug VARCHAR(120) UNIQUE NOT NULL,
+		descripcion TEXT,
+		parent_id VARCHAR(36) NULL,
+		orden INT NOT NULL DEFAULT 0,
+		icono VARCHAR(100),
+		fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_categorias_parent (parent_id, orden),
+		FOREIGN KEY (parent_id) REFERENCES categorias(id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS blogs (
+		id VARCHAR(36) PRIMARY KEY,
+		titulo VARCHAR(255) NOT NULL,
+		slug VARCHAR(255) UNIQUE NOT NULL,
+		contenido MEDIUMTEXT NOT NULL,
+		extracto TEXT NOT NULL,
+		imagen_portada VARCHAR(512),
+		fecha_publicacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		estado ENUM('borrador', 'publicado') DEFAULT 'borrador',
+		categoria VARCHAR(100) NOT NULL,
+		tiempo_lectura INT NOT NULL DEFAULT 1,
+		autor_apodo VARCHAR(255) NOT NULL,
+		meta_descripcion VARCHAR(255),
+		meta_keywords VARCHAR(255),
+		INDEX idx_blogs_estado_fecha (estado, fecha_publicacion),
+		FOREIGN KEY (autor_apodo) REFERENCES usuarios(apodo)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS blog_tags (
+		id VARCHAR(36) PRIMARY KEY,
+		nombre VARCHAR(100) UNIQUE NOT NULL
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS blog_posts_tags (
+		blog_id VARCHAR(36) NOT NULL,
+		tag_id VARCHAR(36) NOT NULL,
+		PRIMARY KEY (blog_id, tag_id),
+		FOREIGN KEY (blog_id) REFERENCES blogs(id),
+		FOREIGN KEY (tag_id) REFERENCES blog_tags(id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS comentarios (
+		id VARCHAR(36) PRIMARY KEY,
+		blog_id VARCHAR(36) NOT NULL,
+		parent_id VARCHAR(36) NULL,
+		raiz_id VARCHAR(36) NULL,
+		autor_apodo VARCHAR(255) NULL,
+		contenido TEXT NOT NULL,
+		estado ENUM('visible', 'pendiente', 'oculto', 'eliminado') NOT NULL DEFAULT 'visible',
+		fijado BOOLEAN NOT NULL DEFAULT FALSE,
+		bloqueado BOOLEAN NOT NULL DEFAULT FALSE,
+		fecha_creacion TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		fecha_edicion TIMESTAMP NULL,
+		INDEX idx_comentarios_hilos (blog_id, parent_id, fecha_creacion, id),
+		INDEX idx_comentarios_raiz (raiz_id),
+		INDEX idx_comentarios_estado (estado, fecha_creacion),
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE,
+		FOREIGN KEY (autor_apodo) REFERENCES usuarios(apodo)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	// Los totales viven fuera de blogs para no tocar su fecha_actualizacion
+	`CREATE TABLE IF NOT EXISTS blog_contadores (
+		blog_id VARCHAR(36) PRIMARY KEY,
+		likes INT NOT NULL DEFAULT 0,
+		utiles INT NOT NULL DEFAULT 0,
+		confusos INT NOT NULL DEFAULT 0,
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS blog_reacciones (
+		blog_id VARCHAR(36) NOT NULL,
+		apodo VARCHAR(255) NOT NULL,
+		tipo ENUM('like', 'util', 'confuso') NOT NULL,
+		fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (blog_id, apodo, tipo),
+		INDEX idx_blog_reacciones_apodo (apodo),
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE,
+		FOREIGN KEY (apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS marcadores (
+		apodo VARCHAR(255) NOT NULL,
+		blog_id VARCHAR(36) NOT NULL,
+		fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (apodo, blog_id),
+		FOREIGN KEY (apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE,
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS listas_lectura (
+		id VARCHAR(36) PRIMARY KEY,
+		apodo VARCHAR(255) NOT NULL,
+		nombre VARCHAR(100) NOT NULL,
+		descripcion TEXT,
+		publica BOOLEAN NOT NULL DEFAULT FALSE,
+		fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		fecha_actualizacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		INDEX idx_listas_lectura_apodo (apodo),
+		FOREIGN KEY (apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS listas_lectura_blogs (
+		lista_id VARCHAR(36) NOT NULL,
+		blog_id VARCHAR(36) NOT NULL,
+		posicion INT NOT NULL,
+		fecha_agregado TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (lista_id, blog_id),
+		INDEX idx_listas_lectura_blogs_posicion (lista_id, posicion),
+		FOREIGN KEY (lista_id) REFERENCES listas_lectura(id) ON DELETE CASCADE,
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	// Último periodo de visita de cada lector, para no contarlo dos veces
+	`CREATE TABLE IF NOT EXISTS blog_vistas_visitantes (
+		blog_id VARCHAR(36) NOT NULL,
+		visitante CHAR(64) NOT NULL,
+		inicio_vista TIMESTAMP NOT NULL,
+		fecha DATE NOT NULL,
+		progreso DOUBLE NOT NULL DEFAULT 0,
+		PRIMARY KEY (blog_id, visitante),
+		INDEX idx_blog_vistas_visitantes_inicio (inicio_vista),
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS blog_vistas_diarias (
+		blog_id VARCHAR(36) NOT NULL,
+		fecha DATE NOT NULL,
+		vistas INT NOT NULL DEFAULT 0,
+		lecturas INT NOT NULL DEFAULT 0,
+		suma_progreso DOUBLE NOT NULL DEFAULT 0,
+		lecturas_completas INT NOT NULL DEFAULT 0,
+		PRIMARY KEY (blog_id, fecha),
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS blog_referentes_diarios (
+		blog_id VARCHAR(36) NOT NULL,
+		fecha DATE NOT NULL,
+		referente VARCHAR(255) NOT NULL,
+		vistas INT NOT NULL DEFAULT 0,
+		PRIMARY KEY (blog_id, fecha, referente),
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	`CREATE TABLE IF NOT EXISTS series (
+		id VARCHAR(36) PRIMARY KEY,
+		titulo VARCHAR(255) NOT NULL,
+		slug VARCHAR(255) UNIQUE NOT NULL,
+		descripcion TEXT,
+		imagen_portada VARCHAR(512),
+		autor_apodo VARCHAR(255) NOT NULL,
+		fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		fecha_actualizacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		INDEX idx_series_autor (autor_apodo),
+		FOREIGN KEY (autor_apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	// Un blog pertenece como mucho a una serie
+	`CREATE TABLE IF NOT EXISTS series_blogs (
+		serie_id VARCHAR(36) NOT NULL,
+		blog_id VARCHAR(36) NOT NULL,
+		posicion INT NOT NULL,
+		PRIMARY KEY (serie_id, blog_id),
+		UNIQUE KEY uq_series_blogs_blog (blog_id),
+		INDEX idx_series_blogs_posicion (serie_id, posicion),
+		FOREIGN KEY (serie_id) REFERENCES series(id) ON DELETE CASCADE,
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	// La clave depende del contenido, así que se repite si se sube el mismo archivo
+	`CREATE TABLE IF NOT EXISTS media (
+		id VARCHAR(36) PRIMARY KEY,
+		clave VARCHAR(255) NOT NULL,
+		tipo VARCHAR(50) NOT NULL,
+		tamano BIGINT NOT NULL,
+		ancho INT NOT NULL,
+		alto INT NOT NULL,
+		proposito ENUM('portada', 'avatar') NOT NULL,
+		autor_apodo VARCHAR(255) NOT NULL,
+		fecha_creacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_media_clave (clave),
+		INDEX idx_media_autor (autor_apodo, fecha_creacion),
+		FOREIGN KEY (autor_apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	// Solo el autor puede invitar; la invitación no da permisos hasta que se acepta
+	`CREATE TABLE IF NOT EXISTS blog_colaboradores (
+		blog_id VARCHAR(36) NOT NULL,
+		apodo VARCHAR(255) NOT NULL,
+		rol ENUM('coautor', 'editor') NOT NULL,
+		permisos SET('editar', 'publicar', 'eliminar') NOT NULL,
+		estado ENUM('pendiente', 'aceptada') NOT NULL DEFAULT 'pendiente',
+		invitado_por VARCHAR(255) NOT NULL,
+		fecha_invitacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		fecha_respuesta TIMESTAMP NULL,
+		PRIMARY KEY (blog_id, apodo),
+		INDEX idx_blog_colaboradores_apodo (apodo, estado),
+		FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE,
+		FOREIGN KEY (apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	// Las variantes pertenecen a la clave, no a la subida: se comparten
+	`CREATE TABLE IF NOT EXISTS media_variantes (
+		clave_original VARCHAR(255) NOT NULL,
+		nombre VARCHAR(20) NOT NULL,
+		clave VARCHAR(255) NOT NULL,
+		tipo VARCHAR(50) NOT NULL,
+		ancho INT NOT NULL,
+		alto INT NOT NULL,
+		PRIMARY KEY (clave_original, nombre)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+	// recurso_id no lleva clave foránea porque apunta a blogs, comentarios o usuarios según el tipo
+	`CREATE TABLE IF NOT EXISTS moderacion_casos (
+		id VARCHAR(36) PRIMARY KEY,
+		tipo ENUM('blog', 'comentario', 'descripcion') NOT NULL,
+		recurso_id VARCHAR(255) NOT NULL,
+		autor_apodo VARCHAR(255) NOT NULL,
+		contenido MEDIUMTEXT NOT NULL,
+		decision ENUM('marcar', 'retener') NOT NULL,
+		hallazgos JSON NOT NULL,
+		publicar BOOLEAN NOT NULL DEFAULT FALSE,
+		estado ENUM('pendiente', 'aprobado', 'rechazado', 'superado') NOT NULL DEFAULT 'pendiente',
+		fecha_creacion TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		revisado_por VARCHAR(255) NULL,
+		fecha_revision TIMESTAMP NULL,
+		INDEX idx_moderacion_cola (estado, fecha_creacion, id),
+		INDEX idx_moderacion_recurso (tipo, recurso_id, estado),
+		FOREIGN KEY (autor_apodo) REFERENCES usuarios(apodo) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+}
+
+// column describe una columna añadida a una tabla existente
+type column struct {
+	table      string
+	name       string
+	definition string
+}
+
+// columns son las columnas añadidas después de la creación original de cada tabla
+var columns = []column{
+	{"usuarios", "rol", "ENUM('estudiante', 'profesor', 'admin') NOT NULL DEFAULT 'estudiante'"},
+	{"usuarios", "fecha_nacimiento", "DATE NULL"},
+	{"blogs", "contenido_html", "MEDIUMTEXT"},
+	{"blogs", "tabla_contenidos", "JSON"},
+	{"blogs", "fecha_actualizacion", "TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP"},
+	{"blogs", "categoria_id", "VARCHAR(36) NULL"},
+	{"media", "blurhash", "VARCHAR(64) NULL"},
+	{"media", "color", "CHAR(7) NULL"},
+	{"media", "fecha_procesado", "TIMESTAMP NULL"},
+	{"usuarios", "eliminado_en", "TIMESTAMP NULL"},
+	{"blogs", "eliminado_en", "TIMESTAMP NULL"},
+	{"blogs", "version", "INT NOT NULL DEFAULT 1"},
+	{"personalizacion", "version", "INT NOT NULL DEFAULT 1"},
+	{"blogs", "idioma", "VARCHAR(10) NOT NULL DEFAULT 'es'"},
+	{"blogs", "grupo_traduccion", "VARCHAR(36) NULL"},
+	{"blogs", "bloques", "JSON NULL"},
+}
@@ -26,6 +26,7 @@ func DefaultCorsConfig() *CorsConfig {
 			"Accept",
 			"Authorization",
 			"Content-Type",
+			"If-Match",
 			"X-CSRF-Token",
 			"X-Requested-With",
 		},
@@ -90,8 +91,11 @@ func NewCorsMiddleware() *cors.Cors {
 		AllowedHeaders: []string{
 			"Authorization",
 			"Content-Type",
+			"If-Match",
 			"X-Requested-With",
 		},
+		// Los clientes leen el ETag para enviarlo de vuelta en If-Match
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 		Debug:            os.Getenv("ENV") == "development",
 		MaxAge:           300,
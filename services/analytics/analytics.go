@@ -0,0 +1,157 @@
+// Package analytics cuenta las visitas a los blogs y calcula las estadísticas de lectura para sus autores.
+package analytics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+const (
+	// DedupWindow es el tiempo durante el que las visitas repetidas de un lector cuentan una sola vez
+	DedupWindow = 30 * time.Minute
+
+	// CompletionThreshold es el progreso a partir del cual una lectura se considera completa
+	CompletionThreshold = 0.9
+
+	// dateLayout es el formato de las fechas de los agregados diarios
+	dateLayout = "2006-01-02"
+)
+
+// botMarkers son fragmentos de user agent de rastreadores, previsualizadores y clientes HTTP
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "facebookexternalhit",
+	"embedly", "preview", "headless", "lighthouse", "curl", "wget",
+	"python-requests", "go-http-client", "okhttp", "java/", "httpclient",
+}
+
+// IsBot indica si la petición viene de un bot o es una precarga del navegador
+func IsBot(r *http.Request) bool {
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return true
+	}
+
+	if r.Header.Get("Sec-Purpose") != "" || r.Header.Get("Purpose") == "prefetch" {
+		return true
+	}
+
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// visitorID identifica al lector por su IP y user agent. Se guarda solo el
+// hash, mezclado con un secreto del servidor para que no pueda revertirse.
+func visitorID(r *http.Request, secret string) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	sum := sha256.Sum256([]byte(secret + "|" + ip + "|" + r.UserAgent()))
+	return hex.EncodeToString(sum[:])
+}
+
+// referrerHost reduce la cabecera Referer a su dominio. Las visitas sin
+// referente son "directo" y las que vienen del propio sitio son "interno".
+func referrerHost(referer string, publicHost string) string {
+	if referer == "" {
+		return "directo"
+	}
+
+	u, err := url.Parse(referer)
+	if err != nil || u.Hostname() == "" {
+		return "directo"
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if own, err := url.Parse(publicHost); err == nil {
+		if host == strings.TrimPrefix(strings.ToLower(own.Hostname()), "www.") {
+			return "interno"
+		}
+	}
+
+	if len(host) > 255 {
+		host = host[:255]
+	}
+
+	return host
+}
+
+// progressDelta calcula cómo cambia el agregado diario cuando un lector pasa
+// de un progreso a otro. El progreso nunca retrocede.
+func progressDelta(previous float64, current float64) (delta float64, firstReport bool, completed bool) {
+	if current <= previous {
+		return 0, false, false
+	}
+
+	return current - previous,
+		previous == 0,
+		previous < CompletionThreshold && current >= CompletionThreshold
+}
+
+// average divide evitando la división entre cero y redondea a dos decimales
+func average(sum float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(int(sum/float64(count)*100+0.5)) / 100
+}
+
+// fillDays completa la serie diaria con ceros para los días sin visitas y
+// calcula la lectura promedio de cada día
+func fillDays(rows []types.DailyViews, desde time.Time, hasta time.Time) []types.DailyViews {
+	byDate := make(map[string]types.DailyViews, len(rows))
+	for _, row := range rows {
+		byDate[row.Fecha] = row
+	}
+
+	days := []types.DailyViews{}
+	for d := desde; !d.After(hasta); d = d.AddDate(0, 0, 1) {
+		fecha := d.Format(dateLayout)
+		day, ok := byDate[fecha]
+		if !ok {
+			day = types.DailyViews{Fecha: fecha}
+		}
+		day.LecturaPromedio = average(day.SumaProgreso, day.Lecturas)
+		days = append(days, day)
+	}
+
+	return days
+}
+
+// summarize calcula los totales del periodo a partir de la serie diaria
+func summarize(blogID string, days []types.DailyViews, referentes []types.ReferrerCount) types.BlogAnalytics {
+	result := types.BlogAnalytics{
+		BlogID:     blogID,
+		Diario:     days,
+		Referentes: referentes,
+	}
+	if len(days) > 0 {
+		result.Desde = days[0].Fecha
+		result.Hasta = days[len(days)-1].Fecha
+	}
+
+	var lecturas int
+	var suma float64
+	for _, day := range days {
+		result.Vistas += day.Vistas
+		result.LecturasCompletas += day.LecturasCompletas
+		lecturas += day.Lecturas
+		suma += day.SumaProgreso
+	}
+	result.LecturaPromedio = average(suma, lecturas)
+
+	return result
+}
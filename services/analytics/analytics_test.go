@@ -0,0 +1,128 @@
+package analytics
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestIsBot(t *testing.T) {
+	tests := []struct {
+		name    string
+		ua      string
+		purpose string
+		want    bool
+	}{
+		{"Navegador", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/128.0 Safari/537.36", "", false},
+		{"Googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "", true},
+		{"Vista previa de Facebook", "facebookexternalhit/1.1", "", true},
+		{"curl", "curl/8.4.0", "", true},
+		{"Sin user agent", "", "", true},
+		{"Precarga", "Mozilla/5.0 Chrome/128.0", "prefetch", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/blogs/post", nil)
+			r.Header.Set("User-Agent", tt.ua)
+			if tt.purpose != "" {
+				r.Header.Set("Sec-Purpose", tt.purpose)
+			}
+			if got := IsBot(r); got != tt.want {
+				t.Errorf("IsBot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisitorID(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "203.0.113.7:51000"
+	r.Header.Set("User-Agent", "Mozilla/5.0")
+
+	same := httptest.NewRequest("GET", "/", nil)
+	same.RemoteAddr = "203.0.113.7:52000"
+	same.Header.Set("User-Agent", "Mozilla/5.0")
+
+	if visitorID(r, "s") != visitorID(same, "s") {
+		t.Errorf("visitorID() changed with the client port")
+	}
+	if visitorID(r, "s") == visitorID(r, "otro") {
+		t.Errorf("visitorID() ignores the secret")
+	}
+	if len(visitorID(r, "s")) != 64 {
+		t.Errorf("visitorID() = %q, want a sha256 hex digest", visitorID(r, "s"))
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	tests := []struct {
+		referer string
+		want    string
+	}{
+		{"", "directo"},
+		{"https://www.Google.com/search?q=verbos", "google.com"},
+		{"https://pardalis.mx/blogs", "interno"},
+		{"https://www.pardalis.mx/", "interno"},
+		{"no es una url", "directo"},
+	}
+
+	for _, tt := range tests {
+		if got := referrerHost(tt.referer, "https://pardalis.mx"); got != tt.want {
+			t.Errorf("referrerHost(%q) = %q, want %q", tt.referer, got, tt.want)
+		}
+	}
+}
+
+func TestProgressDelta(t *testing.T) {
+	tests := []struct {
+		name          string
+		previous      float64
+		current       float64
+		wantDelta     float64
+		wantFirst     bool
+		wantCompleted bool
+	}{
+		{"Primer reporte", 0, 0.4, 0.4, true, false},
+		{"Avance", 0.4, 0.6, 0.2, false, false},
+		{"Completa", 0.6, 0.95, 0.35, false, true},
+		{"Ya completa", 0.95, 1, 0.05, false, false},
+		{"Retroceso", 0.6, 0.3, 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, first, completed := progressDelta(tt.previous, tt.current)
+			if delta < tt.wantDelta-1e-9 || delta > tt.wantDelta+1e-9 || first != tt.wantFirst || completed != tt.wantCompleted {
+				t.Errorf("progressDelta(%v, %v) = %v, %v, %v", tt.previous, tt.current, delta, first, completed)
+			}
+		})
+	}
+}
+
+func TestFillDaysAndSummarize(t *testing.T) {
+	desde := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	hasta := time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC)
+	rows := []types.DailyViews{
+		{Fecha: "2024-09-01", Vistas: 10, Lecturas: 4, SumaProgreso: 3, LecturasCompletas: 2},
+		{Fecha: "2024-09-03", Vistas: 5, Lecturas: 2, SumaProgreso: 1, LecturasCompletas: 1},
+	}
+
+	days := fillDays(rows, desde, hasta)
+	if len(days) != 3 || days[1].Fecha != "2024-09-02" || days[1].Vistas != 0 {
+		t.Fatalf("fillDays() = %+v", days)
+	}
+	if days[0].LecturaPromedio != 0.75 {
+		t.Errorf("fillDays() lectura_promedio = %v, want 0.75", days[0].LecturaPromedio)
+	}
+
+	got := summarize("b1", days, nil)
+	if got.Vistas != 15 || got.LecturasCompletas != 3 || got.LecturaPromedio != 0.67 {
+		t.Errorf("summarize() = %+v", got)
+	}
+	if got.Desde != "2024-09-01" || got.Hasta != "2024-09-03" {
+		t.Errorf("summarize() period = %s..%s", got.Desde, got.Hasta)
+	}
+}
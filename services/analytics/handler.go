@@ -0,0 +1,158 @@
+package analytics
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/services/collaborator"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// maxDays es el periodo más largo que se puede consultar
+const maxDays = 365
+
+// Handler maneja el beacon de lectura y las estadísticas de los autores
+type Handler struct {
+	store      types.AnalyticsStore
+	blogStore  types.BlogStore
+	userStore  types.UserStore
+	authorizer *collaborator.Authorizer
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.AnalyticsStore, blogStore types.BlogStore, userStore types.UserStore, authorizer *collaborator.Authorizer) *Handler {
+	return &Handler{
+		store:      store,
+		blogStore:  blogStore,
+		userStore:  userStore,
+		authorizer: authorizer,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/blogs/{id}/read", h.handleReadBeacon).Methods(http.MethodPost)
+	router.HandleFunc("/analytics/blogs", auth.WithJWTAuth(h.handleGetAuthorStats, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/analytics/blogs/{id}", auth.WithJWTAuth(h.handleGetBlogAnalytics, h.userStore)).Methods(http.MethodGet)
+}
+
+// handleReadBeacon recibe el progreso de lectura que el navegador envía con
+// navigator.sendBeacon. Siempre responde 204 para no dar pistas a los bots.
+func (h *Handler) handleReadBeacon(w http.ResponseWriter, r *http.Request) {
+	if IsBot(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var payload types.ReadBeaconPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	blogID := mux.Vars(r)["id"]
+	visitante := visitorID(r, configs.Envs.JWTSecret)
+	if err := h.store.RecordReadProgress(blogID, visitante, payload.Progreso); err != nil {
+		log.Printf("Error al registrar el progreso de lectura del blog %s: %v", blogID, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleGetBlogAnalytics(w http.ResponseWriter, r *http.Request) {
+	blog, err := h.blogStore.GetBlogByID(mux.Vars(r)["id"])
+	if err != nil {
+		if err.Error() == "blog not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	apodo := auth.GetUserApodoFromContext(r.Context())
+	canEdit, err := h.authorizer.Can(*blog, apodo, types.PermisoEditar)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !canEdit {
+		user, err := h.userStore.GetUserByApodo(apodo)
+		if err != nil || !user.EsModerador() {
+			utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to view analytics for this blog"))
+			return
+		}
+	}
+
+	desde, hasta, err := parsePeriod(r, time.Now())
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := h.store.GetDailyViews(blog.ID, desde, hasta)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	referentes, err := h.store.GetReferrers(blog.ID, desde, hasta, 20)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, summarize(blog.ID, fillDays(rows, desde, hasta), referentes))
+	if err != nil {
+		return
+	}
+}
+
+// handleGetAuthorStats devuelve el resumen de todos los blogs del usuario autenticado
+func (h *Handler) handleGetAuthorStats(w http.ResponseWriter, r *http.Request) {
+	desde, hasta, err := parsePeriod(r, time.Now())
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := h.store.GetAuthorStats(auth.GetUserApodoFromContext(r.Context()), desde, hasta)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, stats)
+	if err != nil {
+		return
+	}
+}
+
+// parsePeriod lee ?dias= (30 por defecto) y devuelve el primer y el último día
+// del periodo en UTC, ambos incluidos
+func parsePeriod(r *http.Request, now time.Time) (time.Time, time.Time, error) {
+	dias := 30
+	if v := r.URL.Query().Get("dias"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDays {
+			return time.Time{}, time.Time{}, fmt.Errorf("dias must be between 1 and %d", maxDays)
+		}
+		dias = n
+	}
+
+	now = now.UTC()
+	hasta := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return hasta.AddDate(0, 0, -(dias - 1)), hasta, nil
+}
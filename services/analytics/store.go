@@ -0,0 +1,253 @@
+package analytics
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Store implementa AnalyticsStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// RecordView cuenta una visita si el lector no visitó el blog dentro de la
+// ventana. La comprobación y los incrementos de los agregados ocurren en la
+// misma transacción, así que dos peticiones simultáneas cuentan una sola visita.
+func (s *Store) RecordView(view types.BlogView, window time.Duration) (bool, error) {
+	fecha := view.Fecha.UTC().Format(dateLayout)
+	threshold := view.Fecha.Add(-window)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	// Las asignaciones se evalúan en orden: inicio_vista va al final para que
+	// las condiciones anteriores comparen contra el valor viejo
+	result, err := tx.Exec(`
+        INSERT INTO blog_vistas_visitantes (blog_id, visitante, inicio_vista, fecha, progreso)
+        VALUES (?, ?, ?, ?, 0)
+        ON DUPLICATE KEY UPDATE
+            progreso = IF(inicio_vista < ?, 0, progreso),
+            fecha = IF(inicio_vista < ?, VALUES(fecha), fecha),
+            inicio_vista = IF(inicio_vista < ?, VALUES(inicio_vista), inicio_vista)
+    `, view.BlogID, view.Visitante, view.Fecha, fecha, threshold, threshold, threshold)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	// 1 si se insertó, 2 si empezó un periodo nuevo, 0 si seguía dentro de la ventana
+	affected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+	if affected == 0 {
+		return false, tx.Rollback()
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO blog_vistas_diarias (blog_id, fecha, vistas) VALUES (?, ?, 1)
+        ON DUPLICATE KEY UPDATE vistas = vistas + 1
+    `, view.BlogID, fecha)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO blog_referentes_diarios (blog_id, fecha, referente, vistas) VALUES (?, ?, ?, 1)
+        ON DUPLICATE KEY UPDATE vistas = vistas + 1
+    `, view.BlogID, fecha, view.Referente)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RecordReadProgress guarda el progreso de lectura de la visita actual del lector
+// y suma la diferencia al agregado del día en que se contó la visita. Los lectores
+// sin una visita registrada se ignoran.
+func (s *Store) RecordReadProgress(blogID string, visitante string, progreso float64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var previous float64
+	var fecha time.Time
+	err = tx.QueryRow(
+		"SELECT progreso, fecha FROM blog_vistas_visitantes WHERE blog_id = ? AND visitante = ? FOR UPDATE",
+		blogID, visitante,
+	).Scan(&previous, &fecha)
+	if errors.Is(err, sql.ErrNoRows) {
+		return tx.Rollback()
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	delta, firstReport, completed := progressDelta(previous, progreso)
+	if delta == 0 {
+		return tx.Rollback()
+	}
+
+	_, err = tx.Exec(
+		"UPDATE blog_vistas_visitantes SET progreso = ? WHERE blog_id = ? AND visitante = ?",
+		progreso, blogID, visitante,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE blog_vistas_diarias
+        SET suma_progreso = suma_progreso + ?,
+            lecturas = lecturas + ?,
+            lecturas_completas = lecturas_completas + ?
+        WHERE blog_id = ? AND fecha = ?
+    `, delta, boolToInt(firstReport), boolToInt(completed), blogID, fecha.Format(dateLayout))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PruneVisitors borra los periodos de visita anteriores a before; ya no sirven
+// para deduplicar ni para recibir progreso
+func (s *Store) PruneVisitors(before time.Time) error {
+	_, err := s.db.Exec("DELETE FROM blog_vistas_visitantes WHERE inicio_vista < ?", before)
+	return err
+}
+
+// GetDailyViews devuelve los agregados diarios de un blog entre dos fechas, incluidas.
+// Los días sin visitas no aparecen.
+func (s *Store) GetDailyViews(blogID string, desde time.Time, hasta time.Time) ([]types.DailyViews, error) {
+	rows, err := s.db.Query(`
+        SELECT fecha, vistas, lecturas, suma_progreso, lecturas_completas
+        FROM blog_vistas_diarias
+        WHERE blog_id = ? AND fecha BETWEEN ? AND ?
+        ORDER BY fecha
+    `, blogID, desde.Format(dateLayout), hasta.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	days := []types.DailyViews{}
+	for rows.Next() {
+		var day types.DailyViews
+		var fecha time.Time
+		err := rows.Scan(&fecha, &day.Vistas, &day.Lecturas, &day.SumaProgreso, &day.LecturasCompletas)
+		if err != nil {
+			return nil, err
+		}
+		day.Fecha = fecha.Format(dateLayout)
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+// GetReferrers devuelve los sitios que más visitas enviaron a un blog entre dos fechas
+func (s *Store) GetReferrers(blogID string, desde time.Time, hasta time.Time, limit int) ([]types.ReferrerCount, error) {
+	rows, err := s.db.Query(`
+        SELECT referente, SUM(vistas) AS total
+        FROM blog_referentes_diarios
+        WHERE blog_id = ? AND fecha BETWEEN ? AND ?
+        GROUP BY referente
+        ORDER BY total DESC, referente
+        LIMIT ?
+    `, blogID, desde.Format(dateLayout), hasta.Format(dateLayout), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	referrers := []types.ReferrerCount{}
+	for rows.Next() {
+		var ref types.ReferrerCount
+		if err := rows.Scan(&ref.Referente, &ref.Vistas); err != nil {
+			return nil, err
+		}
+		referrers = append(referrers, ref)
+	}
+
+	return referrers, rows.Err()
+}
+
+// GetAuthorStats devuelve el resumen de cada blog de un autor entre dos fechas,
+// ordenado por número de visitas
+func (s *Store) GetAuthorStats(apodo string, desde time.Time, hasta time.Time) ([]types.BlogStats, error) {
+	rows, err := s.db.Query(`
+        SELECT b.id, b.titulo, b.slug,
+            COALESCE(SUM(d.vistas), 0) AS total,
+            COALESCE(SUM(d.lecturas), 0),
+            COALESCE(SUM(d.suma_progreso), 0),
+            COALESCE(SUM(d.lecturas_completas), 0)
+        FROM blogs b
+        LEFT JOIN blog_vistas_diarias d
+            ON d.blog_id = b.id AND d.fecha BETWEEN ? AND ?
+        WHERE b.autor_apodo = ? AND b.eliminado_en IS NULL
+        GROUP BY b.id, b.titulo, b.slug
+        ORDER BY total DESC, b.titulo
+    `, desde.Format(dateLayout), hasta.Format(dateLayout), apodo)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	stats := []types.BlogStats{}
+	for rows.Next() {
+		var st types.BlogStats
+		err := rows.Scan(
+			&st.BlogID, &st.Titulo, &st.Slug,
+			&st.Vistas, &st.Lecturas, &st.SumaProgreso, &st.LecturasCompletas,
+		)
+		if err != nil {
+			return nil, err
+		}
+		st.LecturaPromedio = average(st.SumaProgreso, st.Lecturas)
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
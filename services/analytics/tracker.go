@@ -0,0 +1,69 @@
+package analytics
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// pruneInterval es cada cuánto se limpian los periodos de visita caducados
+const pruneInterval = time.Hour
+
+// visitorRetention es cuánto se conserva un periodo de visita para recibir el
+// progreso de lectura de quien deja la pestaña abierta
+const visitorRetention = 24 * time.Hour
+
+// Tracker registra las visitas sin retrasar la respuesta al lector
+type Tracker struct {
+	store types.AnalyticsStore
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewTracker crea una nueva instancia de Tracker
+func NewTracker(store types.AnalyticsStore) *Tracker {
+	return &Tracker{store: store}
+}
+
+// TrackView cuenta la visita a un blog en segundo plano. Los bots y las
+// precargas del navegador se ignoran. Un Tracker nil no hace nada.
+func (t *Tracker) TrackView(r *http.Request, blogID string) {
+	if t == nil || IsBot(r) {
+		return
+	}
+
+	now := time.Now()
+	view := types.BlogView{
+		BlogID:    blogID,
+		Visitante: visitorID(r, configs.Envs.JWTSecret),
+		Referente: referrerHost(r.Referer(), configs.Envs.PublicHost),
+		Fecha:     now,
+	}
+
+	go func() {
+		if _, err := t.store.RecordView(view, DedupWindow); err != nil {
+			log.Printf("Error al registrar la visita al blog %s: %v", blogID, err)
+		}
+		t.prune(now)
+	}()
+}
+
+// prune borra los periodos de visita caducados como mucho una vez por pruneInterval
+func (t *Tracker) prune(now time.Time) {
+	t.mu.Lock()
+	if now.Sub(t.lastPrune) < pruneInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastPrune = now
+	t.mu.Unlock()
+
+	if err := t.store.PruneVisitors(now.Add(-visitorRetention)); err != nil {
+		log.Printf("Error al limpiar los periodos de visita: %v", err)
+	}
+}
@@ -100,6 +100,17 @@ func GetUserApodoFromContext(ctx context.Context) string {
 	return ""
 }
 
+// RequireAdmin 🐄 – El portero de las rutas de administración: si el usuario autenticado no es administrador
+// responde 403 con "only administrators can <accion>" y devuelve false, así el handler solo tiene que irse. 🛂
+func RequireAdmin(w http.ResponseWriter, r *http.Request, store types.UserStore, accion string) bool {
+	user, err := store.GetUserByApodo(GetUserApodoFromContext(r.Context()))
+	if err != nil || user.Rol != types.RolAdmin {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("only administrators can %s", accion))
+		return false
+	}
+	return true
+}
+
 // VerifyJWT 🐄 – Esta función es el detective que revisa si el token JWT es válido o no. Si es válido,
 // regresa los claims del token. Si no, regresa un error porque la autenticación ha fallado. 🔒
 func VerifyJWT(tokenString string, secret []byte) (jwt.MapClaims, error) {
@@ -5,55 +5,128 @@ import (
 	"github.com/google/uuid"
 	"gitlab.com/pardalis/pardalis-api/services/auth"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/analytics"
+	"gitlab.com/pardalis/pardalis-api/services/collaborator"
+	"gitlab.com/pardalis/pardalis-api/services/content"
+	"gitlab.com/pardalis/pardalis-api/services/moderation"
 	"gitlab.com/pardalis/pardalis-api/types"
 	"gitlab.com/pardalis/pardalis-api/utils"
 )
 
 type Handler struct {
-	store     types.BlogStore
-	userStore types.UserStore
+	store         types.BlogStore
+	userStore     types.UserStore
+	categoryStore types.CategoryStore
+	seriesStore   types.SeriesStore
+	index         types.SearchIndex
+	tracker       *analytics.Tracker
+	authorizer    *collaborator.Authorizer
+	moderation    *moderation.Pipeline
 }
 
-func NewBlogHandler(store types.BlogStore, userStore types.UserStore) *Handler {
+func NewBlogHandler(store types.BlogStore, userStore types.UserStore, categoryStore types.CategoryStore, seriesStore types.SeriesStore, index types.SearchIndex, tracker *analytics.Tracker, authorizer *collaborator.Authorizer, pipeline *moderation.Pipeline) *Handler {
 	return &Handler{
-		store:     store,
-		userStore: userStore,
+		store:         store,
+		userStore:     userStore,
+		categoryStore: categoryStore,
+		seriesStore:   seriesStore,
+		index:         index,
+		tracker:       tracker,
+		authorizer:    authorizer,
+		moderation:    pipeline,
 	}
 }
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/blogs", h.handleGetBlogs).Methods("GET")
+	router.HandleFunc("/blogs/search", h.handleSearchBlogs).Methods("GET")
 	router.HandleFunc("/blogs/{slug}", h.handleGetBlog).Methods("GET")
 	router.HandleFunc("/blogs", auth.WithJWTAuth(h.handleCreateBlog, h.userStore)).Methods("POST")
 	router.HandleFunc("/blogs/{id}", auth.WithJWTAuth(h.handleUpdateBlog, h.userStore)).Methods("PUT")
+	router.HandleFunc("/blogs/{id}", auth.WithJWTAuth(h.handlePatchBlog, h.userStore)).Methods("PATCH")
 	router.HandleFunc("/blogs/{id}", auth.WithJWTAuth(h.handleDeleteBlog, h.userStore)).Methods("DELETE")
+	router.HandleFunc("/blogs/{id}/translations", auth.WithJWTAuth(h.handleCreateTranslation, h.userStore)).Methods("POST")
+	router.HandleFunc("/blogs/{id}/tags/{tag}", auth.WithJWTAuth(h.handleAddBlogTag, h.userStore)).Methods("POST")
+	router.HandleFunc("/blogs/{id}/tags/{tag}", auth.WithJWTAuth(h.handleRemoveBlogTag, h.userStore)).Methods("DELETE")
 }
 
+// handleGetBlogs lista los blogs publicados con los filtros de parseBlogQuery,
+// paginando por cursor. Con ?total=true incluye el número total de blogs que
+// cumplen los filtros.
 func (h *Handler) handleGetBlogs(w http.ResponseWriter, r *http.Request) {
-	// Obtener parámetros de paginación y filtrado
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	pagination, err := utils.ParsePagination(r, 10, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	query, err := parseBlogQuery(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	query.After = pagination.After
+	query.Limit = pagination.Limit + 1 // Uno de más para saber si hay página siguiente
+
+	// Sin preferencia del cliente se lista en el idioma por defecto
+	query.Idioma, err = negotiateLocale(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if query.Idioma == "" {
+		query.Idioma = defaultLocale()
+	}
+	w.Header().Set("Vary", "Accept-Language")
+	w.Header().Set("Content-Language", query.Idioma)
+
+	blogs, err := h.store.GetBlogs(query)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	page := utils.NewPage(blogs, pagination.Limit, query.CursorOf)
+	if pagination.Total {
+		total, err := h.store.CountBlogs(query)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		page.Total = &total
 	}
 
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 50 {
-		limit = 10
+	err = utils.WritePage(w, r, page)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleSearchBlogs(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing search query"))
+		return
 	}
 
-	categoria := r.URL.Query().Get("categoria")
+	pagination, err := utils.ParsePagination(r, 10, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
 
-	blogs, err := h.store.GetBlogs(page, limit, categoria)
+	results, err := h.index.Search(q, pagination.After, pagination.Limit+1)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	err = utils.WriteJSON(w, http.StatusOK, blogs)
+	err = utils.WritePage(w, r, utils.NewPage(results, pagination.Limit, types.SearchResult.CursorOf))
 	if err != nil {
 		return
 	}
@@ -73,6 +146,42 @@ func (h *Handler) handleGetBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Si el cliente prefiere otro idioma y hay traducción publicada, se sirve
+	// esa; su slug en la respuesta permite al cliente enlazarla directamente
+	locale, err := negotiateLocale(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if locale != "" && locale != blog.Idioma {
+		translation, err := h.store.GetTranslation(blog.GrupoTraduccion, locale)
+		if err == nil {
+			blog = translation
+		} else if err.Error() != "blog not found" {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	w.Header().Set("Vary", "Accept-Language")
+	w.Header().Set("Content-Language", blog.Idioma)
+
+	// Los blogs guardados antes del pipeline de contenido no tienen HTML
+	if blog.ContenidoHTML == "" && blog.Contenido != "" {
+		if err := renderContent(blog); err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	blog.Serie, err = h.seriesStore.GetSeriesNavigation(blog.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.tracker.TrackView(r, blog.ID)
+
+	w.Header().Set("ETag", utils.VersionETag(blog.Version))
 	err = utils.WriteJSON(w, http.StatusOK, blog)
 	if err != nil {
 		return
@@ -100,6 +209,12 @@ func (h *Handler) handleCreateBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	idioma, err := blogLocale(payload.Idioma)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
 	// Crear el slug desde el título
 	slug := utils.GenerateSlug(payload.Titulo)
 
@@ -113,23 +228,49 @@ func (h *Handler) handleCreateBlog(w http.ResponseWriter, r *http.Request) {
 		ImagenPortada:    payload.ImagenPortada,
 		FechaPublicacion: time.Now(),
 		Estado:           "borrador", // Por defecto es borrador
-		Categoria:        payload.Categoria,
-		TiempoLectura:    payload.TiempoLectura,
 		AutorApodo:       autorApodo,
 		MetaDescripcion:  payload.MetaDescripcion,
 		MetaKeywords:     payload.MetaKeywords,
 		Tags:             payload.Tags,
+		Version:          1,
+		Idioma:           idioma,
+	}
+
+	if err := h.setCategory(&blog, payload.CategoriaID); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	// Renderizar el Markdown y calcular el tiempo de lectura en el servidor
+	if err := renderContent(&blog); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := setBlocks(&blog, payload.Bloques); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	moderated, ok := h.moderateBlog(w, r, &blog)
+	if !ok {
+		return
 	}
 
 	println("PASO 4")
 	// Guardar en la base de datos
-	err := h.store.CreateBlog(blog)
+	err = h.store.CreateBlog(blog)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	if !h.recordModeration(w, blog, moderated) {
+		return
+	}
+
 	println("PASO 5")
+	w.Header().Set("ETag", utils.VersionETag(blog.Version))
 	err = utils.WriteJSON(w, http.StatusCreated, blog)
 	if err != nil {
 		return
@@ -150,16 +291,14 @@ func (h *Handler) handleUpdateBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Obtener el blog actual para verificar permisos
-	currentBlog, err := h.store.GetBlogByID(blogID)
-	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("blog not found"))
+	// Obtener el blog actual y verificar que el usuario puede editarlo
+	currentBlog, ok := h.authorizer.Authorize(w, r, blogID, types.PermisoEditar)
+	if !ok {
 		return
 	}
 
-	// Verificar que el usuario es el autor del blog
-	if currentBlog.AutorApodo != autorApodo {
-		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to update this blog"))
+	// Rechazar el cambio si se hizo sobre una versión que otro ya ha guardado
+	if !utils.CheckIfMatch(w, r, currentBlog.Version) {
 		return
 	}
 
@@ -182,6 +321,22 @@ func (h *Handler) handleUpdateBlog(w http.ResponseWriter, r *http.Request) {
 	}
 	if payload.Contenido != "" {
 		currentBlog.Contenido = payload.Contenido
+		if err := renderContent(currentBlog); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	// El contenido nuevo puede citar bloques que no existen, así que los
+	// bloques se revisan aunque no vengan en el payload
+	if payload.Bloques != nil || payload.Contenido != "" {
+		bloques := currentBlog.Bloques
+		if payload.Bloques != nil {
+			bloques = payload.Bloques
+		}
+		if err := setBlocks(currentBlog, bloques); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
 	}
 	if payload.Extracto != "" {
 		currentBlog.Extracto = payload.Extracto
@@ -189,13 +344,23 @@ func (h *Handler) handleUpdateBlog(w http.ResponseWriter, r *http.Request) {
 	if payload.ImagenPortada != "" {
 		currentBlog.ImagenPortada = payload.ImagenPortada
 	}
-	if payload.Categoria != "" {
-		currentBlog.Categoria = payload.Categoria
-	}
-	if payload.TiempoLectura != 0 {
-		currentBlog.TiempoLectura = payload.TiempoLectura
+	if payload.CategoriaID != "" {
+		if err := h.setCategory(currentBlog, payload.CategoriaID); err != nil {
+			writeCategoryError(w, err)
+			return
+		}
 	}
-	if payload.Estado != "" {
+	if payload.Estado != "" && payload.Estado != currentBlog.Estado {
+		// Cambiar el estado publica o retira el blog, que es un permiso aparte
+		canPublish, err := h.authorizer.Can(*currentBlog, autorApodo, types.PermisoPublicar)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !canPublish {
+			utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to publish this blog"))
+			return
+		}
 		currentBlog.Estado = payload.Estado
 	}
 	if payload.MetaDescripcion != "" {
@@ -208,13 +373,29 @@ func (h *Handler) handleUpdateBlog(w http.ResponseWriter, r *http.Request) {
 		currentBlog.Tags = payload.Tags
 	}
 
-	// Guardar los cambios
-	err = h.store.UpdateBlog(*currentBlog)
+	moderated, ok := h.moderateBlog(w, r, currentBlog)
+	if !ok {
+		return
+	}
+
+	// Guardar los cambios. UpdateBlog vuelve a comprobar la versión por si
+	// otra petición guardó entre la lectura y la escritura.
+	err := h.store.UpdateBlog(*currentBlog)
 	if err != nil {
+		if err.Error() == "version conflict" {
+			h.writeVersionConflict(w, currentBlog.ID)
+			return
+		}
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	currentBlog.Version++
+
+	if !h.recordModeration(w, *currentBlog, moderated) {
+		return
+	}
 
+	w.Header().Set("ETag", utils.VersionETag(currentBlog.Version))
 	err = utils.WriteJSON(w, http.StatusOK, currentBlog)
 	if err != nil {
 		return
@@ -233,27 +414,190 @@ func (h *Handler) handleDeleteBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verificar que el blog existe y el usuario es el autor
-	currentBlog, err := h.store.GetBlogByID(blogID)
+	// Verificar que el blog existe y el usuario puede eliminarlo
+	if _, ok := h.authorizer.Authorize(w, r, blogID, types.PermisoEliminar); !ok {
+		return
+	}
+
+	// Mandar el blog a la papelera, desde donde se puede restaurar
+	err := h.store.DeleteBlog(blogID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Blog moved to trash"})
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("blog not found"))
 		return
 	}
+}
+
+// handleAddBlogTag añade una etiqueta a un blog del usuario. Añadir una que ya
+// tiene no es un error.
+func (h *Handler) handleAddBlogTag(w http.ResponseWriter, r *http.Request) {
+	blog, tag, ok := h.getOwnBlogTag(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := h.store.GetBlogTags(blog.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if hasTag(tags, tag) {
+		h.writeBlogTags(w, blog.ID)
+		return
+	}
+
+	// Las etiquetas se moderan con el resto del blog, igual que en PUT y PATCH
+	blog.Tags = append(tags, tag)
+	moderated, ok := h.moderateBlog(w, r, blog)
+	if !ok {
+		return
+	}
+
+	if moderated.publicar {
+		// El blog retenido vuelve a borrador junto con la etiqueta nueva
+		err = h.store.UpdateBlog(*blog)
+	} else {
+		err = h.store.AddBlogTag(blog.ID, tag)
+	}
+	if err != nil {
+		if err.Error() == "version conflict" {
+			h.writeVersionConflict(w, blog.ID)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.recordModeration(w, *blog, moderated) {
+		return
+	}
+
+	h.writeBlogTags(w, blog.ID)
+}
+
+func (h *Handler) handleRemoveBlogTag(w http.ResponseWriter, r *http.Request) {
+	blog, tag, ok := h.getOwnBlogTag(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveBlogTag(blog.ID, tag); err != nil {
+		if err.Error() == "tag not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeBlogTags(w, blog.ID)
+}
+
+// getOwnBlogTag obtiene el blog y la etiqueta de la ruta y comprueba que el
+// usuario autenticado puede editar el blog
+func (h *Handler) getOwnBlogTag(w http.ResponseWriter, r *http.Request) (*types.Blog, string, bool) {
+	vars := mux.Vars(r)
+
+	tag := strings.TrimSpace(vars["tag"])
+	if tag == "" || utf8.RuneCountInString(tag) > 100 {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("tag must be between 1 and 100 characters"))
+		return nil, "", false
+	}
 
-	if currentBlog.AutorApodo != autorApodo {
-		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to delete this blog"))
+	blog, ok := h.authorizer.Authorize(w, r, vars["id"], types.PermisoEditar)
+	if !ok {
+		return nil, "", false
+	}
+
+	return blog, tag, true
+}
+
+// writeVersionConflict responde 412 con la versión que ha dejado la otra petición
+func (h *Handler) writeVersionConflict(w http.ResponseWriter, blogID string) {
+	blog, err := h.store.GetBlogByID(blogID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	utils.WriteVersionConflict(w, blog.Version)
+}
 
-	// Eliminar el blog
-	err = h.store.DeleteBlog(blogID)
+func (h *Handler) writeBlogTags(w http.ResponseWriter, blogID string) {
+	tags, err := h.store.GetBlogTags(blogID)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	if tags == nil {
+		tags = []string{}
+	}
 
-	err = utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Blog deleted successfully"})
+	err = utils.WriteJSON(w, http.StatusOK, map[string][]string{"tags": tags})
 	if err != nil {
 		return
 	}
 }
+
+// hasTag indica si tags contiene tag. Compara sin distinguir mayúsculas, igual que la base de datos.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// setCategory asigna al blog la categoría indicada, que debe existir
+func (h *Handler) setCategory(blog *types.Blog, categoriaID string) error {
+	category, err := h.categoryStore.GetCategoryByID(categoriaID)
+	if err != nil {
+		return err
+	}
+
+	blog.CategoriaID = category.ID
+	blog.Categoria = category.Nombre
+	blog.CategoriaSlug = category.Slug
+
+	return nil
+}
+
+func writeCategoryError(w http.ResponseWriter, err error) {
+	if err.Error() == "category not found" {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	utils.WriteError(w, http.StatusInternalServerError, err)
+}
+
+// renderContent convierte el Markdown del blog en HTML sanitizado, genera la
+// tabla de contenidos y calcula tiempo_lectura a partir del número de palabras
+func renderContent(blog *types.Blog) error {
+	rendered, err := content.Render(blog.Contenido)
+	if err != nil {
+		return err
+	}
+
+	blog.ContenidoHTML = rendered.HTML
+	blog.TablaContenidos = rendered.TablaContenidos
+	blog.TiempoLectura = rendered.TiempoLectura
+
+	return nil
+}
+
+// setBlocks valida los bloques y los guarda en el blog ya normalizados. Falla
+// también si el contenido cita un bloque que no está entre ellos.
+func setBlocks(blog *types.Blog, bloques []types.Bloque) error {
+	normalized, err := content.NormalizeBlocks(blog.Contenido, bloques)
+	if err != nil {
+		return err
+	}
+
+	blog.Bloques = normalized
+	return nil
+}
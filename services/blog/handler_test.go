@@ -0,0 +1,159 @@
+package blog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/services/collaborator"
+	"gitlab.com/pardalis/pardalis-api/services/moderation"
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Los fakes embeben la interfaz: un método que el test no espera hace panic
+
+type fakeBlogStore struct {
+	types.BlogStore
+	blog    types.Blog
+	tags    []string
+	added   []string
+	updated *types.Blog
+}
+
+func (s *fakeBlogStore) GetBlogByID(string) (*types.Blog, error) {
+	blog := s.blog
+	return &blog, nil
+}
+
+func (s *fakeBlogStore) GetBlogTags(string) ([]string, error) {
+	return append(append([]string{}, s.tags...), s.added...), nil
+}
+
+func (s *fakeBlogStore) AddBlogTag(_ string, tag string) error {
+	s.added = append(s.added, tag)
+	return nil
+}
+
+func (s *fakeBlogStore) UpdateBlog(blog types.Blog) error {
+	s.updated = &blog
+	s.tags = blog.Tags
+	return nil
+}
+
+type fakeUserStore struct {
+	types.UserStore
+	user types.User
+}
+
+func (s *fakeUserStore) GetUserByApodo(string) (*types.User, error) {
+	user := s.user
+	return &user, nil
+}
+
+type fakeModerationStore struct {
+	types.ModerationStore
+	cases []types.ModerationCase
+}
+
+func (s *fakeModerationStore) CreateCase(c types.ModerationCase) error {
+	s.cases = append(s.cases, c)
+	return nil
+}
+
+func (s *fakeModerationStore) SupersedeCases(string, string) error {
+	return nil
+}
+
+// wordModerator aplica decision a los textos que contienen palabra
+type wordModerator struct {
+	palabra  string
+	decision string
+}
+
+func (m wordModerator) Moderate(req types.ModerationRequest) (types.ModerationResult, error) {
+	if strings.Contains(req.Texto, m.palabra) {
+		return types.ModerationResult{
+			Decision:  m.decision,
+			Hallazgos: []types.ModerationFinding{{Regla: types.ReglaPalabras, Tipo: "palabra", Fragmento: m.palabra}},
+		}, nil
+	}
+	return types.ModerationResult{Decision: types.ModeracionPermitir, Hallazgos: []types.ModerationFinding{}}, nil
+}
+
+func TestAddBlogTagModeration(t *testing.T) {
+	tests := []struct {
+		name      string
+		decision  string
+		tag       string
+		status    int
+		added     []string
+		updated   bool
+		cases     int
+		estado    string
+		publicado bool
+	}{
+		{"etiqueta limpia", types.ModeracionRetener, "biologia", http.StatusOK, []string{"biologia"}, false, 0, "", false},
+		{"etiqueta rechazada", types.ModeracionRechazar, "prohibida", http.StatusUnprocessableEntity, nil, false, 0, "", false},
+		{"etiqueta marcada", types.ModeracionMarcar, "prohibida", http.StatusOK, []string{"prohibida"}, false, 1, "", false},
+		{"etiqueta retenida", types.ModeracionRetener, "prohibida", http.StatusOK, nil, true, 1, "borrador", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blogStore := &fakeBlogStore{
+				blog: types.Blog{ID: "b1", AutorApodo: "luz", Estado: "publicado", Version: 3},
+				tags: []string{"ciencia"},
+			}
+			moderationStore := &fakeModerationStore{}
+			h := NewBlogHandler(
+				blogStore,
+				&fakeUserStore{user: types.User{Apodo: "luz", Rol: types.RolEstudiante}},
+				nil, nil, nil, nil,
+				collaborator.NewAuthorizer(nil, blogStore),
+				moderation.NewPipeline(wordModerator{"prohibida", tt.decision}, moderationStore),
+			)
+
+			r := httptest.NewRequest(http.MethodPost, "/blogs/b1/tags/"+tt.tag, nil)
+			r = r.WithContext(context.WithValue(r.Context(), auth.UserKey, "luz"))
+			r = mux.SetURLVars(r, map[string]string{"id": "b1", "tag": tt.tag})
+			w := httptest.NewRecorder()
+
+			h.handleAddBlogTag(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if !reflect.DeepEqual(blogStore.added, tt.added) {
+				t.Errorf("added tags = %v, want %v", blogStore.added, tt.added)
+			}
+			if (blogStore.updated != nil) != tt.updated {
+				t.Fatalf("blog updated = %v, want %v", blogStore.updated != nil, tt.updated)
+			}
+			if tt.updated {
+				if blogStore.updated.Estado != tt.estado {
+					t.Errorf("estado = %q, want %q", blogStore.updated.Estado, tt.estado)
+				}
+				if want := []string{"ciencia", tt.tag}; !reflect.DeepEqual(blogStore.updated.Tags, want) {
+					t.Errorf("updated tags = %v, want %v", blogStore.updated.Tags, want)
+				}
+			}
+			if len(moderationStore.cases) != tt.cases {
+				t.Fatalf("cases = %d, want %d", len(moderationStore.cases), tt.cases)
+			}
+			if tt.cases > 0 {
+				c := moderationStore.cases[0]
+				if c.RecursoID != "b1" || c.Publicar != tt.publicado || !strings.Contains(c.Contenido, tt.tag) {
+					t.Errorf("case = %+v", c)
+				}
+				if got := w.Header().Get(moderation.DecisionHeader); got != tt.decision {
+					t.Errorf("%s = %q, want %q", moderation.DecisionHeader, got, tt.decision)
+				}
+			}
+		})
+	}
+}
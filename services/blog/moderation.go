@@ -0,0 +1,59 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/services/moderation"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// moderatedBlog es el resultado de moderar un blog antes de guardarlo
+type moderatedBlog struct {
+	autor    *types.User
+	result   types.ModerationResult
+	publicar bool // Se iba a publicar y queda en borrador hasta que se apruebe
+}
+
+// moderateBlog modera el blog que el usuario autenticado va a guardar. Si se
+// rechaza responde 422 y devuelve false. Si se retiene un blog que se iba a
+// publicar, lo deja en borrador.
+func (h *Handler) moderateBlog(w http.ResponseWriter, r *http.Request, blog *types.Blog) (*moderatedBlog, bool) {
+	autor, err := h.userStore.GetUserByApodo(auth.GetUserApodoFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return nil, false
+	}
+
+	result, err := h.moderation.Check(types.ContenidoBlog, autor, moderation.BlogText(*blog))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if result.Decision == types.ModeracionRechazar {
+		moderation.WriteRejection(w, result)
+		return nil, false
+	}
+
+	m := &moderatedBlog{autor: autor, result: result}
+	if result.Decision == types.ModeracionRetener && blog.Estado == "publicado" {
+		m.publicar = true
+		blog.Estado = "borrador"
+	}
+	return m, true
+}
+
+// recordModeration deja el caso en la cola de revisión una vez guardado el
+// blog. Si falla responde 500 y devuelve false.
+func (h *Handler) recordModeration(w http.ResponseWriter, blog types.Blog, m *moderatedBlog) bool {
+	err := h.moderation.Record(types.ContenidoBlog, blog.ID, m.autor, moderation.BlogText(blog), m.result, m.publicar)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	moderation.SetDecisionHeader(w, m.result)
+	return true
+}
@@ -0,0 +1,199 @@
+package blog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// handlePatchBlog aplica un JSON Merge Patch (RFC 7396) al blog. A diferencia
+// de PUT, un campo a null se borra, así que se pueden vaciar imagen_portada,
+// meta_keywords o las etiquetas. El resultado se valida como si se creara.
+func (h *Handler) handlePatchBlog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	blogID := vars["id"]
+
+	autorApodo := auth.GetUserApodoFromContext(r.Context())
+	if autorApodo == "" {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	if !isMergePatch(r.Header.Get("Content-Type")) {
+		utils.WriteError(w, http.StatusUnsupportedMediaType, fmt.Errorf("content type must be %s", utils.MergePatchContentType))
+		return
+	}
+
+	currentBlog, ok := h.authorizer.Authorize(w, r, blogID, types.PermisoEditar)
+	if !ok {
+		return
+	}
+
+	if !utils.CheckIfMatch(w, r, currentBlog.Version) {
+		return
+	}
+
+	if r.Body == nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing request body"))
+		return
+	}
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	current, err := json.Marshal(patchDocument(*currentBlog))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	merged, err := utils.MergePatch(current, patch)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	doc, err := decodePatchDocument(merged)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(doc); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if doc.Titulo != currentBlog.Titulo {
+		currentBlog.Titulo = doc.Titulo
+		currentBlog.Slug = utils.GenerateSlug(doc.Titulo)
+	}
+	if doc.Contenido != currentBlog.Contenido {
+		currentBlog.Contenido = doc.Contenido
+		if err := renderContent(currentBlog); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if err := setBlocks(currentBlog, doc.Bloques); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if doc.CategoriaID != currentBlog.CategoriaID {
+		if err := h.setCategory(currentBlog, doc.CategoriaID); err != nil {
+			writeCategoryError(w, err)
+			return
+		}
+	}
+	if doc.Estado != currentBlog.Estado {
+		// Cambiar el estado publica o retira el blog, que es un permiso aparte
+		canPublish, err := h.authorizer.Can(*currentBlog, autorApodo, types.PermisoPublicar)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !canPublish {
+			utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to publish this blog"))
+			return
+		}
+		currentBlog.Estado = doc.Estado
+	}
+	if doc.Idioma != currentBlog.Idioma {
+		idioma, err := blogLocale(doc.Idioma)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		currentBlog.Idioma = idioma
+	}
+	currentBlog.Extracto = doc.Extracto
+	currentBlog.ImagenPortada = doc.ImagenPortada
+	currentBlog.MetaDescripcion = doc.MetaDescripcion
+	currentBlog.MetaKeywords = doc.MetaKeywords
+	currentBlog.Tags = doc.Tags
+
+	moderated, ok := h.moderateBlog(w, r, currentBlog)
+	if !ok {
+		return
+	}
+
+	err = h.store.UpdateBlog(*currentBlog)
+	if err != nil {
+		if err.Error() == "version conflict" {
+			h.writeVersionConflict(w, currentBlog.ID)
+			return
+		}
+		if err.Error() == "translation already exists" {
+			utils.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	currentBlog.Version++
+
+	if !h.recordModeration(w, *currentBlog, moderated) {
+		return
+	}
+
+	w.Header().Set("ETag", utils.VersionETag(currentBlog.Version))
+	err = utils.WriteJSON(w, http.StatusOK, currentBlog)
+	if err != nil {
+		return
+	}
+}
+
+// isMergePatch acepta application/merge-patch+json y, por comodidad de los
+// clientes, application/json
+func isMergePatch(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == utils.MergePatchContentType || mediaType == "application/json"
+}
+
+// patchDocument devuelve los campos editables del blog, que son sobre los que
+// se aplica el parche
+func patchDocument(blog types.Blog) types.PatchBlogDocument {
+	return types.PatchBlogDocument{
+		CreateBlogPayload: types.CreateBlogPayload{
+			Titulo:          blog.Titulo,
+			Contenido:       blog.Contenido,
+			Extracto:        blog.Extracto,
+			ImagenPortada:   blog.ImagenPortada,
+			CategoriaID:     blog.CategoriaID,
+			MetaDescripcion: blog.MetaDescripcion,
+			MetaKeywords:    blog.MetaKeywords,
+			Tags:            blog.Tags,
+			Idioma:          blog.Idioma,
+			Bloques:         blog.Bloques,
+		},
+		Estado: blog.Estado,
+	}
+}
+
+// decodePatchDocument lee el documento ya parcheado. Los campos que no son
+// editables, como el slug o el autor, hacen fallar la petición en vez de
+// ignorarse sin avisar.
+func decodePatchDocument(data []byte) (types.PatchBlogDocument, error) {
+	var doc types.PatchBlogDocument
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return doc, fmt.Errorf("invalid blog patch: %v", err)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,91 @@
+package blog
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+func TestIsMergePatch(t *testing.T) {
+	tests := map[string]bool{
+		"application/merge-patch+json":                true,
+		"application/merge-patch+json; charset=utf-8": true,
+		"application/json":                            true,
+		"application/json-patch+json":                 false,
+		"text/plain":                                  false,
+		"":                                            false,
+	}
+
+	for contentType, want := range tests {
+		if got := isMergePatch(contentType); got != want {
+			t.Errorf("isMergePatch(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func TestPatchDocument(t *testing.T) {
+	blog := types.Blog{
+		Titulo:        "Hola",
+		Slug:          "hola",
+		Contenido:     "# Hola",
+		Extracto:      "Saludo",
+		ImagenPortada: "/uploads/portada.jpg",
+		CategoriaID:   "cat",
+		Estado:        "publicado",
+		MetaKeywords:  "hola,saludo",
+		Tags:          []string{"go", "api"},
+	}
+
+	current, err := json.Marshal(patchDocument(blog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := utils.MergePatch(current, []byte(`{"imagen_portada":null,"meta_keywords":null,"tags":null,"titulo":"Adiós"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := decodePatchDocument(merged)
+	if err != nil {
+		t.Fatalf("decodePatchDocument() error = %v", err)
+	}
+
+	want := types.PatchBlogDocument{
+		CreateBlogPayload: types.CreateBlogPayload{
+			Titulo:      "Adiós",
+			Contenido:   "# Hola",
+			Extracto:    "Saludo",
+			CategoriaID: "cat",
+		},
+		Estado: "publicado",
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("patched document = %+v, want %+v", doc, want)
+	}
+
+	// Los campos obligatorios no se pueden borrar
+	merged, err = utils.MergePatch(current, []byte(`{"titulo":null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = decodePatchDocument(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.Validate.Struct(doc); err == nil {
+		t.Errorf("Validate() without titulo should fail")
+	}
+
+	// Ni tocar los que no son editables
+	merged, err = utils.MergePatch(current, []byte(`{"slug":"otro"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodePatchDocument(merged); err == nil {
+		t.Errorf("decodePatchDocument() with slug should fail")
+	}
+}
@@ -0,0 +1,284 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// maxTagFilters es el número máximo de etiquetas por las que se puede filtrar
+const maxTagFilters = 10
+
+// popularidad es la clave del orden populares
+const popularidad = "(COALESCE(bc.likes, 0) + COALESCE(bc.utiles, 0))"
+
+// blogOrder es un orden de listado: una clave principal opcional seguida
+// siempre de fecha e id, para que el orden sea total y se pueda paginar
+type blogOrder struct {
+	key  string
+	desc bool
+}
+
+// blogOrders son los órdenes admitidos en ?orden. Sus expresiones son las
+// únicas que llegan a ORDER BY.
+var blogOrders = map[string]blogOrder{
+	types.OrdenRecientes:    {desc: true},
+	types.OrdenAntiguos:     {desc: false},
+	types.OrdenPopulares:    {key: popularidad, desc: true},
+	types.OrdenLecturaCorta: {key: "b.tiempo_lectura", desc: false},
+	types.OrdenLecturaLarga: {key: "b.tiempo_lectura", desc: true},
+}
+
+// selectQuery construye una consulta SELECT. Las condiciones y expresiones son
+// siempre literales del código; los valores de la petición viajan como argumentos.
+type selectQuery struct {
+	columns string
+	from    string
+	where   []string
+	args    []interface{}
+	orderBy []string
+	limit   int
+}
+
+func newSelect(columns, from string) *selectQuery {
+	return &selectQuery{columns: columns, from: from}
+}
+
+// Where añade una condición unida con AND a las anteriores
+func (q *selectQuery) Where(cond string, args ...interface{}) *selectQuery {
+	q.where = append(q.where, cond)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// After añade la condición de paginación por clave: la tupla de columnas debe
+// ir después de values en el sentido del orden
+func (q *selectQuery) After(columns []string, values []interface{}, desc bool) *selectQuery {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	cond := fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op, placeholders(len(values)))
+	return q.Where(cond, values...)
+}
+
+// OrderBy fija las expresiones de ordenación, todas en el mismo sentido
+func (q *selectQuery) OrderBy(columns []string, desc bool) *selectQuery {
+	dir := " ASC"
+	if desc {
+		dir = " DESC"
+	}
+	q.orderBy = q.orderBy[:0]
+	for _, c := range columns {
+		q.orderBy = append(q.orderBy, c+dir)
+	}
+	return q
+}
+
+func (q *selectQuery) Limit(n int) *selectQuery {
+	q.limit = n
+	return q
+}
+
+// Build devuelve la consulta SQL y sus argumentos
+func (q *selectQuery) Build() (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString("SELECT " + q.columns + " FROM " + q.from)
+	if len(q.where) > 0 {
+		sb.WriteString(" WHERE " + strings.Join(q.where, " AND "))
+	}
+	if len(q.orderBy) > 0 {
+		sb.WriteString(" ORDER BY " + strings.Join(q.orderBy, ", "))
+	}
+
+	args := append([]interface{}{}, q.args...)
+	if q.limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		args = append(args, q.limit)
+	}
+
+	return sb.String(), args
+}
+
+// filterBlogs añade a la consulta los filtros de un listado, sin el cursor
+func filterBlogs(q *selectQuery, bq types.BlogQuery) {
+	q.Where("b.estado = 'publicado'")
+	q.Where("b.eliminado_en IS NULL")
+
+	if bq.Categoria != "" {
+		// La categoría incluye a todas sus subcategorías
+		q.Where(`b.categoria_id IN (
+            WITH RECURSIVE arbol AS (
+                SELECT id FROM categorias WHERE slug = ?
+                UNION ALL
+                SELECT c.id FROM categorias c JOIN arbol a ON c.parent_id = a.id
+            )
+            SELECT id FROM arbol
+        )`, bq.Categoria)
+	}
+
+	if len(bq.Tags) > 0 {
+		args := make([]interface{}, 0, len(bq.Tags)+1)
+		for _, tag := range bq.Tags {
+			args = append(args, tag)
+		}
+
+		subquery := `SELECT pt.blog_id FROM blog_posts_tags pt
+            JOIN blog_tags t ON t.id = pt.tag_id
+            WHERE t.nombre IN (` + placeholders(len(bq.Tags)) + `)`
+		if bq.TodasLasTags {
+			subquery += " GROUP BY pt.blog_id HAVING COUNT(DISTINCT t.id) = ?"
+			args = append(args, len(bq.Tags))
+		}
+		q.Where("b.id IN ("+subquery+")", args...)
+	}
+
+	if bq.Autor != "" {
+		// Los coautores firman el blog, así que también cuentan como autores
+		q.Where(`(b.autor_apodo = ? OR b.id IN (
+            SELECT blog_id FROM blog_colaboradores
+            WHERE apodo = ? AND rol = 'coautor' AND estado = 'aceptada'
+        ))`, bq.Autor, bq.Autor)
+	}
+	if !bq.Desde.IsZero() {
+		q.Where("b.fecha_publicacion >= ?", bq.Desde)
+	}
+	if !bq.Hasta.IsZero() {
+		q.Where("b.fecha_publicacion < ?", bq.Hasta)
+	}
+	if bq.TiempoMin > 0 {
+		q.Where("b.tiempo_lectura >= ?", bq.TiempoMin)
+	}
+	if bq.TiempoMax > 0 {
+		q.Where("b.tiempo_lectura <= ?", bq.TiempoMax)
+	}
+	if bq.Idioma != "" {
+		// Una versión por grupo: la del idioma preferido o, si no la hay, la
+		// primera que se publicó
+		q.Where(`(b.idioma = ? OR NOT EXISTS (
+            SELECT 1 FROM blogs t
+            WHERE t.grupo_traduccion = b.grupo_traduccion AND t.id <> b.id
+                AND t.estado = 'publicado' AND t.eliminado_en IS NULL
+                AND (t.idioma = ? OR (t.fecha_publicacion, t.id) < (b.fecha_publicacion, b.id))
+        ))`, bq.Idioma, bq.Idioma)
+	}
+}
+
+// orderBlogs añade el orden de bq y, si hay cursor, la condición para empezar después de él
+func orderBlogs(q *selectQuery, bq types.BlogQuery) {
+	order, ok := blogOrders[bq.Orden]
+	if !ok {
+		order = blogOrders[types.OrdenRecientes]
+	}
+
+	columns := []string{"b.fecha_publicacion", "b.id"}
+	values := []interface{}{bq.After.Fecha, bq.After.ID}
+	if order.key != "" {
+		columns = append([]string{order.key}, columns...)
+		values = append([]interface{}{bq.After.Valor}, values...)
+	}
+
+	if !bq.After.IsZero() {
+		q.After(columns, values, order.desc)
+	}
+	q.OrderBy(columns, order.desc)
+}
+
+// parseBlogQuery lee los filtros y el orden de GET /blogs:
+//
+//	?categoria=  slug o nombre de la categoría; todos no filtra
+//	?tag=        repetible o separado por comas; ?tags_modo=todas exige todas
+//	?autor=      apodo del autor
+//	?desde= / ?hasta=            fechas AAAA-MM-DD, ambas inclusive
+//	?tiempo_min= / ?tiempo_max=  minutos de lectura
+//	?orden=      recientes, antiguos, populares, lectura_corta o lectura_larga
+func parseBlogQuery(r *http.Request) (types.BlogQuery, error) {
+	values := r.URL.Query()
+	var q types.BlogQuery
+
+	// Se acepta el slug o el nombre de la categoría; ambos producen el mismo
+	// slug. "Todos" es el valor que enviaban los clientes antiguos para no filtrar.
+	if categoria := utils.GenerateSlug(values.Get("categoria")); categoria != "" && categoria != "todos" {
+		q.Categoria = categoria
+	}
+
+	for _, value := range values["tag"] {
+		for _, tag := range strings.Split(value, ",") {
+			// Sin repetidas: en modo todas se cuentan y la base de datos no distingue mayúsculas
+			if tag = strings.TrimSpace(tag); tag != "" && !hasTag(q.Tags, tag) {
+				q.Tags = append(q.Tags, tag)
+			}
+		}
+	}
+	if len(q.Tags) > maxTagFilters {
+		return q, fmt.Errorf("at most %d tags can be filtered", maxTagFilters)
+	}
+
+	switch values.Get("tags_modo") {
+	case "", "alguna":
+	case "todas":
+		q.TodasLasTags = true
+	default:
+		return q, fmt.Errorf("invalid tags_modo")
+	}
+
+	q.Autor = strings.TrimSpace(values.Get("autor"))
+
+	var err error
+	if q.Desde, err = parseDate(values.Get("desde")); err != nil {
+		return q, fmt.Errorf("invalid desde")
+	}
+	if q.Hasta, err = parseDate(values.Get("hasta")); err != nil {
+		return q, fmt.Errorf("invalid hasta")
+	}
+	if !q.Hasta.IsZero() {
+		// hasta incluye el día entero
+		q.Hasta = q.Hasta.AddDate(0, 0, 1)
+	}
+	if !q.Desde.IsZero() && !q.Hasta.IsZero() && !q.Desde.Before(q.Hasta) {
+		return q, fmt.Errorf("desde must not be after hasta")
+	}
+
+	if q.TiempoMin, err = parseMinutes(values.Get("tiempo_min")); err != nil {
+		return q, fmt.Errorf("invalid tiempo_min")
+	}
+	if q.TiempoMax, err = parseMinutes(values.Get("tiempo_max")); err != nil {
+		return q, fmt.Errorf("invalid tiempo_max")
+	}
+	if q.TiempoMax > 0 && q.TiempoMin > q.TiempoMax {
+		return q, fmt.Errorf("tiempo_min must not be greater than tiempo_max")
+	}
+
+	q.Orden = values.Get("orden")
+	if q.Orden == "" {
+		q.Orden = types.OrdenRecientes
+	}
+	if _, ok := blogOrders[q.Orden]; !ok {
+		return q, fmt.Errorf("invalid orden")
+	}
+
+	return q, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+func parseMinutes(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid minutes")
+	}
+	return n, nil
+}
@@ -0,0 +1,133 @@
+package blog
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestSelectQuery(t *testing.T) {
+	query, args := newSelect("b.id", "blogs b").
+		Where("b.estado = 'publicado'").
+		Where("b.autor_apodo = ?", "ana").
+		After([]string{"b.fecha_publicacion", "b.id"}, []interface{}{"2024-01-01", "x"}, true).
+		OrderBy([]string{"b.fecha_publicacion", "b.id"}, true).
+		Limit(10).
+		Build()
+
+	want := "SELECT b.id FROM blogs b WHERE b.estado = 'publicado' AND b.autor_apodo = ? " +
+		"AND (b.fecha_publicacion, b.id) < (?, ?) ORDER BY b.fecha_publicacion DESC, b.id DESC LIMIT ?"
+	if query != want {
+		t.Errorf("Build() query = %q\nwant %q", query, want)
+	}
+	if wantArgs := []interface{}{"ana", "2024-01-01", "x", 10}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("Build() args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestFilterBlogs_Tags(t *testing.T) {
+	sq := newSelect("COUNT(*)", "blogs b")
+	filterBlogs(sq, types.BlogQuery{Tags: []string{"go", "sql"}, TodasLasTags: true})
+	query, args := sq.Build()
+
+	if !strings.Contains(query, "t.nombre IN (?, ?) GROUP BY pt.blog_id HAVING COUNT(DISTINCT t.id) = ?") {
+		t.Errorf("filterBlogs() query = %q", query)
+	}
+	if wantArgs := []interface{}{"go", "sql", 2}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("filterBlogs() args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestOrderBlogs(t *testing.T) {
+	after := types.Cursor{Fecha: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ID: "x", Valor: 7}
+
+	sq := newSelect("b.id", "blogs b")
+	orderBlogs(sq, types.BlogQuery{Orden: types.OrdenLecturaCorta, After: after})
+	query, args := sq.Build()
+
+	want := "SELECT b.id FROM blogs b WHERE (b.tiempo_lectura, b.fecha_publicacion, b.id) > (?, ?, ?) " +
+		"ORDER BY b.tiempo_lectura ASC, b.fecha_publicacion ASC, b.id ASC"
+	if query != want {
+		t.Errorf("orderBlogs() query = %q\nwant %q", query, want)
+	}
+	if wantArgs := []interface{}{int64(7), after.Fecha, "x"}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("orderBlogs() args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestParseBlogQuery(t *testing.T) {
+	r := httptest.NewRequest("GET", "/blogs?tag=go,sql&tag=web&tags_modo=todas&autor=ana"+
+		"&desde=2024-01-01&hasta=2024-01-31&tiempo_min=3&tiempo_max=10&orden=populares&categoria=Ciencias", nil)
+
+	q, err := parseBlogQuery(r)
+	if err != nil {
+		t.Fatalf("parseBlogQuery() error = %v", err)
+	}
+
+	want := types.BlogQuery{
+		Categoria:    "ciencias",
+		Tags:         []string{"go", "sql", "web"},
+		TodasLasTags: true,
+		Autor:        "ana",
+		Desde:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Hasta:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		TiempoMin:    3,
+		TiempoMax:    10,
+		Orden:        types.OrdenPopulares,
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Errorf("parseBlogQuery() = %+v\nwant %+v", q, want)
+	}
+}
+
+func TestParseBlogQuery_AllCategories(t *testing.T) {
+	for _, query := range []string{"categoria=Todos", "categoria=todos", "categoria=", "categoria=%20"} {
+		q, err := parseBlogQuery(httptest.NewRequest("GET", "/blogs?"+query, nil))
+		if err != nil {
+			t.Fatalf("parseBlogQuery(%q) error = %v", query, err)
+		}
+		if q.Categoria != "" {
+			t.Errorf("parseBlogQuery(%q) categoria = %q, want no filter", query, q.Categoria)
+		}
+	}
+}
+
+func TestParseBlogQuery_DuplicateTags(t *testing.T) {
+	r := httptest.NewRequest("GET", "/blogs?tag=go&tag=Go,sql&tag=go&tag=SQL&tags_modo=todas", nil)
+
+	q, err := parseBlogQuery(r)
+	if err != nil {
+		t.Fatalf("parseBlogQuery() error = %v", err)
+	}
+	if want := []string{"go", "sql"}; !reflect.DeepEqual(q.Tags, want) {
+		t.Errorf("parseBlogQuery() tags = %v, want %v", q.Tags, want)
+	}
+}
+
+func TestParseBlogQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"Orden desconocido", "orden=titulo"},
+		{"Modo de tags desconocido", "tag=go&tags_modo=ninguna"},
+		{"Fecha mal formada", "desde=01/01/2024"},
+		{"Rango de fechas invertido", "desde=2024-02-01&hasta=2024-01-01"},
+		{"Tiempo negativo", "tiempo_min=-1"},
+		{"Rango de tiempo invertido", "tiempo_min=10&tiempo_max=5"},
+		{"Demasiadas tags", "tag=a,b,c,d,e,f,g,h,i,j,k"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/blogs?"+tt.query, nil)
+			if _, err := parseBlogQuery(r); err == nil {
+				t.Errorf("parseBlogQuery(%q) accepted an invalid query", tt.query)
+			}
+		})
+	}
+}
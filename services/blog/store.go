@@ -2,13 +2,42 @@ package blog
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"gitlab.com/pardalis/pardalis-api/types"
 )
 
+// blogDetailColumns son las columnas de un blog completo, en el orden que espera scanBlogDetail
+const blogDetailColumns = `
+    b.id, b.titulo, b.slug, b.contenido, b.extracto,
+    b.imagen_portada, b.fecha_publicacion, b.estado,
+    COALESCE(cat.nombre, b.categoria), COALESCE(b.categoria_id, ''), COALESCE(cat.slug, ''),
+    b.tiempo_lectura, b.autor_apodo,
+    b.meta_descripcion, b.meta_keywords, b.fecha_actualizacion,
+    b.contenido_html, b.tabla_contenidos,
+    COALESCE(bc.likes, 0), COALESCE(bc.utiles, 0), COALESCE(bc.confusos, 0), b.eliminado_en, b.version,
+    b.idioma, COALESCE(b.grupo_traduccion, b.id), b.bloques`
+
+// blogSummaryColumns son las columnas de los listados, sin el contenido
+const blogSummaryColumns = `
+    b.id, b.titulo, b.slug, b.extracto,
+    b.imagen_portada, b.fecha_publicacion,
+    COALESCE(cat.nombre, b.categoria), COALESCE(b.categoria_id, ''), COALESCE(cat.slug, ''),
+    b.tiempo_lectura, b.autor_apodo,
+    b.fecha_actualizacion,
+    COALESCE(bc.likes, 0), COALESCE(bc.utiles, 0), COALESCE(bc.confusos, 0),
+    b.idioma, COALESCE(b.grupo_traduccion, b.id)`
+
+// blogJoins son las tablas de las que leen blogDetailColumns y blogSummaryColumns
+const blogJoins = `blogs b
+    LEFT JOIN categorias cat ON cat.id = b.categoria_id
+    LEFT JOIN blog_contadores bc ON bc.blog_id = b.id`
+
 type Store struct {
 	db *sql.DB
 }
@@ -29,28 +58,44 @@ func (s *Store) CreateBlog(blog types.Blog) error {
         INSERT INTO blogs (
             id, titulo, slug, contenido, extracto, 
             imagen_portada, fecha_publicacion, estado,
-            categoria, tiempo_lectura, autor_apodo,
-            meta_descripcion, meta_keywords
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+            categoria, categoria_id, tiempo_lectura, autor_apodo,
+            meta_descripcion, meta_keywords,
+            contenido_html, tabla_contenidos, idioma, grupo_traduccion, bloques
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 
+	// Un blog nuevo que no es traducción de otro abre su propio grupo
+	if blog.GrupoTraduccion == "" {
+		blog.GrupoTraduccion = blog.ID
+	}
+
+	toc, err := json.Marshal(blog.TablaContenidos)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	bloques, err := json.Marshal(blog.Bloques)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	log.Printf("Ejecutando query: %s", query)
 	log.Printf("Con valores: %+v", blog)
 
 	_, err = tx.Exec(query,
 		blog.ID, blog.Titulo, blog.Slug, blog.Contenido,
 		blog.Extracto, blog.ImagenPortada, blog.FechaPublicacion,
-		blog.Estado, blog.Categoria, blog.TiempoLectura,
+		blog.Estado, blog.Categoria, blog.CategoriaID, blog.TiempoLectura,
 		blog.AutorApodo, blog.MetaDescripcion, blog.MetaKeywords,
+		blog.ContenidoHTML, toc, blog.Idioma, blog.GrupoTraduccion, bloques,
 	)
 
 	if err != nil {
-		err := tx.Rollback()
-		if err != nil {
-			return err
-		}
+		_ = tx.Rollback()
 		log.Printf("Error al insertar el blog: %v", err)
-		return err
+		return translationError(err)
 	}
 
 	// Insertar tags
@@ -78,35 +123,49 @@ func (s *Store) CreateBlog(blog types.Blog) error {
 	return nil
 }
 
+// UpdateBlog guarda el blog si sigue en blog.Version y aumenta la versión. Si
+// otra petición lo guardó antes devuelve "version conflict".
 func (s *Store) UpdateBlog(blog types.Blog) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 
+	toc, err := json.Marshal(blog.TablaContenidos)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	bloques, err := json.Marshal(blog.Bloques)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Actualizar el blog
 	query := `
         UPDATE blogs 
         SET titulo = ?, slug = ?, contenido = ?, extracto = ?,
-            imagen_portada = ?, estado = ?, categoria = ?,
-            tiempo_lectura = ?, meta_descripcion = ?, meta_keywords = ?
-        WHERE id = ?
+            imagen_portada = ?, estado = ?, categoria = ?, categoria_id = ?,
+            tiempo_lectura = ?, meta_descripcion = ?, meta_keywords = ?,
+            contenido_html = ?, tabla_contenidos = ?, idioma = ?, bloques = ?,
+            version = version + 1
+        WHERE id = ? AND version = ? AND eliminado_en IS NULL
     `
 
 	result, err := tx.Exec(query,
 		blog.Titulo, blog.Slug, blog.Contenido,
 		blog.Extracto, blog.ImagenPortada, blog.Estado,
-		blog.Categoria, blog.TiempoLectura,
+		blog.Categoria, blog.CategoriaID, blog.TiempoLectura,
 		blog.MetaDescripcion, blog.MetaKeywords,
-		blog.ID,
+		blog.ContenidoHTML, toc, blog.Idioma, bloques,
+		blog.ID, blog.Version,
 	)
 
 	if err != nil {
-		err := tx.Rollback()
-		if err != nil {
-			return err
-		}
-		return err
+		_ = tx.Rollback()
+		return translationError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -119,10 +178,16 @@ func (s *Store) UpdateBlog(blog types.Blog) error {
 	}
 
 	if rowsAffected == 0 {
-		err := tx.Rollback()
+		// Distinguir un blog que no existe de uno que otra petición cambió antes
+		var exists bool
+		err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM blogs WHERE id = ? AND eliminado_en IS NULL)", blog.ID).Scan(&exists)
+		_ = tx.Rollback()
 		if err != nil {
 			return err
 		}
+		if exists {
+			return fmt.Errorf("version conflict")
+		}
 		return fmt.Errorf("blog not found")
 	}
 
@@ -151,50 +216,181 @@ func (s *Store) UpdateBlog(blog types.Blog) error {
 	return tx.Commit()
 }
 
+// DeleteBlog manda un blog a la papelera. Conserva sus etiquetas, series y
+// comentarios para que RestoreBlog lo deje como estaba; PurgeDeletedBlogs lo
+// borra definitivamente pasado el periodo de retención.
 func (s *Store) DeleteBlog(id string) error {
-	tx, err := s.db.Begin()
+	result, err := s.db.Exec(
+		"UPDATE blogs SET eliminado_en = CURRENT_TIMESTAMP, fecha_actualizacion = fecha_actualizacion WHERE id = ? AND eliminado_en IS NULL",
+		id,
+	)
 	if err != nil {
 		return err
 	}
 
-	// Eliminar tags primero debido a la restricción de clave foránea
-	_, err = tx.Exec("DELETE FROM blog_posts_tags WHERE blog_id = ?", id)
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		err := tx.Rollback()
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("blog not found")
+	}
+
+	return nil
+}
+
+// SlugExists indica si algún blog usa ya el slug, incluidos los borradores y
+// los de la papelera, que también lo reservan
+func (s *Store) SlugExists(slug string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM blogs WHERE slug = ?)", slug).Scan(&exists)
+	return exists, err
+}
+
+// GetAllBlogs devuelve todos los blogs fuera de la papelera, publicados o no,
+// con su contenido completo y sus tags. Lo usa la exportación.
+func (s *Store) GetAllBlogs() ([]types.Blog, error) {
+	query, args := newSelect(blogDetailColumns, blogJoins).
+		Where("b.eliminado_en IS NULL").
+		OrderBy([]string{"b.fecha_publicacion", "b.id"}, false).
+		Build()
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
 		if err != nil {
-			return err
+			log.Println(err)
 		}
-		return err
+	}(rows)
+
+	blogs := []types.Blog{}
+	for rows.Next() {
+		blog, err := scanBlogDetail(rows)
+		if err != nil {
+			return nil, err
+		}
+		blogs = append(blogs, *blog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	// Eliminar el blog
-	result, err := tx.Exec("DELETE FROM blogs WHERE id = ?", id)
+	if err := s.loadTags(blogs); err != nil {
+		return nil, err
+	}
+
+	return blogs, nil
+}
+
+// GetDeletedBlogs devuelve la papelera de un autor, lo último eliminado primero
+func (s *Store) GetDeletedBlogs(autorApodo string) ([]types.Blog, error) {
+	query, args := newSelect(blogDetailColumns, blogJoins).
+		Where("b.autor_apodo = ?", autorApodo).
+		Where("b.eliminado_en IS NOT NULL").
+		OrderBy([]string{"b.eliminado_en"}, true).
+		Build()
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		err := tx.Rollback()
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
 		if err != nil {
-			return err
+			log.Println(err)
+		}
+	}(rows)
+
+	blogs := []types.Blog{}
+	for rows.Next() {
+		blog, err := scanBlogDetail(rows)
+		if err != nil {
+			return nil, err
 		}
+		blogs = append(blogs, *blog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadTags(blogs); err != nil {
+		return nil, err
+	}
+
+	return blogs, nil
+}
+
+// GetDeletedBlogByID obtiene un blog de la papelera
+func (s *Store) GetDeletedBlogByID(id string) (*types.Blog, error) {
+	query, args := newSelect(blogDetailColumns, blogJoins).
+		Where("b.id = ?", id).
+		Where("b.eliminado_en IS NOT NULL").
+		Build()
+
+	return s.getBlog(query, args...)
+}
+
+// RestoreBlog saca un blog de la papelera. Los blogs de una cuenta eliminada
+// solo vuelven con la cuenta.
+func (s *Store) RestoreBlog(id string) error {
+	result, err := s.db.Exec(`
+        UPDATE blogs b
+        JOIN usuarios u ON u.apodo = b.autor_apodo
+        SET b.eliminado_en = NULL, b.fecha_actualizacion = b.fecha_actualizacion
+        WHERE b.id = ? AND b.eliminado_en IS NOT NULL AND u.eliminado_en IS NULL
+    `, id)
+	if err != nil {
 		return err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		err := tx.Rollback()
-		if err != nil {
-			return err
-		}
 		return err
 	}
 
 	if rowsAffected == 0 {
-		err := tx.Rollback()
-		if err != nil {
-			return err
-		}
 		return fmt.Errorf("blog not found")
 	}
 
-	return tx.Commit()
+	return nil
+}
+
+// PurgeDeletedBlogs borra definitivamente los blogs que llevan en la papelera
+// desde antes de before y devuelve cuántos eran. Las demás tablas se borran en
+// cascada salvo las etiquetas, que van primero por su clave foránea.
+func (s *Store) PurgeDeletedBlogs(before time.Time) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+        DELETE pt FROM blog_posts_tags pt
+        JOIN blogs b ON b.id = pt.blog_id
+        WHERE b.eliminado_en < ?
+    `, before)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	result, err := tx.Exec("DELETE FROM blogs WHERE eliminado_en < ?", before)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return purged, tx.Commit()
 }
 
 func (s *Store) AddBlogTag(blogID string, tag string) error {
@@ -246,72 +442,22 @@ func (s *Store) addTagTx(tx interface {
 }
 
 func (s *Store) GetBlogBySlug(slug string) (*types.Blog, error) {
-	query := `
-        SELECT 
-            b.id, b.titulo, b.slug, b.contenido, b.extracto, 
-            b.imagen_portada, b.fecha_publicacion, b.estado,
-            b.categoria, b.tiempo_lectura, b.autor_apodo,
-            b.meta_descripcion, b.meta_keywords
-        FROM blogs b
-        WHERE b.slug = ? AND b.estado = 'publicado'
-    `
+	query, args := newSelect(blogDetailColumns, blogJoins).
+		Where("b.slug = ?", slug).
+		Where("b.estado = 'publicado'").
+		Where("b.eliminado_en IS NULL").
+		Build()
 
-	blog := &types.Blog{}
-	err := s.db.QueryRow(query, slug).Scan(
-		&blog.ID, &blog.Titulo, &blog.Slug, &blog.Contenido,
-		&blog.Extracto, &blog.ImagenPortada, &blog.FechaPublicacion,
-		&blog.Estado, &blog.Categoria, &blog.TiempoLectura,
-		&blog.AutorApodo, &blog.MetaDescripcion, &blog.MetaKeywords,
-	)
-
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("blog not found")
-		}
-		return nil, err
-	}
-
-	// Obtener tags
-	tags, err := s.GetBlogTags(blog.ID)
-	if err != nil {
-		return nil, err
-	}
-	blog.Tags = tags
-
-	return blog, nil
+	return s.getBlog(query, args...)
 }
 
-func (s *Store) GetBlogs(page, limit int, categoria string) ([]types.Blog, error) {
-	offset := (page - 1) * limit
-
-	var query string
-	var args []interface{}
-
-	if categoria != "" && categoria != "Todos" {
-		query = `
-            SELECT 
-                b.id, b.titulo, b.slug, b.extracto, 
-                b.imagen_portada, b.fecha_publicacion,
-                b.categoria, b.tiempo_lectura, b.autor_apodo
-            FROM blogs b
-            WHERE b.estado = 'publicado' AND b.categoria = ?
-            ORDER BY b.fecha_publicacion DESC
-            LIMIT ? OFFSET ?
-        `
-		args = []interface{}{categoria, limit, offset}
-	} else {
-		query = `
-            SELECT 
-                b.id, b.titulo, b.slug, b.extracto, 
-                b.imagen_portada, b.fecha_publicacion,
-                b.categoria, b.tiempo_lectura, b.autor_apodo
-            FROM blogs b
-            WHERE b.estado = 'publicado'
-            ORDER BY b.fecha_publicacion DESC
-            LIMIT ? OFFSET ?
-        `
-		args = []interface{}{limit, offset}
-	}
+// GetBlogs devuelve los blogs publicados posteriores a q.After en el orden pedido.
+// La paginación es por clave (keyset), así que publicar un blog no desplaza las páginas.
+func (s *Store) GetBlogs(q types.BlogQuery) ([]types.Blog, error) {
+	sq := newSelect(blogSummaryColumns, blogJoins)
+	filterBlogs(sq, q)
+	orderBlogs(sq, q)
+	query, args := sq.Limit(q.Limit).Build()
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
@@ -330,25 +476,44 @@ func (s *Store) GetBlogs(page, limit int, categoria string) ([]types.Blog, error
 		err := rows.Scan(
 			&blog.ID, &blog.Titulo, &blog.Slug, &blog.Extracto,
 			&blog.ImagenPortada, &blog.FechaPublicacion,
-			&blog.Categoria, &blog.TiempoLectura, &blog.AutorApodo,
+			&blog.Categoria, &blog.CategoriaID, &blog.CategoriaSlug, &blog.TiempoLectura, &blog.AutorApodo,
+			&blog.FechaActualizacion,
+			&blog.Reacciones.Like, &blog.Reacciones.Util, &blog.Reacciones.Confuso,
+			&blog.Idioma, &blog.GrupoTraduccion,
 		)
 		if err != nil {
 			return nil, err
 		}
-
-		// Obtener tags para cada blog
-		tags, err := s.GetBlogTags(blog.ID)
-		if err != nil {
-			return nil, err
-		}
-		blog.Tags = tags
-
 		blogs = append(blogs, blog)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadTags(blogs); err != nil {
+		return nil, err
+	}
+	if err := s.loadAutores(blogs); err != nil {
+		return nil, err
+	}
+	if err := s.loadTraducciones(blogs); err != nil {
+		return nil, err
+	}
 
 	return blogs, nil
 }
 
+// CountBlogs devuelve cuántos blogs publicados cumplen los filtros de q
+func (s *Store) CountBlogs(q types.BlogQuery) (int, error) {
+	sq := newSelect("COUNT(*)", "blogs b")
+	filterBlogs(sq, q)
+	query, args := sq.Build()
+
+	var total int
+	err := s.db.QueryRow(query, args...).Scan(&total)
+	return total, err
+}
+
 func (s *Store) GetBlogTags(blogID string) ([]string, error) {
 	query := `
         SELECT t.nombre
@@ -380,25 +545,192 @@ func (s *Store) GetBlogTags(blogID string) ([]string, error) {
 	return tags, nil
 }
 
-func (s *Store) GetBlogByID(id string) (*types.Blog, error) {
+// loadTags rellena las tags de todos los blogs con una sola consulta, en lugar
+// de una por blog. Debe llamarse con el cursor del listado ya cerrado.
+func (s *Store) loadTags(blogs []types.Blog) error {
+	if len(blogs) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(blogs))
+	args := make([]interface{}, len(blogs))
+	for i, blog := range blogs {
+		index[blog.ID] = i
+		args[i] = blog.ID
+	}
+
 	query := `
-        SELECT 
-            b.id, b.titulo, b.slug, b.contenido, b.extracto, 
-            b.imagen_portada, b.fecha_publicacion, b.estado,
-            b.categoria, b.tiempo_lectura, b.autor_apodo,
-            b.meta_descripcion, b.meta_keywords
-        FROM blogs b
-        WHERE b.id = ?
+        SELECT pt.blog_id, t.nombre
+        FROM blog_posts_tags pt
+        JOIN blog_tags t ON t.id = pt.tag_id
+        WHERE pt.blog_id IN (` + placeholders(len(blogs)) + `)
     `
 
-	blog := &types.Blog{}
-	err := s.db.QueryRow(query, id).Scan(
-		&blog.ID, &blog.Titulo, &blog.Slug, &blog.Contenido,
-		&blog.Extracto, &blog.ImagenPortada, &blog.FechaPublicacion,
-		&blog.Estado, &blog.Categoria, &blog.TiempoLectura,
-		&blog.AutorApodo, &blog.MetaDescripcion, &blog.MetaKeywords,
-	)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var blogID, tag string
+		if err := rows.Scan(&blogID, &tag); err != nil {
+			return err
+		}
+		if i, ok := index[blogID]; ok {
+			blogs[i].Tags = append(blogs[i].Tags, tag)
+		}
+	}
+
+	return rows.Err()
+}
+
+// loadAutores rellena la firma de los blogs: el autor seguido de los coautores
+// que han aceptado la invitación, por orden de llegada. Los editores y los
+// coautores en la papelera no firman.
+func (s *Store) loadAutores(blogs []types.Blog) error {
+	if len(blogs) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(blogs))
+	args := make([]interface{}, len(blogs))
+	for i, blog := range blogs {
+		index[blog.ID] = i
+		args[i] = blog.ID
+		blogs[i].Autores = []string{blog.AutorApodo}
+	}
+
+	query := `
+        SELECT bc.blog_id, bc.apodo
+        FROM blog_colaboradores bc
+        JOIN usuarios u ON u.apodo = bc.apodo
+        WHERE bc.blog_id IN (` + placeholders(len(blogs)) + `)
+            AND bc.rol = 'coautor' AND bc.estado = 'aceptada' AND u.eliminado_en IS NULL
+        ORDER BY bc.fecha_respuesta, bc.apodo
+    `
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var blogID, apodo string
+		if err := rows.Scan(&blogID, &apodo); err != nil {
+			return err
+		}
+		if i, ok := index[blogID]; ok {
+			blogs[i].Autores = append(blogs[i].Autores, apodo)
+		}
+	}
+
+	return rows.Err()
+}
+
+// loadTraducciones rellena en cada blog las demás versiones publicadas de su
+// grupo de traducción, ordenadas por idioma
+func (s *Store) loadTraducciones(blogs []types.Blog) error {
+	if len(blogs) == 0 {
+		return nil
+	}
+
+	groups := make(map[string][]int, len(blogs))
+	args := make([]interface{}, 0, len(blogs))
+	for i, blog := range blogs {
+		if _, ok := groups[blog.GrupoTraduccion]; !ok {
+			args = append(args, blog.GrupoTraduccion)
+		}
+		groups[blog.GrupoTraduccion] = append(groups[blog.GrupoTraduccion], i)
+	}
+
+	query := `
+        SELECT grupo_traduccion, id, idioma, slug, titulo
+        FROM blogs
+        WHERE grupo_traduccion IN (` + placeholders(len(args)) + `)
+            AND estado = 'publicado' AND eliminado_en IS NULL
+        ORDER BY idioma
+    `
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var grupo string
+		var t types.Traduccion
+		if err := rows.Scan(&grupo, &t.ID, &t.Idioma, &t.Slug, &t.Titulo); err != nil {
+			return err
+		}
+		for _, i := range groups[grupo] {
+			if blogs[i].ID != t.ID {
+				blogs[i].Traducciones = append(blogs[i].Traducciones, t)
+			}
+		}
+	}
+
+	return rows.Err()
+}
+
+// translationError traduce la violación de uq_blogs_traduccion: el grupo ya
+// tiene una versión en ese idioma
+func translationError(err error) error {
+	if err != nil && strings.Contains(err.Error(), "uq_blogs_traduccion") {
+		return fmt.Errorf("translation already exists")
+	}
+	return err
+}
+
+// placeholders devuelve n marcadores "?" separados por comas para una cláusula IN
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
+
+// GetTranslation obtiene la versión publicada de un grupo de traducción en un idioma
+func (s *Store) GetTranslation(grupo string, idioma string) (*types.Blog, error) {
+	query, args := newSelect(blogDetailColumns, blogJoins).
+		Where("b.grupo_traduccion = ?", grupo).
+		Where("b.idioma = ?", idioma).
+		Where("b.estado = 'publicado'").
+		Where("b.eliminado_en IS NULL").
+		Build()
+
+	return s.getBlog(query, args...)
+}
+
+func (s *Store) GetBlogByID(id string) (*types.Blog, error) {
+	query, args := newSelect(blogDetailColumns, blogJoins).
+		Where("b.id = ?", id).
+		Where("b.eliminado_en IS NULL").
+		Build()
 
+	return s.getBlog(query, args...)
+}
+
+// getBlog obtiene un único blog con su contenido completo y sus tags
+func (s *Store) getBlog(query string, args ...interface{}) (*types.Blog, error) {
+	blog, err := scanBlogDetail(s.db.QueryRow(query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("blog not found")
@@ -412,5 +744,136 @@ func (s *Store) GetBlogByID(id string) (*types.Blog, error) {
 	}
 	blog.Tags = tags
 
+	blogs := []types.Blog{*blog}
+	if err := s.loadAutores(blogs); err != nil {
+		return nil, err
+	}
+	if err := s.loadTraducciones(blogs); err != nil {
+		return nil, err
+	}
+	blog.Autores = blogs[0].Autores
+	blog.Traducciones = blogs[0].Traducciones
+
 	return blog, nil
 }
+
+// scanBlogDetail convierte una fila con blogDetailColumns en un blog
+func scanBlogDetail(row interface{ Scan(...any) error }) (*types.Blog, error) {
+	blog := &types.Blog{}
+	var contenidoHTML, toc, bloques sql.NullString
+	var eliminadoEn sql.NullTime
+	err := row.Scan(
+		&blog.ID, &blog.Titulo, &blog.Slug, &blog.Contenido,
+		&blog.Extracto, &blog.ImagenPortada, &blog.FechaPublicacion,
+		&blog.Estado, &blog.Categoria, &blog.CategoriaID, &blog.CategoriaSlug, &blog.TiempoLectura,
+		&blog.AutorApodo, &blog.MetaDescripcion, &blog.MetaKeywords, &blog.FechaActualizacion,
+		&contenidoHTML, &toc,
+		&blog.Reacciones.Like, &blog.Reacciones.Util, &blog.Reacciones.Confuso, &eliminadoEn, &blog.Version,
+		&blog.Idioma, &blog.GrupoTraduccion, &bloques,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	blog.ContenidoHTML = contenidoHTML.String
+	if eliminadoEn.Valid {
+		blog.EliminadoEn = &eliminadoEn.Time
+	}
+	if toc.Valid {
+		if err := json.Unmarshal([]byte(toc.String), &blog.TablaContenidos); err != nil {
+			return nil, err
+		}
+	}
+	if bloques.Valid {
+		if err := json.Unmarshal([]byte(bloques.String), &blog.Bloques); err != nil {
+			return nil, err
+		}
+	}
+
+	return blog, nil
+}
+
+// GetPublishedBlogs devuelve todos los blogs publicados con su contenido completo.
+// Lo usan los procesos que necesitan recorrer el catálogo entero, como el índice de búsqueda.
+func (s *Store) GetPublishedBlogs() ([]types.Blog, error) {
+	query, args := newSelect(blogDetailColumns, blogJoins).
+		Where("b.estado = 'publicado'").
+		Where("b.eliminado_en IS NULL").
+		OrderBy([]string{"b.fecha_publicacion"}, true).
+		Build()
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	var blogs []types.Blog
+	for rows.Next() {
+		blog, err := scanBlogDetail(rows)
+		if err != nil {
+			return nil, err
+		}
+		blogs = append(blogs, *blog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadTags(blogs); err != nil {
+		return nil, err
+	}
+	if err := s.loadAutores(blogs); err != nil {
+		return nil, err
+	}
+
+	return blogs, nil
+}
+
+// GetPublishedBlogSummaries devuelve los datos básicos (sin contenido ni tags)
+// de todos los blogs publicados, para listados completos como el sitemap
+func (s *Store) GetPublishedBlogSummaries() ([]types.Blog, error) {
+	query := `
+        SELECT 
+            b.id, b.titulo, b.slug, b.imagen_portada,
+            b.fecha_publicacion, b.fecha_actualizacion,
+            COALESCE(cat.nombre, b.categoria), COALESCE(b.categoria_id, ''), COALESCE(cat.slug, ''), b.autor_apodo
+        FROM blogs b
+        LEFT JOIN categorias cat ON cat.id = b.categoria_id
+        WHERE b.estado = 'publicado' AND b.eliminado_en IS NULL
+        ORDER BY b.fecha_publicacion DESC
+    `
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	var blogs []types.Blog
+	for rows.Next() {
+		var blog types.Blog
+		err := rows.Scan(
+			&blog.ID, &blog.Titulo, &blog.Slug, &blog.ImagenPortada,
+			&blog.FechaPublicacion, &blog.FechaActualizacion,
+			&blog.Categoria, &blog.CategoriaID, &blog.CategoriaSlug, &blog.AutorApodo,
+		)
+		if err != nil {
+			return nil, err
+		}
+		blog.Estado = "publicado"
+		blogs = append(blogs, blog)
+	}
+
+	return blogs, rows.Err()
+}
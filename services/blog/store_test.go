@@ -0,0 +1,187 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"gitlab.com/pardalis/pardalis-api/db"
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{1, "?"},
+		{3, "?, ?, ?"},
+	}
+
+	for _, tt := range tests {
+		if got := placeholders(tt.n); got != tt.want {
+			t.Errorf("placeholders(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// Los benchmarks necesitan una base de datos MySQL desechable, por ejemplo:
+//
+//	PARDALIS_BENCH_DSN='root:secret@tcp(localhost:3306)/pardalis_bench?parseTime=true' \
+//	    go test ./services/blog -run '^$' -bench GetBlogs
+//
+// Crean el esquema y siembran benchBlogs blogs publicados con benchTagsPerBlog
+// tags cada uno. Además de ns/op informan de queries/op.
+const (
+	benchBlogs       = 200
+	benchTagsPerBlog = 4
+	benchPageSize    = 50
+)
+
+func BenchmarkGetBlogs(b *testing.B) {
+	store, queries := openBenchStore(b)
+
+	page, err := store.GetBlogs(types.BlogQuery{Limit: benchPageSize})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(page) != benchPageSize {
+		b.Fatalf("GetBlogs() = %d blogs, want %d", len(page), benchPageSize)
+	}
+
+	run := func(name string, fn func() error) {
+		b.Run(name, func(b *testing.B) {
+			queries.Store(0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := fn(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(queries.Load())/float64(b.N), "queries/op")
+		})
+	}
+
+	// Carga de tags de una página: antes, una consulta por blog; ahora, una por página
+	run("tags/por_blog", func() error {
+		for i := range page {
+			tags, err := store.GetBlogTags(page[i].ID)
+			if err != nil {
+				return err
+			}
+			page[i].Tags = tags
+		}
+		return nil
+	})
+	run("tags/por_pagina", func() error {
+		for i := range page {
+			page[i].Tags = nil
+		}
+		return store.loadTags(page)
+	})
+
+	// Listado completo, tal como lo sirve GET /blogs
+	run("listado", func() error {
+		_, err := store.GetBlogs(types.BlogQuery{Limit: benchPageSize})
+		return err
+	})
+}
+
+// openBenchStore abre la base de datos de PARDALIS_BENCH_DSN contando las
+// consultas que llegan al driver, y la siembra si hace falta
+func openBenchStore(b *testing.B) (*Store, *atomic.Int64) {
+	b.Helper()
+
+	dsn := os.Getenv("PARDALIS_BENCH_DSN")
+	if dsn == "" {
+		b.Skip("PARDALIS_BENCH_DSN no definido")
+	}
+
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	queries := new(atomic.Int64)
+	conn := sql.OpenDB(countingConnector{Connector: connector, queries: queries})
+	b.Cleanup(func() { _ = conn.Close() })
+
+	if err := db.InitializeDatabase(conn); err != nil {
+		b.Fatal(err)
+	}
+	if err := seedBenchBlogs(conn); err != nil {
+		b.Fatal(err)
+	}
+
+	return NewBlogStore(conn), queries
+}
+
+func seedBenchBlogs(conn *sql.DB) error {
+	_, err := conn.Exec(
+		"INSERT IGNORE INTO usuarios (apodo, nombre, correo, contrasenna) VALUES ('bench', 'Bench', 'bench@example.com', '-')",
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < benchBlogs; i++ {
+		id := fmt.Sprintf("bench-%04d", i)
+		_, err := conn.Exec(`
+            INSERT IGNORE INTO blogs (id, titulo, slug, contenido, extracto, estado, categoria, autor_apodo)
+            VALUES (?, ?, ?, 'contenido', 'extracto', 'publicado', 'General', 'bench')
+        `, id, "Blog "+id, id)
+		if err != nil {
+			return err
+		}
+
+		for j := 0; j < benchTagsPerBlog; j++ {
+			tag := fmt.Sprintf("bench-tag-%02d", (i+j)%20)
+			if _, err := conn.Exec("INSERT IGNORE INTO blog_tags (id, nombre) VALUES (UUID(), ?)", tag); err != nil {
+				return err
+			}
+			_, err := conn.Exec(`
+                INSERT IGNORE INTO blog_posts_tags (blog_id, tag_id)
+                SELECT ?, id FROM blog_tags WHERE nombre = ?
+            `, id, tag)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// countingConnector cuenta las consultas de lectura que pasan por el driver
+type countingConnector struct {
+	driver.Connector
+	queries *atomic.Int64
+}
+
+func (c countingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{Conn: conn, queries: c.queries}, nil
+}
+
+type countingConn struct {
+	driver.Conn
+	queries *atomic.Int64
+}
+
+func (c countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.queries.Add(1)
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
@@ -0,0 +1,157 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// handleCreateTranslation crea un borrador que traduce el blog a otro idioma.
+// La traducción es un blog aparte, con su slug y su autor, en el mismo grupo
+// de traducción que el original.
+func (h *Handler) handleCreateTranslation(w http.ResponseWriter, r *http.Request) {
+	autorApodo := auth.GetUserApodoFromContext(r.Context())
+	if autorApodo == "" {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	// Traduce quien puede editar el original
+	original, ok := h.authorizer.Authorize(w, r, mux.Vars(r)["id"], types.PermisoEditar)
+	if !ok {
+		return
+	}
+
+	var payload types.CreateBlogPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if payload.Idioma == "" {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("idioma is required"))
+		return
+	}
+	idioma, err := blogLocale(payload.Idioma)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	blog := types.Blog{
+		ID:               uuid.New().String(),
+		Titulo:           payload.Titulo,
+		Slug:             translationSlug(payload.Titulo, idioma, original.Slug),
+		Contenido:        payload.Contenido,
+		Extracto:         payload.Extracto,
+		ImagenPortada:    payload.ImagenPortada,
+		FechaPublicacion: time.Now(),
+		Estado:           "borrador",
+		AutorApodo:       autorApodo,
+		MetaDescripcion:  payload.MetaDescripcion,
+		MetaKeywords:     payload.MetaKeywords,
+		Tags:             payload.Tags,
+		Version:          1,
+		Idioma:           idioma,
+		GrupoTraduccion:  original.GrupoTraduccion,
+	}
+
+	if err := h.setCategory(&blog, payload.CategoriaID); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	if err := renderContent(&blog); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := setBlocks(&blog, payload.Bloques); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	moderated, ok := h.moderateBlog(w, r, &blog)
+	if !ok {
+		return
+	}
+
+	err = h.store.CreateBlog(blog)
+	if err != nil {
+		if err.Error() == "translation already exists" {
+			utils.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.recordModeration(w, blog, moderated) {
+		return
+	}
+
+	w.Header().Set("ETag", utils.VersionETag(blog.Version))
+	err = utils.WriteJSON(w, http.StatusCreated, blog)
+	if err != nil {
+		return
+	}
+}
+
+// negotiateLocale devuelve el idioma que pide el cliente: ?lang= si está,
+// si no el mejor de Accept-Language que se publique. Devuelve "" si el
+// cliente no expresa ninguna preferencia que se pueda atender.
+func negotiateLocale(r *http.Request) (string, error) {
+	if lang := strings.TrimSpace(r.URL.Query().Get("lang")); lang != "" {
+		locale := utils.MatchLocale([]string{strings.ToLower(lang)}, configs.Envs.Locales)
+		if locale == "" {
+			return "", fmt.Errorf("unsupported locale")
+		}
+		return locale, nil
+	}
+
+	return utils.MatchLocale(utils.ParseAcceptLanguage(r.Header.Get("Accept-Language")), configs.Envs.Locales), nil
+}
+
+// defaultLocale es el idioma de los blogs que no indican otro
+func defaultLocale() string {
+	if len(configs.Envs.Locales) == 0 {
+		return "es"
+	}
+	return configs.Envs.Locales[0]
+}
+
+// blogLocale valida el idioma de un blog. Vacío es el idioma por defecto.
+func blogLocale(idioma string) (string, error) {
+	if idioma == "" {
+		return defaultLocale(), nil
+	}
+	for _, locale := range configs.Envs.Locales {
+		if strings.EqualFold(idioma, locale) {
+			return locale, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported locale")
+}
+
+// translationSlug genera el slug de una traducción. Si el título coincide con
+// el del original, como pasa con los nombres propios, se distingue por idioma.
+func translationSlug(titulo string, idioma string, originalSlug string) string {
+	slug := utils.GenerateSlug(titulo)
+	if slug == originalSlug {
+		slug += "-" + idioma
+	}
+	return slug
+}
@@ -0,0 +1,60 @@
+package blog
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		want           string
+		wantErr        bool
+	}{
+		{"sin preferencia", "/blogs", "", "", false},
+		{"lang manda sobre la cabecera", "/blogs?lang=qu", "en", "qu", false},
+		{"lang no publicado", "/blogs?lang=fr", "", "", true},
+		{"cabecera con variante", "/blogs", "en-US,en;q=0.9", "en", false},
+		{"cabecera sin idiomas publicados", "/blogs", "fr,de;q=0.5", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			got, err := negotiateLocale(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("negotiateLocale() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("negotiateLocale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlogLocale(t *testing.T) {
+	if got, err := blogLocale(""); err != nil || got != defaultLocale() {
+		t.Errorf("blogLocale(\"\") = %q, %v; want %q", got, err, defaultLocale())
+	}
+	if got, err := blogLocale("EN"); err != nil || got != "en" {
+		t.Errorf("blogLocale(\"EN\") = %q, %v; want \"en\"", got, err)
+	}
+	if _, err := blogLocale("fr"); err == nil {
+		t.Errorf("blogLocale(\"fr\") should fail")
+	}
+}
+
+func TestTranslationSlug(t *testing.T) {
+	if got := translationSlug("Hello world", "en", "hola-mundo"); got != "hello-world" {
+		t.Errorf("translationSlug() = %q, want %q", got, "hello-world")
+	}
+	if got := translationSlug("Machu Picchu", "qu", "machu-picchu"); got != "machu-picchu-qu" {
+		t.Errorf("translationSlug() = %q, want %q", got, "machu-picchu-qu")
+	}
+}
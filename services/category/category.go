@@ -0,0 +1,72 @@
+// Package category gestiona el árbol de categorías de los blogs.
+package category
+
+import (
+	"sort"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// buildTree anida las categorías bajo sus padres y suma a cada una los blogs de
+// sus subcategorías. Recibe TotalBlogs con los blogs propios de cada categoría.
+func buildTree(categories []types.Category) []types.Category {
+	children := map[string][]types.Category{}
+	var roots []types.Category
+	for _, c := range categories {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var attach func(c types.Category) types.Category
+	attach = func(c types.Category) types.Category {
+		for _, child := range sortCategories(children[c.ID]) {
+			child = attach(child)
+			c.TotalBlogs += child.TotalBlogs
+			c.Subcategorias = append(c.Subcategorias, child)
+		}
+		return c
+	}
+
+	tree := []types.Category{}
+	for _, root := range sortCategories(roots) {
+		tree = append(tree, attach(root))
+	}
+
+	return tree
+}
+
+// sortCategories ordena por orden y después por nombre
+func sortCategories(categories []types.Category) []types.Category {
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].Orden != categories[j].Orden {
+			return categories[i].Orden < categories[j].Orden
+		}
+		return categories[i].Nombre < categories[j].Nombre
+	})
+	return categories
+}
+
+// createsCycle indica si colgar la categoría id de parentID formaría un ciclo,
+// es decir, si parentID es la propia categoría o una de sus descendientes
+func createsCycle(categories []types.Category, id string, parentID string) bool {
+	parents := make(map[string]string, len(categories))
+	for _, c := range categories {
+		if c.ParentID != nil {
+			parents[c.ID] = *c.ParentID
+		}
+	}
+
+	// Se sube desde el nuevo padre hasta la raíz buscando la categoría
+	seen := map[string]bool{}
+	for current := parentID; current != ""; current = parents[current] {
+		if current == id || seen[current] {
+			return true
+		}
+		seen[current] = true
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package category
+
+import (
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func ptr(s string) *string { return &s }
+
+func testCategories() []types.Category {
+	return []types.Category{
+		{ID: "idiomas", Nombre: "Idiomas", Orden: 1, TotalBlogs: 1},
+		{ID: "ciencias", Nombre: "Ciencias", Orden: 2, TotalBlogs: 0},
+		{ID: "ingles", Nombre: "Inglés", ParentID: ptr("idiomas"), TotalBlogs: 3},
+		{ID: "frances", Nombre: "Francés", ParentID: ptr("idiomas"), TotalBlogs: 2},
+		{ID: "verbos", Nombre: "Verbos", ParentID: ptr("ingles"), TotalBlogs: 4},
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	tree := buildTree(testCategories())
+
+	if len(tree) != 2 || tree[0].ID != "idiomas" || tree[1].ID != "ciencias" {
+		t.Fatalf("buildTree() roots = %+v", tree)
+	}
+
+	idiomas := tree[0]
+	if idiomas.TotalBlogs != 10 {
+		t.Errorf("buildTree() idiomas total = %d, want 10", idiomas.TotalBlogs)
+	}
+	if len(idiomas.Subcategorias) != 2 || idiomas.Subcategorias[0].ID != "frances" {
+		t.Errorf("buildTree() subcategorias = %+v, want sorted by nombre", idiomas.Subcategorias)
+	}
+	if ingles := idiomas.Subcategorias[1]; ingles.TotalBlogs != 7 || len(ingles.Subcategorias) != 1 {
+		t.Errorf("buildTree() ingles = %+v", ingles)
+	}
+}
+
+func TestCreatesCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		parentID string
+		want     bool
+	}{
+		{"Mover a otra raíz", "ingles", "ciencias", false},
+		{"Padre de sí misma", "ingles", "ingles", true},
+		{"Bajo su descendiente", "idiomas", "verbos", true},
+		{"Bajo un hermano", "frances", "ingles", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := createsCycle(testCategories(), tt.id, tt.parentID); got != tt.want {
+				t.Errorf("createsCycle(%s, %s) = %v, want %v", tt.id, tt.parentID, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package category
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// adminAction completa el error de auth.RequireAdmin en las rutas de este handler
+const adminAction = "manage categories"
+
+// Handler maneja las rutas de categorías. Solo los administradores pueden modificarlas.
+type Handler struct {
+	store     types.CategoryStore
+	userStore types.UserStore
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.CategoryStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:     store,
+		userStore: userStore,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/categorias", h.handleGetCategories).Methods(http.MethodGet)
+	router.HandleFunc("/categorias", auth.WithJWTAuth(h.handleCreateCategory, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/categorias/{id}", auth.WithJWTAuth(h.handleUpdateCategory, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/categorias/{id}", auth.WithJWTAuth(h.handleDeleteCategory, h.userStore)).Methods(http.MethodDelete)
+}
+
+// handleGetCategories devuelve el árbol de categorías con el número de blogs publicados
+func (h *Handler) handleGetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.store.GetCategories()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, buildTree(categories))
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	var payload types.CreateCategoryPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	category := types.Category{
+		ID:          uuid.New().String(),
+		Nombre:      strings.TrimSpace(payload.Nombre),
+		Descripcion: payload.Descripcion,
+		Orden:       payload.Orden,
+		Icono:       payload.Icono,
+	}
+	category.Slug = utils.GenerateSlug(category.Nombre)
+
+	if payload.ParentID != "" {
+		if _, err := h.store.GetCategoryByID(payload.ParentID); err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		category.ParentID = &payload.ParentID
+	}
+
+	if err := h.store.CreateCategory(category); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusCreated, category)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	category, err := h.store.GetCategoryByID(mux.Vars(r)["id"])
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	var payload types.UpdateCategoryPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if nombre := strings.TrimSpace(payload.Nombre); nombre != "" {
+		category.Nombre = nombre
+		category.Slug = utils.GenerateSlug(nombre)
+	}
+	if payload.Descripcion != nil {
+		category.Descripcion = *payload.Descripcion
+	}
+	if payload.Orden != nil {
+		category.Orden = *payload.Orden
+	}
+	if payload.Icono != nil {
+		category.Icono = *payload.Icono
+	}
+	if payload.ParentID != nil {
+		if *payload.ParentID == "" {
+			category.ParentID = nil
+		} else {
+			all, err := h.store.GetCategories()
+			if err != nil {
+				utils.WriteError(w, http.StatusInternalServerError, err)
+				return
+			}
+			if !exists(all, *payload.ParentID) {
+				utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("parent category not found"))
+				return
+			}
+			if createsCycle(all, category.ID, *payload.ParentID) {
+				utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("a category cannot be nested under itself"))
+				return
+			}
+			category.ParentID = payload.ParentID
+		}
+	}
+
+	if err := h.store.UpdateCategory(*category); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, category)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	if err := h.store.DeleteCategory(mux.Vars(r)["id"]); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Category deleted successfully"})
+	if err != nil {
+		return
+	}
+}
+
+func exists(categories []types.Category, id string) bool {
+	for _, c := range categories {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case err.Error() == "category not found":
+		utils.WriteError(w, http.StatusNotFound, err)
+	case err.Error() == "category in use":
+		utils.WriteError(w, http.StatusConflict, err)
+	case strings.Contains(err.Error(), "Duplicate entry"):
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("category already exists"))
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,165 @@
+package category
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Store implementa CategoryStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// GetCategories devuelve todas las categorías sin anidar. TotalBlogs cuenta
+// solo los blogs publicados directamente en cada categoría.
+func (s *Store) GetCategories() ([]types.Category, error) {
+	rows, err := s.db.Query(`
+        SELECT c.id, c.nombre, c.slug, c.descripcion, c.parent_id, c.orden, c.icono,
+            c.fecha_creacion, COUNT(b.id)
+        FROM categorias c
+        LEFT JOIN blogs b ON b.categoria_id = c.id AND b.estado = 'publicado' AND b.eliminado_en IS NULL
+        GROUP BY c.id
+        ORDER BY c.orden, c.nombre
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	categories := []types.Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows, true)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetCategoryByID obtiene una categoría por su id
+func (s *Store) GetCategoryByID(id string) (*types.Category, error) {
+	row := s.db.QueryRow(`
+        SELECT id, nombre, slug, descripcion, parent_id, orden, icono, fecha_creacion
+        FROM categorias
+        WHERE id = ?
+    `, id)
+
+	c, err := scanCategory(row, false)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// CreateCategory guarda una categoría nueva
+func (s *Store) CreateCategory(c types.Category) error {
+	_, err := s.db.Exec(`
+        INSERT INTO categorias (id, nombre, slug, descripcion, parent_id, orden, icono)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `, c.ID, c.Nombre, c.Slug, c.Descripcion, c.ParentID, c.Orden, c.Icono)
+	return err
+}
+
+// UpdateCategory actualiza una categoría. Los blogs guardan también el nombre
+// en su antigua columna de texto, así que se actualiza en la misma transacción.
+func (s *Store) UpdateCategory(c types.Category) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE categorias
+        SET nombre = ?, slug = ?, descripcion = ?, parent_id = ?, orden = ?, icono = ?
+        WHERE id = ?
+    `, c.Nombre, c.Slug, c.Descripcion, c.ParentID, c.Orden, c.Icono, c.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE blogs SET categoria = ?, fecha_actualizacion = fecha_actualizacion WHERE categoria_id = ?",
+		c.Nombre, c.ID,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCategory elimina una categoría que no tenga blogs ni subcategorías
+func (s *Store) DeleteCategory(id string) error {
+	var inUse bool
+	err := s.db.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM blogs WHERE categoria_id = ?)
+            OR EXISTS (SELECT 1 FROM categorias WHERE parent_id = ?)
+    `, id, id).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return fmt.Errorf("category in use")
+	}
+
+	result, err := s.db.Exec("DELETE FROM categorias WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	return nil
+}
+
+// scanCategory convierte una fila en una categoría. withCount indica si la
+// consulta incluye el número de blogs como última columna.
+func scanCategory(row interface{ Scan(...any) error }, withCount bool) (*types.Category, error) {
+	c := new(types.Category)
+	var descripcion, parentID, icono sql.NullString
+
+	dest := []any{&c.ID, &c.Nombre, &c.Slug, &descripcion, &parentID, &c.Orden, &icono, &c.FechaCreacion}
+	if withCount {
+		dest = append(dest, &c.TotalBlogs)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	c.Descripcion = descripcion.String
+	c.Icono = icono.String
+	if parentID.Valid {
+		c.ParentID = &parentID.String
+	}
+
+	return c, nil
+}
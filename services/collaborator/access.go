@@ -0,0 +1,148 @@
+package collaborator
+
+import (
+	"fmt"
+	"net/http"
+
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// allPermissions son los permisos del autor, en el orden en que se muestran
+var allPermissions = []string{
+	types.PermisoEditar,
+	types.PermisoPublicar,
+	types.PermisoEliminar,
+	types.PermisoGestionar,
+}
+
+// rolePermissions son los permisos de cada papel si la invitación no los indica
+var rolePermissions = map[string][]string{
+	types.ColaboradorCoautor: {types.PermisoEditar, types.PermisoPublicar},
+	types.ColaboradorEditor:  {types.PermisoEditar},
+}
+
+// normalizePermissions devuelve los permisos de una invitación sin repetir y
+// en orden. Quien colabora siempre puede editar; gestionar no se delega.
+func normalizePermissions(rol string, permisos []string) []string {
+	if len(permisos) == 0 {
+		permisos = rolePermissions[rol]
+	}
+
+	granted := map[string]bool{types.PermisoEditar: true}
+	for _, p := range permisos {
+		granted[p] = true
+	}
+
+	normalized := []string{}
+	for _, p := range allPermissions {
+		if granted[p] && p != types.PermisoGestionar {
+			normalized = append(normalized, p)
+		}
+	}
+	return normalized
+}
+
+// permissionsFor devuelve los permisos de apodo sobre blog. c es su
+// invitación, o nil si no tiene.
+func permissionsFor(blog types.Blog, apodo string, c *types.Colaborador) []string {
+	switch {
+	case apodo != "" && blog.AutorApodo == apodo:
+		return allPermissions
+	case c != nil && c.Estado == types.InvitacionAceptada:
+		return c.Permisos
+	default:
+		return nil
+	}
+}
+
+// Authorizer es el único punto donde se decide qué puede hacer un usuario con
+// un blog. Los handlers que modifican blogs lo usan en lugar de comparar el
+// autor, para que los colaboradores funcionen en todas partes.
+type Authorizer struct {
+	store     types.CollaboratorStore
+	blogStore types.BlogStore
+}
+
+// NewAuthorizer crea una nueva instancia de Authorizer
+func NewAuthorizer(store types.CollaboratorStore, blogStore types.BlogStore) *Authorizer {
+	return &Authorizer{
+		store:     store,
+		blogStore: blogStore,
+	}
+}
+
+// Permissions devuelve los permisos de apodo sobre blog
+func (a *Authorizer) Permissions(blog types.Blog, apodo string) ([]string, error) {
+	if apodo == "" {
+		return nil, nil
+	}
+	if blog.AutorApodo == apodo {
+		return permissionsFor(blog, apodo, nil), nil
+	}
+
+	c, err := a.store.GetCollaborator(blog.ID, apodo)
+	if err != nil {
+		if err.Error() == "collaborator not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return permissionsFor(blog, apodo, c), nil
+}
+
+// Can indica si apodo tiene un permiso sobre blog
+func (a *Authorizer) Can(blog types.Blog, apodo string, permiso string) (bool, error) {
+	permisos, err := a.Permissions(blog, apodo)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range permisos {
+		if p == permiso {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Authorize carga el blog y comprueba que el usuario autenticado tiene el
+// permiso. Si no existe responde 404 y si no tiene permiso 403; en ambos casos
+// devuelve false y el handler solo tiene que terminar.
+func (a *Authorizer) Authorize(w http.ResponseWriter, r *http.Request, blogID string, permiso string) (*types.Blog, bool) {
+	blog, err := a.blogStore.GetBlogByID(blogID)
+	if err != nil {
+		if err.Error() == "blog not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return nil, false
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	ok, err := a.Can(*blog, auth.GetUserApodoFromContext(r.Context()), permiso)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if !ok {
+		utils.WriteError(w, http.StatusForbidden, forbidden(permiso))
+		return nil, false
+	}
+
+	return blog, true
+}
+
+// forbidden es el error de cada permiso denegado
+func forbidden(permiso string) error {
+	switch permiso {
+	case types.PermisoPublicar:
+		return fmt.Errorf("not authorized to publish this blog")
+	case types.PermisoEliminar:
+		return fmt.Errorf("not authorized to delete this blog")
+	case types.PermisoGestionar:
+		return fmt.Errorf("only the author can manage collaborators")
+	default:
+		return fmt.Errorf("not authorized to update this blog")
+	}
+}
@@ -0,0 +1,59 @@
+package collaborator
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestNormalizePermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		rol      string
+		permisos []string
+		want     []string
+	}{
+		{"coautor por defecto", types.ColaboradorCoautor, nil, []string{"editar", "publicar"}},
+		{"editor por defecto", types.ColaboradorEditor, nil, []string{"editar"}},
+		{"editar siempre incluido", types.ColaboradorEditor, []string{"eliminar"}, []string{"editar", "eliminar"}},
+		{"orden y duplicados", types.ColaboradorCoautor, []string{"eliminar", "publicar", "eliminar"}, []string{"editar", "publicar", "eliminar"}},
+		{"gestionar no se delega", types.ColaboradorCoautor, []string{"gestionar"}, []string{"editar"}},
+	}
+
+	for _, tt := range tests {
+		if got := normalizePermissions(tt.rol, tt.permisos); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: normalizePermissions() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionsFor(t *testing.T) {
+	blog := types.Blog{ID: "b1", AutorApodo: "ana"}
+	aceptada := &types.Colaborador{Apodo: "luis", Estado: types.InvitacionAceptada, Permisos: []string{"editar"}}
+	pendiente := &types.Colaborador{Apodo: "eva", Estado: types.InvitacionPendiente, Permisos: []string{"editar", "publicar"}}
+
+	if got := permissionsFor(blog, "ana", nil); !reflect.DeepEqual(got, allPermissions) {
+		t.Errorf("permissionsFor(autor) = %v, want %v", got, allPermissions)
+	}
+	if got := permissionsFor(blog, "luis", aceptada); !reflect.DeepEqual(got, []string{"editar"}) {
+		t.Errorf("permissionsFor(aceptada) = %v, want [editar]", got)
+	}
+	if got := permissionsFor(blog, "eva", pendiente); got != nil {
+		t.Errorf("permissionsFor(pendiente) = %v, want none", got)
+	}
+	if got := permissionsFor(blog, "pablo", nil); got != nil {
+		t.Errorf("permissionsFor(ajeno) = %v, want none", got)
+	}
+	// Un blog sin autor cargado no da permisos a un usuario anónimo
+	if got := permissionsFor(types.Blog{}, "", nil); got != nil {
+		t.Errorf("permissionsFor(anónimo) = %v, want none", got)
+	}
+
+	if !aceptada.Can(types.PermisoEditar) || aceptada.Can(types.PermisoPublicar) {
+		t.Errorf("Colaborador.Can() with an accepted invitation = wrong permissions")
+	}
+	if pendiente.Can(types.PermisoEditar) {
+		t.Errorf("Colaborador.Can() with a pending invitation = true, want false")
+	}
+}
@@ -0,0 +1,264 @@
+package collaborator
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Handler maneja los colaboradores de los blogs y las invitaciones
+type Handler struct {
+	store      types.CollaboratorStore
+	authorizer *Authorizer
+	userStore  types.UserStore
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.CollaboratorStore, authorizer *Authorizer, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:      store,
+		authorizer: authorizer,
+		userStore:  userStore,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/blogs/{id}/collaborators", auth.WithJWTAuth(h.handleGetCollaborators, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/blogs/{id}/collaborators", auth.WithJWTAuth(h.handleInvite, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/blogs/{id}/collaborators/{apodo}", auth.WithJWTAuth(h.handleUpdateCollaborator, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/blogs/{id}/collaborators/{apodo}", auth.WithJWTAuth(h.handleRemoveCollaborator, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/invitations", auth.WithJWTAuth(h.handleGetInvitations, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/invitations/{blogId}/accept", auth.WithJWTAuth(h.handleAccept, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/invitations/{blogId}/decline", auth.WithJWTAuth(h.handleDecline, h.userStore)).Methods(http.MethodPost)
+}
+
+// handleGetCollaborators lista los colaboradores de un blog a quien puede editarlo
+func (h *Handler) handleGetCollaborators(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.authorizer.Authorize(w, r, mux.Vars(r)["id"], types.PermisoEditar)
+	if !ok {
+		return
+	}
+
+	collaborators, err := h.store.GetCollaborators(blog.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, collaborators)
+	if err != nil {
+		return
+	}
+}
+
+// handleInvite invita a un usuario a colaborar. La invitación no da permisos
+// hasta que el usuario la acepta.
+func (h *Handler) handleInvite(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.authorizer.Authorize(w, r, mux.Vars(r)["id"], types.PermisoGestionar)
+	if !ok {
+		return
+	}
+
+	var payload types.InviteCollaboratorPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if payload.Apodo == blog.AutorApodo {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the author cannot be a collaborator"))
+		return
+	}
+
+	if _, err := h.userStore.GetUserByApodo(payload.Apodo); err != nil {
+		if err.Error() == "user not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	c := types.Colaborador{
+		BlogID:      blog.ID,
+		Apodo:       payload.Apodo,
+		Rol:         payload.Rol,
+		Permisos:    normalizePermissions(payload.Rol, payload.Permisos),
+		InvitadoPor: auth.GetUserApodoFromContext(r.Context()),
+	}
+	if err := h.store.InviteCollaborator(c); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	created, err := h.store.GetCollaborator(blog.ID, c.Apodo)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusCreated, created)
+	if err != nil {
+		return
+	}
+}
+
+// handleUpdateCollaborator cambia el papel o los permisos de un colaborador
+func (h *Handler) handleUpdateCollaborator(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	blog, ok := h.authorizer.Authorize(w, r, vars["id"], types.PermisoGestionar)
+	if !ok {
+		return
+	}
+
+	var payload types.UpdateCollaboratorPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	c, err := h.store.GetCollaborator(blog.ID, vars["apodo"])
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	permisos := c.Permisos
+	if payload.Rol != "" && payload.Rol != c.Rol {
+		c.Rol = payload.Rol
+		permisos = nil // Un papel nuevo sin permisos explícitos toma los del papel
+	}
+	if payload.Permisos != nil {
+		permisos = payload.Permisos
+	}
+	c.Permisos = normalizePermissions(c.Rol, permisos)
+
+	if err := h.store.UpdateCollaborator(*c); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, c)
+	if err != nil {
+		return
+	}
+}
+
+// handleRemoveCollaborator retira a un colaborador. El autor puede retirar a
+// cualquiera y cada colaborador puede abandonar el blog.
+func (h *Handler) handleRemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	permiso := types.PermisoGestionar
+	if vars["apodo"] == auth.GetUserApodoFromContext(r.Context()) {
+		permiso = types.PermisoEditar
+	}
+
+	blog, ok := h.authorizer.Authorize(w, r, vars["id"], permiso)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveCollaborator(blog.ID, vars["apodo"]); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetInvitations lista las invitaciones pendientes del usuario autenticado
+func (h *Handler) handleGetInvitations(w http.ResponseWriter, r *http.Request) {
+	pagination, err := utils.ParsePagination(r, 20, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apodo := auth.GetUserApodoFromContext(r.Context())
+	invitations, err := h.store.GetInvitations(apodo, pagination.After.Fecha, pagination.After.ID, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WritePage(w, r, utils.NewPage(invitations, pagination.Limit, invitationCursor))
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleAccept(w http.ResponseWriter, r *http.Request) {
+	blogID := mux.Vars(r)["blogId"]
+	apodo := auth.GetUserApodoFromContext(r.Context())
+
+	if err := h.store.AcceptInvitation(blogID, apodo); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	c, err := h.store.GetCollaborator(blogID, apodo)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, c)
+	if err != nil {
+		return
+	}
+}
+
+// handleDecline rechaza una invitación pendiente, que se borra
+func (h *Handler) handleDecline(w http.ResponseWriter, r *http.Request) {
+	blogID := mux.Vars(r)["blogId"]
+	apodo := auth.GetUserApodoFromContext(r.Context())
+
+	c, err := h.store.GetCollaborator(blogID, apodo)
+	if err != nil || c.Estado != types.InvitacionPendiente {
+		if err == nil || err.Error() == "collaborator not found" {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("invitation not found"))
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := h.store.RemoveCollaborator(blogID, apodo); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "collaborator not found", "invitation not found":
+		utils.WriteError(w, http.StatusNotFound, err)
+	case "user is already a collaborator":
+		utils.WriteError(w, http.StatusConflict, err)
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// invitationCursor devuelve la posición de una invitación, que se ordena por
+// fecha de invitación e id del blog
+func invitationCursor(c types.Colaborador) types.Cursor {
+	return types.Cursor{Fecha: c.FechaInvitacion, ID: c.BlogID}
+}
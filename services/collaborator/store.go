@@ -0,0 +1,181 @@
+// Package collaborator gestiona los coautores y editores de los blogs y
+// centraliza la comprobación de permisos sobre un blog.
+package collaborator
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// collaboratorColumns son las columnas que espera scanCollaborator, en orden
+const collaboratorColumns = `
+	c.blog_id, b.titulo, c.apodo, c.rol, c.permisos, c.estado, c.invitado_por,
+	c.fecha_invitacion, c.fecha_respuesta
+`
+
+// Store implementa CollaboratorStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// InviteCollaborator guarda una invitación pendiente
+func (s *Store) InviteCollaborator(c types.Colaborador) error {
+	_, err := s.db.Exec(`
+        INSERT INTO blog_colaboradores (blog_id, apodo, rol, permisos, estado, invitado_por)
+        VALUES (?, ?, ?, ?, 'pendiente', ?)
+    `, c.BlogID, c.Apodo, c.Rol, strings.Join(c.Permisos, ","), c.InvitadoPor)
+	if err != nil && strings.Contains(err.Error(), "Duplicate entry") {
+		return fmt.Errorf("user is already a collaborator")
+	}
+	return err
+}
+
+// GetCollaborator obtiene la invitación de un usuario a un blog, aceptada o no
+func (s *Store) GetCollaborator(blogID string, apodo string) (*types.Colaborador, error) {
+	row := s.db.QueryRow(`
+        SELECT `+collaboratorColumns+`
+        FROM blog_colaboradores c
+        JOIN blogs b ON b.id = c.blog_id
+        WHERE c.blog_id = ? AND c.apodo = ?
+    `, blogID, apodo)
+
+	c, err := scanCollaborator(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("collaborator not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetCollaborators devuelve los colaboradores de un blog, incluidas las invitaciones pendientes
+func (s *Store) GetCollaborators(blogID string) ([]types.Colaborador, error) {
+	return s.list(`
+        SELECT `+collaboratorColumns+`
+        FROM blog_colaboradores c
+        JOIN blogs b ON b.id = c.blog_id
+        WHERE c.blog_id = ?
+        ORDER BY c.fecha_invitacion
+    `, blogID)
+}
+
+// GetInvitations devuelve las invitaciones pendientes de un usuario posteriores
+// al cursor, de la más reciente a la más antigua
+func (s *Store) GetInvitations(apodo string, afterFecha time.Time, afterID string, limit int) ([]types.Colaborador, error) {
+	return s.list(`
+        SELECT `+collaboratorColumns+`
+        FROM blog_colaboradores c
+        JOIN blogs b ON b.id = c.blog_id
+        WHERE c.apodo = ? AND c.estado = 'pendiente' AND b.eliminado_en IS NULL
+            AND (? = '' OR (c.fecha_invitacion, c.blog_id) < (?, ?))
+        ORDER BY c.fecha_invitacion DESC, c.blog_id DESC
+        LIMIT ?
+    `, apodo, afterID, afterFecha, afterID, limit)
+}
+
+// AcceptInvitation acepta una invitación pendiente
+func (s *Store) AcceptInvitation(blogID string, apodo string) error {
+	result, err := s.db.Exec(`
+        UPDATE blog_colaboradores
+        SET estado = 'aceptada', fecha_respuesta = CURRENT_TIMESTAMP
+        WHERE blog_id = ? AND apodo = ? AND estado = 'pendiente'
+    `, blogID, apodo)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, "invitation not found")
+}
+
+// UpdateCollaborator cambia el papel y los permisos de un colaborador sin tocar su estado
+func (s *Store) UpdateCollaborator(c types.Colaborador) error {
+	_, err := s.db.Exec(
+		"UPDATE blog_colaboradores SET rol = ?, permisos = ? WHERE blog_id = ? AND apodo = ?",
+		c.Rol, strings.Join(c.Permisos, ","), c.BlogID, c.Apodo,
+	)
+	return err
+}
+
+// RemoveCollaborator retira a un colaborador o rechaza su invitación
+func (s *Store) RemoveCollaborator(blogID string, apodo string) error {
+	result, err := s.db.Exec("DELETE FROM blog_colaboradores WHERE blog_id = ? AND apodo = ?", blogID, apodo)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result, "collaborator not found")
+}
+
+func (s *Store) list(query string, args ...interface{}) ([]types.Colaborador, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	collaborators := []types.Colaborador{}
+	for rows.Next() {
+		c, err := scanCollaborator(rows)
+		if err != nil {
+			return nil, err
+		}
+		collaborators = append(collaborators, *c)
+	}
+
+	return collaborators, rows.Err()
+}
+
+func requireAffected(result sql.Result, notFound string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New(notFound)
+	}
+	return nil
+}
+
+// scanCollaborator convierte una fila con collaboratorColumns en un colaborador
+func scanCollaborator(row interface{ Scan(...any) error }) (*types.Colaborador, error) {
+	c := new(types.Colaborador)
+	var permisos string
+	var respuesta sql.NullTime
+
+	err := row.Scan(
+		&c.BlogID, &c.BlogTitulo, &c.Apodo, &c.Rol, &permisos, &c.Estado, &c.InvitadoPor,
+		&c.FechaInvitacion, &respuesta,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// MySQL devuelve los SET como valores separados por comas
+	c.Permisos = []string{}
+	if permisos != "" {
+		c.Permisos = strings.Split(permisos, ",")
+	}
+	if respuesta.Valid {
+		c.FechaRespuesta = &respuesta.Time
+	}
+
+	return c, nil
+}
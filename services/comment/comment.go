@@ -0,0 +1,66 @@
+// Package comment implementa los hilos de comentarios de los blogs y su moderación.
+package comment
+
+import (
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+const (
+	// EditWindow es el tiempo durante el que el autor puede editar su comentario
+	EditWindow = 15 * time.Minute
+
+	// NewAccountAge es la antigüedad mínima de una cuenta para publicar sin moderación
+	NewAccountAge = 7 * 24 * time.Hour
+)
+
+// initialState decide si un comentario se publica directamente o queda
+// retenido en la cola de moderación. Se retienen los comentarios de menores y
+// de cuentas nuevas, salvo que escriban moderadores o el autor del blog.
+func initialState(user *types.User, blog *types.Blog, now time.Time) string {
+	if user.EsModerador() || user.Apodo == blog.AutorApodo {
+		return types.ComentarioVisible
+	}
+	if user.EsMenor(now) || now.Sub(user.Registro) < NewAccountAge {
+		return types.ComentarioPendiente
+	}
+	return types.ComentarioVisible
+}
+
+// buildTree anida las respuestas bajo sus padres. Los comentarios eliminados
+// que no conservan respuestas visibles se descartan.
+func buildTree(roots []types.Comment, replies []types.Comment) []types.Comment {
+	children := map[string][]types.Comment{}
+	for _, reply := range replies {
+		if reply.ParentID != nil {
+			children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+		}
+	}
+
+	var attach func(c types.Comment) (types.Comment, bool)
+	attach = func(c types.Comment) (types.Comment, bool) {
+		for _, child := range children[c.ID] {
+			if nested, ok := attach(child); ok {
+				c.Respuestas = append(c.Respuestas, nested)
+			}
+		}
+		keep := c.Estado != types.ComentarioEliminado || len(c.Respuestas) > 0
+		return c, keep
+	}
+
+	tree := []types.Comment{}
+	for _, root := range roots {
+		if c, ok := attach(root); ok {
+			tree = append(tree, c)
+		}
+	}
+
+	return tree
+}
+
+// commentCursor devuelve la posición de un comentario en los listados, que se
+// ordenan por fecha de creación e id
+func commentCursor(c types.Comment) types.Cursor {
+	return types.Cursor{Fecha: c.FechaCreacion, ID: c.ID}
+}
@@ -0,0 +1,67 @@
+package comment
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestInitialState(t *testing.T) {
+	now := time.Date(2024, 9, 10, 12, 0, 0, 0, time.UTC)
+	adulto := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	menor := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+	blog := &types.Blog{AutorApodo: "ana"}
+
+	tests := []struct {
+		name string
+		user types.User
+		want string
+	}{
+		{"Cuenta antigua", types.User{Apodo: "luis", Registro: now.AddDate(0, -1, 0), FechaNacimiento: &adulto}, types.ComentarioVisible},
+		{"Sin fecha de nacimiento", types.User{Apodo: "luis", Registro: now.AddDate(0, -1, 0)}, types.ComentarioPendiente},
+		{"Cuenta nueva", types.User{Apodo: "luis", Registro: now.AddDate(0, 0, -2), FechaNacimiento: &adulto}, types.ComentarioPendiente},
+		{"Menor de edad", types.User{Apodo: "luis", Registro: now.AddDate(-1, 0, 0), FechaNacimiento: &menor}, types.ComentarioPendiente},
+		{"Autor del blog", types.User{Apodo: "ana", Registro: now, FechaNacimiento: &menor}, types.ComentarioVisible},
+		{"Profesor", types.User{Apodo: "sofia", Rol: types.RolProfesor, Registro: now}, types.ComentarioVisible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := initialState(&tt.user, blog, now); got != tt.want {
+				t.Errorf("initialState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	roots := []types.Comment{
+		{ID: "a", Estado: types.ComentarioVisible},
+		{ID: "b", Estado: types.ComentarioEliminado},
+		{ID: "c", Estado: types.ComentarioEliminado},
+	}
+	replies := []types.Comment{
+		{ID: "a1", ParentID: ptr("a"), Estado: types.ComentarioVisible},
+		{ID: "a1x", ParentID: ptr("a1"), Estado: types.ComentarioVisible},
+		{ID: "a2", ParentID: ptr("a"), Estado: types.ComentarioEliminado},
+		{ID: "b1", ParentID: ptr("b"), Estado: types.ComentarioVisible},
+	}
+
+	tree := buildTree(roots, replies)
+
+	// c se descarta: está eliminado y no tiene respuestas
+	if len(tree) != 2 || tree[0].ID != "a" || tree[1].ID != "b" {
+		t.Fatalf("buildTree() roots = %+v", tree)
+	}
+	if len(tree[0].Respuestas) != 1 || tree[0].Respuestas[0].ID != "a1" {
+		t.Errorf("buildTree() replies of a = %+v", tree[0].Respuestas)
+	}
+	if len(tree[0].Respuestas[0].Respuestas) != 1 {
+		t.Errorf("buildTree() nested replies missing")
+	}
+	if len(tree[1].Respuestas) != 1 {
+		t.Errorf("buildTree() deleted root lost its replies")
+	}
+}
@@ -0,0 +1,503 @@
+package comment
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/services/collaborator"
+	"gitlab.com/pardalis/pardalis-api/services/moderation"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Handler maneja los comentarios de los blogs y la cola de moderación
+type Handler struct {
+	store      types.CommentStore
+	blogStore  types.BlogStore
+	userStore  types.UserStore
+	authorizer *collaborator.Authorizer
+	moderation *moderation.Pipeline
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.CommentStore, blogStore types.BlogStore, userStore types.UserStore, authorizer *collaborator.Authorizer, pipeline *moderation.Pipeline) *Handler {
+	return &Handler{
+		store:      store,
+		blogStore:  blogStore,
+		userStore:  userStore,
+		authorizer: authorizer,
+		moderation: pipeline,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/blogs/{slug}/comments", h.handleGetComments).Methods("GET")
+	router.HandleFunc("/blogs/{slug}/comments", auth.WithJWTAuth(h.handleCreateComment, h.userStore)).Methods("POST")
+	router.HandleFunc("/comments/moderation", auth.WithJWTAuth(h.handleGetModerationQueue, h.userStore)).Methods("GET")
+	router.HandleFunc("/comments/{id}", auth.WithJWTAuth(h.handleUpdateComment, h.userStore)).Methods("PUT")
+	router.HandleFunc("/comments/{id}", auth.WithJWTAuth(h.handleDeleteComment, h.userStore)).Methods("DELETE")
+	router.HandleFunc("/comments/{id}/moderation", auth.WithJWTAuth(h.handleModerateComment, h.userStore)).Methods("POST")
+}
+
+func (h *Handler) handleGetComments(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.getPublishedBlog(w, mux.Vars(r)["slug"])
+	if !ok {
+		return
+	}
+
+	pagination, err := utils.ParsePagination(r, 20, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Se pide un hilo de más para saber si existe una página siguiente
+	threads, err := h.store.GetThreads(blog.ID, pagination.After.Fecha, pagination.After.ID, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	threadPage := utils.NewPage(threads, pagination.Limit, commentCursor)
+	threads = threadPage.Items
+	page := types.CommentPage{NextCursor: threadPage.NextCursor}
+
+	var pinned []types.Comment
+	if pagination.After.IsZero() {
+		pinned, err = h.store.GetPinnedThreads(blog.ID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ids := make([]string, 0, len(threads)+len(pinned))
+	for _, c := range pinned {
+		ids = append(ids, c.ID)
+	}
+	for _, c := range threads {
+		ids = append(ids, c.ID)
+	}
+	replies, err := h.store.GetReplies(ids)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	page.Fijados = buildTree(pinned, replies)
+	page.Items = buildTree(threads, replies)
+
+	utils.SetPageLinks(w, r, page.NextCursor)
+	err = utils.WriteJSON(w, http.StatusOK, page)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleCreateComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.getUser(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.CreateCommentPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	blog, ok := h.getPublishedBlog(w, mux.Vars(r)["slug"])
+	if !ok {
+		return
+	}
+
+	result, ok := h.moderate(w, user, payload.Contenido)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	comment := types.Comment{
+		ID:            uuid.New().String(),
+		BlogID:        blog.ID,
+		AutorApodo:    user.Apodo,
+		Contenido:     payload.Contenido,
+		Estado:        initialState(user, blog, now),
+		FechaCreacion: now,
+	}
+
+	// Las respuestas heredan el hilo de su padre
+	if payload.ParentID != "" {
+		parent, err := h.store.GetCommentByID(payload.ParentID)
+		if err != nil || parent.BlogID != blog.ID || parent.Estado != types.ComentarioVisible {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid parent comment"))
+			return
+		}
+
+		root := parent
+		if parent.RaizID != nil {
+			root, err = h.store.GetCommentByID(*parent.RaizID)
+			if err != nil {
+				utils.WriteError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		if root.Bloqueado {
+			utils.WriteError(w, http.StatusForbidden, fmt.Errorf("thread is locked"))
+			return
+		}
+
+		comment.ParentID = &parent.ID
+		comment.RaizID = &root.ID
+	}
+
+	// Lo retenido por la moderación espera en la cola aunque el autor no lo necesite
+	if result.Decision == types.ModeracionRetener {
+		comment.Estado = types.ComentarioPendiente
+	}
+
+	err := h.store.CreateComment(comment)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.recordModeration(w, comment, user, result) {
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusCreated, comment)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleUpdateComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.getUser(w, r)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.getComment(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	if comment.AutorApodo != user.Apodo {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to update this comment"))
+		return
+	}
+
+	now := time.Now()
+	if comment.Estado == types.ComentarioEliminado || comment.Estado == types.ComentarioOculto ||
+		now.Sub(comment.FechaCreacion) > EditWindow {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("comment can no longer be edited"))
+		return
+	}
+
+	var payload types.UpdateCommentPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	blog, err := h.blogStore.GetBlogByID(comment.BlogID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, ok := h.moderate(w, user, payload.Contenido)
+	if !ok {
+		return
+	}
+
+	// Un comentario ya aprobado no vuelve a la cola salvo que lo retenga la
+	// moderación; uno retenido sigue retenido mientras el autor no cumpla los
+	// requisitos
+	if comment.Estado == types.ComentarioPendiente {
+		comment.Estado = initialState(user, blog, now)
+	}
+	if result.Decision == types.ModeracionRetener {
+		comment.Estado = types.ComentarioPendiente
+	}
+	comment.Contenido = payload.Contenido
+	comment.FechaEdicion = &now
+
+	err = h.store.UpdateCommentContent(comment.ID, comment.Contenido, comment.Estado)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.recordModeration(w, *comment, user, result) {
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, comment)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.getUser(w, r)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.getComment(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	if comment.AutorApodo != user.Apodo {
+		if !h.authorizeModeration(w, user, comment) {
+			return
+		}
+	}
+
+	// El comentario se conserva vacío para no romper las respuestas que cuelgan de él
+	err := h.store.SetCommentState(comment.ID, types.ComentarioEliminado)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Comment deleted successfully"})
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleModerateComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.getUser(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.ModerateCommentPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	comment, ok := h.getComment(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	if !h.authorizeModeration(w, user, comment) {
+		return
+	}
+
+	if comment.Estado == types.ComentarioEliminado {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("comment was deleted"))
+		return
+	}
+
+	// Lo que retuvo la moderación solo lo aprueba un administrador desde su cola
+	if (payload.Accion == "aprobar" || payload.Accion == "mostrar") && user.Rol != types.RolAdmin {
+		held, err := h.moderation.IsHeld(types.ContenidoComentario, comment.ID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if held {
+			utils.WriteError(w, http.StatusForbidden, fmt.Errorf("comment is held for review by an administrator"))
+			return
+		}
+	}
+
+	var err error
+	switch payload.Accion {
+	case "aprobar", "mostrar":
+		comment.Estado = types.ComentarioVisible
+		err = h.store.SetCommentState(comment.ID, comment.Estado)
+	case "rechazar", "ocultar":
+		comment.Estado = types.ComentarioOculto
+		err = h.store.SetCommentState(comment.ID, comment.Estado)
+	default:
+		// fijar y bloquear actúan sobre el hilo completo
+		if comment.ParentID != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("only thread roots can be pinned or locked"))
+			return
+		}
+		switch payload.Accion {
+		case "fijar":
+			comment.Fijado = true
+		case "desfijar":
+			comment.Fijado = false
+		case "bloquear":
+			comment.Bloqueado = true
+		case "desbloquear":
+			comment.Bloqueado = false
+		}
+		err = h.store.SetCommentFlags(comment.ID, comment.Fijado, comment.Bloqueado)
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, comment)
+	if err != nil {
+		return
+	}
+}
+
+// handleGetModerationQueue lista los comentarios pendientes. Los moderadores ven
+// toda la cola; los demás usuarios solo la de sus propios blogs.
+func (h *Handler) handleGetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.getUser(w, r)
+	if !ok {
+		return
+	}
+
+	pagination, err := utils.ParsePagination(r, 20, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	blogAutor := user.Apodo
+	if user.EsModerador() {
+		blogAutor = ""
+	}
+
+	pending, err := h.store.GetPendingComments(blogAutor, pagination.After.Fecha, pagination.After.ID, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WritePage(w, r, utils.NewPage(pending, pagination.Limit, commentCursor))
+	if err != nil {
+		return
+	}
+}
+
+// moderate pasa el texto del comentario por la moderación. Si se rechaza
+// responde 422 y devuelve false.
+func (h *Handler) moderate(w http.ResponseWriter, user *types.User, contenido string) (types.ModerationResult, bool) {
+	result, err := h.moderation.Check(types.ContenidoComentario, user, contenido)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return result, false
+	}
+	if result.Decision == types.ModeracionRechazar {
+		moderation.WriteRejection(w, result)
+		return result, false
+	}
+	return result, true
+}
+
+// recordModeration deja el caso en la cola de revisión una vez guardado el
+// comentario. Si falla responde 500 y devuelve false.
+func (h *Handler) recordModeration(w http.ResponseWriter, comment types.Comment, user *types.User, result types.ModerationResult) bool {
+	err := h.moderation.Record(types.ContenidoComentario, comment.ID, user, comment.Contenido, result, false)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	moderation.SetDecisionHeader(w, result)
+	return true
+}
+
+// authorizeModeration comprueba que el usuario es moderador o puede publicar en
+// el blog del comentario y responde 403 en caso contrario
+func (h *Handler) authorizeModeration(w http.ResponseWriter, user *types.User, comment *types.Comment) bool {
+	if user.EsModerador() {
+		return true
+	}
+
+	blog, err := h.blogStore.GetBlogByID(comment.BlogID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	canPublish, err := h.authorizer.Can(*blog, user.Apodo, types.PermisoPublicar)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	if !canPublish {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to moderate this comment"))
+		return false
+	}
+
+	return true
+}
+
+// getUser obtiene el usuario autenticado completo, necesario para conocer su rol
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) (*types.User, bool) {
+	apodo := auth.GetUserApodoFromContext(r.Context())
+	if apodo == "" {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return nil, false
+	}
+
+	user, err := h.userStore.GetUserByApodo(apodo)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return nil, false
+	}
+
+	return user, true
+}
+
+func (h *Handler) getComment(w http.ResponseWriter, id string) (*types.Comment, bool) {
+	comment, err := h.store.GetCommentByID(id)
+	if err != nil {
+		if err.Error() == "comment not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return nil, false
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// getPublishedBlog obtiene un blog por su slug; los borradores no admiten comentarios
+func (h *Handler) getPublishedBlog(w http.ResponseWriter, slug string) (*types.Blog, bool) {
+	blog, err := h.blogStore.GetBlogBySlug(slug)
+	if err != nil {
+		if err.Error() == "blog not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return nil, false
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if blog.Estado != "publicado" {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("blog not found"))
+		return nil, false
+	}
+
+	return blog, true
+}
@@ -0,0 +1,214 @@
+package comment
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// commentColumns son las columnas que scanComment espera, en orden
+const commentColumns = `
+	c.id, c.blog_id, c.parent_id, c.raiz_id, COALESCE(c.autor_apodo, ''), c.contenido,
+	c.estado, c.fijado, c.bloqueado, c.fecha_creacion, c.fecha_edicion
+`
+
+// Store implementa CommentStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// CreateComment guarda un comentario nuevo
+func (s *Store) CreateComment(c types.Comment) error {
+	_, err := s.db.Exec(`
+        INSERT INTO comentarios (
+            id, blog_id, parent_id, raiz_id, autor_apodo,
+            contenido, estado, fecha_creacion
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `, c.ID, c.BlogID, c.ParentID, c.RaizID, c.AutorApodo, c.Contenido, c.Estado, c.FechaCreacion)
+	return err
+}
+
+// GetCommentByID obtiene un comentario por su id
+func (s *Store) GetCommentByID(id string) (*types.Comment, error) {
+	row := s.db.QueryRow("SELECT "+commentColumns+" FROM comentarios c WHERE c.id = ?", id)
+
+	c, err := scanComment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("comment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Las actualizaciones no comprueban RowsAffected: MySQL cuenta 0 filas cuando
+// los valores no cambian, y el handler ya verificó que el comentario existe.
+
+// UpdateCommentContent reemplaza el texto de un comentario y marca la fecha de edición
+func (s *Store) UpdateCommentContent(id string, contenido string, estado string) error {
+	_, err := s.db.Exec(
+		"UPDATE comentarios SET contenido = ?, estado = ?, fecha_edicion = CURRENT_TIMESTAMP WHERE id = ?",
+		contenido, estado, id,
+	)
+	return err
+}
+
+// SetCommentState cambia el estado de moderación de un comentario. Al eliminarlo
+// también se borra su texto.
+func (s *Store) SetCommentState(id string, estado string) error {
+	query := "UPDATE comentarios SET estado = ? WHERE id = ?"
+	if estado == types.ComentarioEliminado {
+		query = "UPDATE comentarios SET estado = ?, contenido = '' WHERE id = ?"
+	}
+
+	_, err := s.db.Exec(query, estado, id)
+	return err
+}
+
+// SetCommentFlags fija o bloquea un hilo
+func (s *Store) SetCommentFlags(id string, fijado bool, bloqueado bool) error {
+	_, err := s.db.Exec(
+		"UPDATE comentarios SET fijado = ?, bloqueado = ? WHERE id = ?",
+		fijado, bloqueado, id,
+	)
+	return err
+}
+
+// Las lecturas públicas omiten los comentarios de usuarios en la papelera. Los
+// de cuentas ya purgadas no tienen autor y se muestran como eliminados.
+
+// GetPinnedThreads devuelve los hilos fijados visibles de un blog
+func (s *Store) GetPinnedThreads(blogID string) ([]types.Comment, error) {
+	return s.query(`
+        SELECT `+commentColumns+`
+        FROM comentarios c
+        LEFT JOIN usuarios u ON u.apodo = c.autor_apodo
+        WHERE c.blog_id = ? AND c.parent_id IS NULL AND c.fijado = TRUE
+            AND c.estado = 'visible' AND u.eliminado_en IS NULL
+        ORDER BY c.fecha_creacion, c.id
+    `, blogID)
+}
+
+// GetThreads devuelve los hilos no fijados de un blog posteriores al cursor,
+// incluyendo los eliminados para no perder sus respuestas
+func (s *Store) GetThreads(blogID string, afterFecha time.Time, afterID string, limit int) ([]types.Comment, error) {
+	return s.query(`
+        SELECT `+commentColumns+`
+        FROM comentarios c
+        LEFT JOIN usuarios u ON u.apodo = c.autor_apodo
+        WHERE c.blog_id = ? AND c.parent_id IS NULL AND c.fijado = FALSE
+            AND c.estado IN ('visible', 'eliminado') AND u.eliminado_en IS NULL
+            AND (c.fecha_creacion > ? OR (c.fecha_creacion = ? AND c.id > ?))
+        ORDER BY c.fecha_creacion, c.id
+        LIMIT ?
+    `, blogID, afterFecha, afterFecha, afterID, limit)
+}
+
+// GetReplies devuelve todas las respuestas visibles o eliminadas de los hilos dados
+func (s *Store) GetReplies(raizIDs []string) ([]types.Comment, error) {
+	if len(raizIDs) == 0 {
+		return []types.Comment{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(raizIDs)), ", ")
+	args := make([]interface{}, 0, len(raizIDs))
+	for _, id := range raizIDs {
+		args = append(args, id)
+	}
+
+	return s.query(`
+        SELECT `+commentColumns+`
+        FROM comentarios c
+        LEFT JOIN usuarios u ON u.apodo = c.autor_apodo
+        WHERE c.raiz_id IN (`+placeholders+`)
+            AND c.estado IN ('visible', 'eliminado') AND u.eliminado_en IS NULL
+        ORDER BY c.fecha_creacion, c.id
+    `, args...)
+}
+
+// GetPendingComments devuelve la cola de moderación. Si blogAutor no está vacío
+// solo incluye comentarios en blogs de ese autor o en los que colabora con
+// permiso para publicar. Los retenidos por la moderación automática quedan
+// fuera: esos los revisa un administrador en su propia cola.
+func (s *Store) GetPendingComments(blogAutor string, afterFecha time.Time, afterID string, limit int) ([]types.Comment, error) {
+	return s.query(`
+        SELECT `+commentColumns+`
+        FROM comentarios c
+        JOIN blogs b ON b.id = c.blog_id
+        WHERE c.estado = 'pendiente' AND b.eliminado_en IS NULL
+            AND (? = '' OR b.autor_apodo = ? OR b.id IN (
+                SELECT blog_id FROM blog_colaboradores
+                WHERE apodo = ? AND estado = 'aceptada' AND FIND_IN_SET('publicar', permisos)
+            ))
+            AND c.id NOT IN (
+                SELECT recurso_id FROM moderacion_casos
+                WHERE tipo = 'comentario' AND estado = 'pendiente' AND decision = 'retener'
+            )
+            AND (c.fecha_creacion > ? OR (c.fecha_creacion = ? AND c.id > ?))
+        ORDER BY c.fecha_creacion, c.id
+        LIMIT ?
+    `, blogAutor, blogAutor, blogAutor, afterFecha, afterFecha, afterID, limit)
+}
+
+func (s *Store) query(query string, args ...interface{}) ([]types.Comment, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	comments := []types.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *c)
+	}
+
+	return comments, rows.Err()
+}
+
+// scanComment convierte una fila en un comentario
+func scanComment(row interface{ Scan(...any) error }) (*types.Comment, error) {
+	c := new(types.Comment)
+	var parentID, raizID sql.NullString
+	var fechaEdicion sql.NullTime
+
+	err := row.Scan(
+		&c.ID, &c.BlogID, &parentID, &raizID, &c.AutorApodo, &c.Contenido,
+		&c.Estado, &c.Fijado, &c.Bloqueado, &c.FechaCreacion, &fechaEdicion,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		c.ParentID = &parentID.String
+	}
+	if raizID.Valid {
+		c.RaizID = &raizID.String
+	}
+	if fechaEdicion.Valid {
+		c.FechaEdicion = &fechaEdicion.Time
+	}
+
+	return c, nil
+}
@@ -0,0 +1,314 @@
+package content
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// MaxBlocks es el número máximo de bloques de un blog
+const MaxBlocks = 50
+
+var (
+	// blockID son los ids que se pueden citar desde el Markdown
+	blockID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+	// blockRefLine es una línea que solo contiene {{bloque:id}}
+	blockRefLine = regexp.MustCompile(`^\{\{bloque:([a-z0-9][a-z0-9-]{0,63})\}\}\s*$`)
+	youTubeID    = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoID      = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// NormalizeBlocks valida los bloques de un blog y completa lo que calcula el
+// servidor: los ids que faltan, las letras de las opciones, el proveedor de
+// los vídeos y el HTML de los avisos. Comprueba además que el Markdown solo
+// cita bloques que existen.
+func NormalizeBlocks(source string, bloques []types.Bloque) ([]types.Bloque, error) {
+	if len(bloques) > MaxBlocks {
+		return nil, fmt.Errorf("a blog can have at most %d blocks", MaxBlocks)
+	}
+
+	normalized := make([]types.Bloque, 0, len(bloques))
+	used := map[string]bool{}
+	for i, b := range bloques {
+		if err := normalizeBlock(&b); err != nil {
+			return nil, fmt.Errorf("block %d: %v", i+1, err)
+		}
+		if b.ID != "" {
+			if !blockID.MatchString(b.ID) {
+				return nil, fmt.Errorf("block %d: id must contain only lowercase letters, digits and hyphens", i+1)
+			}
+			if used[b.ID] {
+				return nil, fmt.Errorf("block %d: id %q is repeated", i+1, b.ID)
+			}
+			used[b.ID] = true
+		}
+		normalized = append(normalized, b)
+	}
+
+	// Los bloques sin id reciben el primer bloque-N libre
+	next := 1
+	for i := range normalized {
+		if normalized[i].ID != "" {
+			continue
+		}
+		for used["bloque-"+strconv.Itoa(next)] {
+			next++
+		}
+		normalized[i].ID = "bloque-" + strconv.Itoa(next)
+		used[normalized[i].ID] = true
+	}
+
+	for _, ref := range BlockRefs(source) {
+		if !used[ref] {
+			return nil, fmt.Errorf("content references unknown block %q", ref)
+		}
+	}
+
+	return normalized, nil
+}
+
+// normalizeBlock valida un bloque y completa sus campos calculados
+func normalizeBlock(b *types.Bloque) error {
+	if err := utils.Validate.Struct(b); err != nil {
+		return err
+	}
+
+	present := map[string]bool{
+		types.BloqueTipoCodigo:   b.Codigo != nil,
+		types.BloqueTipoPregunta: b.Pregunta != nil,
+		types.BloqueTipoGaleria:  b.Galeria != nil,
+		types.BloqueTipoVideo:    b.Video != nil,
+		types.BloqueTipoAviso:    b.Aviso != nil,
+	}
+	for tipo, ok := range present {
+		if ok && tipo != b.Tipo {
+			return fmt.Errorf("%s block cannot have a %s field", b.Tipo, tipo)
+		}
+	}
+	if !present[b.Tipo] {
+		return fmt.Errorf("%s block requires a %s field", b.Tipo, b.Tipo)
+	}
+
+	switch b.Tipo {
+	case types.BloqueTipoGaleria:
+		for i, img := range b.Galeria.Imagenes {
+			if _, ok := httpURL(img.URL); !ok {
+				return fmt.Errorf("image %d: url must be http or https", i+1)
+			}
+		}
+	case types.BloqueTipoPregunta:
+		return normalizeQuestion(b.Pregunta)
+	case types.BloqueTipoVideo:
+		return normalizeVideo(b.Video)
+	case types.BloqueTipoAviso:
+		rendered, err := Render(b.Aviso.Texto)
+		if err != nil {
+			return err
+		}
+		b.Aviso.TextoHTML = rendered.HTML
+	}
+	return nil
+}
+
+// normalizeQuestion asigna las letras de las opciones y comprueba que la
+// pregunta tiene respuesta
+func normalizeQuestion(p *types.BloquePregunta) error {
+	correctas := 0
+	for i := range p.Opciones {
+		p.Opciones[i].ID = string(rune('a' + i))
+		if p.Opciones[i].Correcta {
+			correctas++
+		}
+	}
+
+	switch {
+	case correctas == 0:
+		return fmt.Errorf("question needs at least one correct option")
+	case correctas > 1 && !p.Multiple:
+		return fmt.Errorf("question has %d correct options but multiple is false", correctas)
+	}
+	return nil
+}
+
+// normalizeVideo reconoce el proveedor y el id del vídeo. Un ?t= de YouTube
+// se usa como inicio si no se indicó otro.
+func normalizeVideo(v *types.BloqueVideo) error {
+	proveedor, id, inicio, err := parseVideoURL(v.URL)
+	if err != nil {
+		return err
+	}
+
+	v.Proveedor = proveedor
+	v.VideoID = id
+	if v.Inicio == 0 {
+		v.Inicio = inicio
+	}
+	return nil
+}
+
+// parseVideoURL acepta enlaces de YouTube y Vimeo y enlaces directos a
+// archivos de vídeo
+func parseVideoURL(raw string) (proveedor string, id string, inicio int, err error) {
+	u, ok := httpURL(raw)
+	if !ok {
+		return "", "", 0, fmt.Errorf("invalid video url")
+	}
+
+	host := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), "m.")
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch host {
+	case "youtube.com", "youtube-nocookie.com":
+		if u.Path == "/watch" {
+			id = u.Query().Get("v")
+		} else if len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "live") {
+			id = segments[1]
+		}
+		proveedor = types.VideoYouTube
+	case "youtu.be":
+		if len(segments) == 1 {
+			id = segments[0]
+		}
+		proveedor = types.VideoYouTube
+	case "vimeo.com":
+		if len(segments) == 1 {
+			id = segments[0]
+		}
+		proveedor = types.VideoVimeo
+	case "player.vimeo.com":
+		if len(segments) == 2 && segments[0] == "video" {
+			id = segments[1]
+		}
+		proveedor = types.VideoVimeo
+	default:
+		switch strings.ToLower(path.Ext(u.Path)) {
+		case ".mp4", ".webm", ".ogg", ".ogv":
+			return types.VideoArchivo, "", 0, nil
+		}
+		return "", "", 0, fmt.Errorf("video url must be a YouTube or Vimeo link or an mp4, webm or ogg file")
+	}
+
+	switch {
+	case proveedor == types.VideoYouTube && !youTubeID.MatchString(id):
+		return "", "", 0, fmt.Errorf("invalid YouTube video url")
+	case proveedor == types.VideoVimeo && !vimeoID.MatchString(id):
+		return "", "", 0, fmt.Errorf("invalid Vimeo video url")
+	}
+
+	if t := strings.TrimSuffix(u.Query().Get("t"), "s"); t != "" && proveedor == types.VideoYouTube {
+		inicio, _ = strconv.Atoi(t)
+	}
+	return proveedor, id, max(inicio, 0), nil
+}
+
+// httpURL interpreta raw y solo la acepta si es una URL http o https con
+// host. El frontend pinta estas URLs tal cual, así que javascript:, data: y
+// demás esquemas quedan fuera.
+func httpURL(raw string) (*url.URL, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	return u, scheme == "http" || scheme == "https"
+}
+
+// BlockRefs devuelve los ids de los bloques que cita el Markdown, en orden
+func BlockRefs(source string) []string {
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+
+	refs := []string{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if ref, ok := n.(*BlockRef); ok && entering {
+			refs = append(refs, ref.ID)
+		}
+		return ast.WalkContinue, nil
+	})
+	return refs
+}
+
+// KindBlockRef es el tipo de nodo para las líneas {{bloque:id}}
+var KindBlockRef = ast.NewNodeKind("BlockRef")
+
+// BlockRef marca el sitio de un bloque interactivo dentro del contenido
+type BlockRef struct {
+	ast.BaseBlock
+	ID string
+}
+
+func (n *BlockRef) Kind() ast.NodeKind {
+	return KindBlockRef
+}
+
+func (n *BlockRef) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"ID": n.ID}, nil)
+}
+
+// blockRefParser reconoce una línea que solo contiene {{bloque:id}}
+type blockRefParser struct{}
+
+func (p blockRefParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p blockRefParser) Open(_ ast.Node, reader text.Reader, _ parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	match := blockRefLine.FindSubmatch(line)
+	if match == nil {
+		return nil, parser.NoChildren
+	}
+
+	reader.Advance(segment.Len() - 1)
+	return &BlockRef{ID: string(match[1])}, parser.NoChildren
+}
+
+func (p blockRefParser) Continue(ast.Node, text.Reader, parser.Context) parser.State {
+	return parser.Close
+}
+
+func (p blockRefParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+func (p blockRefParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p blockRefParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// blockRefRenderer escribe un div vacío que el frontend sustituye por el bloque
+type blockRefRenderer struct{}
+
+func (r blockRefRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindBlockRef, r.renderBlockRef)
+}
+
+func (r blockRefRenderer) renderBlockRef(w util.BufWriter, _ []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(`<div class="bloque" data-bloque="` + n.(*BlockRef).ID + `"></div>` + "\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// blockRefExtension registra el parser y el renderer de {{bloque:id}} en goldmark
+type blockRefExtension struct{}
+
+func (e blockRefExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(blockRefParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(blockRefRenderer{}, 500)))
+}
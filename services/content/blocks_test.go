@@ -0,0 +1,159 @@
+package content
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestRenderBlockRef(t *testing.T) {
+	got, err := Render("Antes\n{{bloque:quiz-1}}\n\nDespués `{{bloque:no}}`")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if want := `<div class="bloque" data-bloque="quiz-1"></div>`; !strings.Contains(got.HTML, want) {
+		t.Errorf("Render() HTML = %q, want it to contain %q", got.HTML, want)
+	}
+	if strings.Count(got.HTML, "data-bloque") != 1 {
+		t.Errorf("Render() HTML = %q, want a single block", got.HTML)
+	}
+}
+
+func TestBlockRefs(t *testing.T) {
+	got := BlockRefs("{{bloque:a}}\n\ntexto {{bloque:b}}\n\n```\n{{bloque:c}}\n```\n\n{{bloque:d}}")
+	if want := []string{"a", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BlockRefs() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeBlocks(t *testing.T) {
+	bloques := []types.Bloque{
+		{Tipo: types.BloqueTipoPregunta, Pregunta: &types.BloquePregunta{
+			Enunciado: "¿2 + 2?",
+			Opciones:  []types.OpcionPregunta{{Texto: "3"}, {Texto: "4", Correcta: true}},
+		}},
+		{ID: "bloque-1", Tipo: types.BloqueTipoVideo, Video: &types.BloqueVideo{URL: "https://youtu.be/dQw4w9WgXcQ?t=42"}},
+		{Tipo: types.BloqueTipoAviso, Aviso: &types.BloqueAviso{Variante: "nota", Texto: "**Ojo**"}},
+	}
+
+	got, err := NormalizeBlocks("{{bloque:bloque-2}}", bloques)
+	if err != nil {
+		t.Fatalf("NormalizeBlocks() error = %v", err)
+	}
+
+	var ids []string
+	for _, b := range got {
+		ids = append(ids, b.ID)
+	}
+	if want := []string{"bloque-2", "bloque-1", "bloque-3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("NormalizeBlocks() ids = %v, want %v", ids, want)
+	}
+	if got[0].Pregunta.Opciones[1].ID != "b" {
+		t.Errorf("option id = %q, want %q", got[0].Pregunta.Opciones[1].ID, "b")
+	}
+	if v := got[1].Video; v.Proveedor != types.VideoYouTube || v.VideoID != "dQw4w9WgXcQ" || v.Inicio != 42 {
+		t.Errorf("video = %+v", v)
+	}
+	if !strings.Contains(got[2].Aviso.TextoHTML, "<strong>Ojo</strong>") {
+		t.Errorf("aviso html = %q", got[2].Aviso.TextoHTML)
+	}
+
+	if got, err := NormalizeBlocks("sin bloques", nil); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("NormalizeBlocks(nil) = %v, %v", got, err)
+	}
+
+	// Un blog sin bloques los devuelve como lista vacía, nunca null ni ausentes
+	for _, blog := range []types.Blog{{}, {Bloques: []types.Bloque{}}} {
+		data, err := json.Marshal(blog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"bloques":[]`) {
+			t.Errorf("json.Marshal(blog) = %s, want \"bloques\":[]", data)
+		}
+	}
+}
+
+func TestNormalizeBlocksErrors(t *testing.T) {
+	codigo := &types.BloqueCodigo{Lenguaje: "go", Codigo: "package main"}
+	tests := []struct {
+		name    string
+		source  string
+		bloques []types.Bloque
+	}{
+		{"bloque citado que no existe", "{{bloque:falta}}", []types.Bloque{{Tipo: types.BloqueTipoCodigo, Codigo: codigo}}},
+		{"id repetido", "", []types.Bloque{{ID: "x", Tipo: types.BloqueTipoCodigo, Codigo: codigo}, {ID: "x", Tipo: types.BloqueTipoCodigo, Codigo: codigo}}},
+		{"id inválido", "", []types.Bloque{{ID: "Con Espacios", Tipo: types.BloqueTipoCodigo, Codigo: codigo}}},
+		{"tipo sin datos", "", []types.Bloque{{Tipo: types.BloqueTipoGaleria}}},
+		{"datos de otro tipo", "", []types.Bloque{{Tipo: types.BloqueTipoVideo, Codigo: codigo}}},
+		{"lenguaje desconocido", "", []types.Bloque{{Tipo: types.BloqueTipoCodigo, Codigo: &types.BloqueCodigo{Lenguaje: "cobol", Codigo: "x"}}}},
+		{"pregunta sin respuesta", "", []types.Bloque{{Tipo: types.BloqueTipoPregunta, Pregunta: &types.BloquePregunta{
+			Enunciado: "?", Opciones: []types.OpcionPregunta{{Texto: "a"}, {Texto: "b"}},
+		}}}},
+		{"varias correctas sin multiple", "", []types.Bloque{{Tipo: types.BloqueTipoPregunta, Pregunta: &types.BloquePregunta{
+			Enunciado: "?", Opciones: []types.OpcionPregunta{{Texto: "a", Correcta: true}, {Texto: "b", Correcta: true}},
+		}}}},
+		{"galería sin alt", "", []types.Bloque{{Tipo: types.BloqueTipoGaleria, Galeria: &types.BloqueGaleria{
+			Imagenes: []types.ImagenGaleria{{URL: "https://pardalis.mx/a.png"}},
+		}}}},
+		{"vídeo no soportado", "", []types.Bloque{{Tipo: types.BloqueTipoVideo, Video: &types.BloqueVideo{URL: "https://ejemplo.com/video"}}}},
+		{"galería con javascript:", "", galleryWith("javascript:alert(1)")},
+		{"galería con data:", "", galleryWith("data:image/svg+xml,<svg onload=alert(1)>")},
+		{"galería con ftp:", "", galleryWith("ftp://ejemplo.com/a.png")},
+		{"vídeo con javascript:", "", []types.Bloque{{Tipo: types.BloqueTipoVideo, Video: &types.BloqueVideo{URL: "javascript://youtube.com/%0Aalert(1)"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NormalizeBlocks(tt.source, tt.bloques); err == nil {
+				t.Errorf("NormalizeBlocks() should fail")
+			}
+		})
+	}
+}
+
+func galleryWith(url string) []types.Bloque {
+	return []types.Bloque{{Tipo: types.BloqueTipoGaleria, Galeria: &types.BloqueGaleria{
+		Imagenes: []types.ImagenGaleria{{URL: url, Alt: "imagen"}},
+	}}}
+}
+
+func TestNormalizeGalleryURL(t *testing.T) {
+	for _, url := range []string{"https://pardalis.mx/a.png", "HTTP://pardalis.mx/b.jpg"} {
+		if _, err := NormalizeBlocks("", galleryWith(url)); err != nil {
+			t.Errorf("NormalizeBlocks(%q) error = %v", url, err)
+		}
+	}
+}
+
+func TestParseVideoURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		proveedor string
+		id        string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", types.VideoYouTube, "dQw4w9WgXcQ"},
+		{"https://youtube.com/shorts/dQw4w9WgXcQ", types.VideoYouTube, "dQw4w9WgXcQ"},
+		{"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", types.VideoYouTube, "dQw4w9WgXcQ"},
+		{"https://vimeo.com/76979871", types.VideoVimeo, "76979871"},
+		{"https://player.vimeo.com/video/76979871", types.VideoVimeo, "76979871"},
+		{"https://cdn.pardalis.mx/clases/celula.MP4", types.VideoArchivo, ""},
+	}
+
+	for _, tt := range tests {
+		proveedor, id, _, err := parseVideoURL(tt.url)
+		if err != nil || proveedor != tt.proveedor || id != tt.id {
+			t.Errorf("parseVideoURL(%q) = %q, %q, %v", tt.url, proveedor, id, err)
+		}
+	}
+
+	for _, url := range []string{"https://www.youtube.com/watch?v=corto", "https://vimeo.com/canal/abc", "ftp://ejemplo.com/a.mp4"} {
+		if _, _, _, err := parseVideoURL(url); err == nil {
+			t.Errorf("parseVideoURL(%q) should fail", url)
+		}
+	}
+}
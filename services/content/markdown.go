@@ -0,0 +1,343 @@
+// Package content convierte el Markdown que escriben los autores en HTML
+// seguro, junto con la tabla de contenidos y el tiempo de lectura.
+package content
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// WordsPerMinute es la velocidad de lectura usada para calcular tiempo_lectura
+const WordsPerMinute = 200
+
+// Rendered es el resultado de procesar el Markdown de un blog
+type Rendered struct {
+	HTML            string
+	TablaContenidos []types.TocEntry
+	Palabras        int
+	TiempoLectura   int
+}
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, mathExtension{}, blockRefExtension{}),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithXHTML()),
+)
+
+var policy = newPolicy()
+
+// newPolicy construye la lista blanca de HTML permitido en los blogs
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[a-z0-9-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[a-zA-Z0-9_+#-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math math-(inline|display)$`)).OnElements("span")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^bloque$`)).OnElements("div")
+	p.AllowAttrs("data-bloque").Matching(blockID).OnElements("div")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowAttrs("type", "checked", "disabled").OnElements("input")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render convierte Markdown en HTML sanitizado y calcula sus metadatos
+func Render(source string) (*Rendered, error) {
+	src := []byte(source)
+	ids := newHeadingIDs()
+	doc := markdown.Parser().Parse(text.NewReader(src), parser.WithContext(parser.NewContext(parser.WithIDs(ids))))
+
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+
+	words := countWords(doc, src)
+
+	return &Rendered{
+		HTML:            policy.Sanitize(buf.String()),
+		TablaContenidos: tableOfContents(doc, src),
+		Palabras:        words,
+		TiempoLectura:   ReadingTime(words),
+	}, nil
+}
+
+// PlainText devuelve el texto visible del Markdown, sin sintaxis ni HTML.
+// Los bloques se separan con saltos de línea.
+func PlainText(source string) string {
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+
+	var b strings.Builder
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock && b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Text:
+			b.Write(node.Value(src))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				b.Write(line.Value(src))
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(b.String())
+}
+
+// ReadingTime devuelve los minutos de lectura para un número de palabras, mínimo 1
+func ReadingTime(words int) int {
+	minutes := int(math.Ceil(float64(words) / WordsPerMinute))
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// tableOfContents recorre los encabezados del documento en orden
+func tableOfContents(doc ast.Node, src []byte) []types.TocEntry {
+	toc := []types.TocEntry{}
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		entry := types.TocEntry{
+			Nivel:  heading.Level,
+			Titulo: plainText(heading, src),
+		}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				entry.ID = string(b)
+			}
+		}
+		toc = append(toc, entry)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return toc
+}
+
+// countWords cuenta las palabras visibles, incluyendo bloques de código
+func countWords(doc ast.Node, src []byte) int {
+	words := 0
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Text:
+			words += len(strings.Fields(string(node.Value(src))))
+		case *ast.String:
+			words += len(strings.Fields(string(node.Value)))
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				words += len(strings.Fields(string(line.Value(src))))
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return words
+}
+
+// plainText concatena el texto de los hijos de un nodo
+func plainText(n ast.Node, src []byte) string {
+	var b strings.Builder
+
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := child.(type) {
+		case *ast.Text:
+			b.Write(node.Value(src))
+			if node.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(b.String())
+}
+
+// headingIDs genera ids de encabezado con el mismo slugificador de los blogs,
+// para que "## Introducción" enlace a #introduccion
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: map[string]bool{}}
+}
+
+func (h *headingIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	base := utils.GenerateSlug(string(value))
+	if base == "" {
+		base = "seccion"
+	}
+
+	id := base
+	for i := 1; h.used[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	h.used[id] = true
+
+	return []byte(id)
+}
+
+func (h *headingIDs) Put(value []byte) {
+	h.used[string(value)] = true
+}
+
+// KindMath es el tipo de nodo para expresiones matemáticas $...$ y $$...$$
+var KindMath = ast.NewNodeKind("Math")
+
+// Math es una expresión LaTeX que el frontend renderiza con KaTeX
+type Math struct {
+	ast.BaseInline
+	Display bool
+}
+
+func (n *Math) Kind() ast.NodeKind {
+	return KindMath
+}
+
+func (n *Math) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Display": strconv.FormatBool(n.Display)}, nil)
+}
+
+// mathParser reconoce $expr$ en línea y $$expr$$ en bloque
+type mathParser struct{}
+
+func (p mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p mathParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delim := 1
+	if len(line) > 1 && line[1] == '$' {
+		delim = 2
+	}
+
+	// "$ 5" o "$5 y $6" no son matemáticas: exigimos contenido pegado al delimitador
+	if len(line) <= delim || line[delim] == ' ' || line[delim] == '$' {
+		return nil
+	}
+
+	l, pos := block.Position()
+	block.Advance(delim)
+	node := &Math{Display: delim == 2}
+
+	for {
+		line, segment := block.PeekLine()
+		if line == nil {
+			block.SetPosition(l, pos)
+			return nil
+		}
+
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if line[i] != '$' {
+				continue
+			}
+			if delim == 2 && (i+1 >= len(line) || line[i+1] != '$') {
+				continue
+			}
+			if delim == 1 && (i == 0 || line[i-1] == ' ') {
+				block.SetPosition(l, pos)
+				return nil
+			}
+
+			if i > 0 {
+				node.AppendChild(node, ast.NewRawTextSegment(segment.WithStop(segment.Start+i)))
+			}
+			block.Advance(i + delim)
+			return node
+		}
+
+		node.AppendChild(node, ast.NewRawTextSegment(segment))
+		block.AdvanceLine()
+	}
+}
+
+// mathRenderer escribe las expresiones como texto escapado dentro de un span
+type mathRenderer struct{}
+
+func (r mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMath, r.renderMath)
+}
+
+func (r mathRenderer) renderMath(w util.BufWriter, src []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	class := "math math-inline"
+	if n.(*Math).Display {
+		class = "math math-display"
+	}
+
+	_, _ = w.WriteString(`<span class="` + class + `">`)
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		_, _ = w.Write(util.EscapeHTML(c.(*ast.Text).Value(src)))
+	}
+	_, _ = w.WriteString("</span>")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// mathExtension registra el parser y el renderer de matemáticas en goldmark
+type mathExtension struct{}
+
+func (e mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(mathParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(mathRenderer{}, 500)))
+}
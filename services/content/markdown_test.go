@@ -0,0 +1,191 @@
+package content
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestRender_HTML(t *testing.T) {
+	tests := []struct {
+		name       string
+		markdown   string
+		contains   []string
+		notContain []string
+	}{
+		{
+			name:     "Encabezados con id",
+			markdown: "## Introducción\n\nTexto",
+			contains: []string{`<h2 id="introduccion">Introducción</h2>`},
+		},
+		{
+			name:     "Bloque de código",
+			markdown: "```go\nfmt.Println(\"hola\")\n```",
+			contains: []string{`<code class="language-go">`, `fmt.Println(&#34;hola&#34;)`},
+		},
+		{
+			name:     "Tablas",
+			markdown: "| a | b |\n|---|--:|\n| 1 | 2 |",
+			contains: []string{"<table>", "<th>a</th>", `<td align="right">2</td>`},
+		},
+		{
+			name:     "Matemáticas en línea",
+			markdown: "La energía es $E = mc^2$.",
+			contains: []string{`<span class="math math-inline">E = mc^2</span>`},
+		},
+		{
+			name:     "Matemáticas en bloque",
+			markdown: "$$\\frac{a}{b} < 1$$",
+			contains: []string{`<span class="math math-display">\frac{a}{b} &lt; 1</span>`},
+		},
+		{
+			name:       "Precios no son matemáticas",
+			markdown:   "Cuesta $5 y $6 pesos",
+			contains:   []string{"Cuesta $5 y $6 pesos"},
+			notContain: []string{"math"},
+		},
+		{
+			name:       "Script eliminado",
+			markdown:   "Hola <script>alert(1)</script>",
+			notContain: []string{"<script", "alert(1)</script>"},
+		},
+		{
+			name:       "Enlaces javascript eliminados",
+			markdown:   "[clic](javascript:alert(1))",
+			notContain: []string{"javascript:"},
+		},
+		{
+			name:       "Atributos de eventos eliminados",
+			markdown:   `<img src="x.png" onerror="alert(1)">`,
+			notContain: []string{"onerror"},
+		},
+		{
+			name:     "Enlaces externos con nofollow",
+			markdown: "[Pardalis](https://pardalis.mx)",
+			contains: []string{`rel="nofollow noopener"`, `target="_blank"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.markdown)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(got.HTML, want) {
+					t.Errorf("Render() HTML = %q, want it to contain %q", got.HTML, want)
+				}
+			}
+			for _, unwanted := range tt.notContain {
+				if strings.Contains(got.HTML, unwanted) {
+					t.Errorf("Render() HTML = %q, must not contain %q", got.HTML, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_TablaContenidos(t *testing.T) {
+	md := "# Curso de Go\n\n## Variables\n\ntexto\n\n### Tipos *básicos*\n\n## Variables\n"
+
+	got, err := Render(md)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := []types.TocEntry{
+		{Nivel: 1, Titulo: "Curso de Go", ID: "curso-de-go"},
+		{Nivel: 2, Titulo: "Variables", ID: "variables"},
+		{Nivel: 3, Titulo: "Tipos básicos", ID: "tipos-basicos"},
+		{Nivel: 2, Titulo: "Variables", ID: "variables-1"},
+	}
+
+	if !reflect.DeepEqual(got.TablaContenidos, want) {
+		t.Errorf("Render() TablaContenidos = %+v, want %+v", got.TablaContenidos, want)
+	}
+}
+
+func TestRender_TiempoLectura(t *testing.T) {
+	tests := []struct {
+		name         string
+		markdown     string
+		wantPalabras int
+		wantMinutos  int
+	}{
+		{
+			name:         "Vacío",
+			markdown:     "",
+			wantPalabras: 0,
+			wantMinutos:  1,
+		},
+		{
+			name:         "Texto corto",
+			markdown:     "Hola **mundo** cruel",
+			wantPalabras: 3,
+			wantMinutos:  1,
+		},
+		{
+			name:         "Cuenta el código",
+			markdown:     "uno dos\n\n```\ntres cuatro\n```",
+			wantPalabras: 4,
+			wantMinutos:  1,
+		},
+		{
+			name:         "Texto largo",
+			markdown:     strings.Repeat("palabra ", 450),
+			wantPalabras: 450,
+			wantMinutos:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.markdown)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got.Palabras != tt.wantPalabras {
+				t.Errorf("Render() Palabras = %d, want %d", got.Palabras, tt.wantPalabras)
+			}
+			if got.TiempoLectura != tt.wantMinutos {
+				t.Errorf("Render() TiempoLectura = %d, want %d", got.TiempoLectura, tt.wantMinutos)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "Énfasis y enlaces",
+			markdown: "Hola **mundo** y [Pardalis](https://pardalis.mx)",
+			want:     "Hola mundo y Pardalis",
+		},
+		{
+			name:     "Bloques separados",
+			markdown: "# Título\n\nPárrafo uno\n\n- item",
+			want:     "Título\nPárrafo uno\nitem",
+		},
+		{
+			name:     "HTML descartado",
+			markdown: "Texto <b>negrita</b>",
+			want:     "Texto negrita",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.markdown); got != tt.want {
+				t.Errorf("PlainText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// Package feed publica los blogs como RSS 2.0, Atom 1.0 y JSON Feed 1.1
+// para que profesores y portales escolares puedan suscribirse.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Feed es la representación común a partir de la que se generan los tres formatos
+type Feed struct {
+	Titulo      string
+	Descripcion string
+	Link        string // Página HTML del listado
+	SelfLink    string // URL del propio feed
+	Actualizado time.Time
+	Items       []types.Blog
+}
+
+// Updated devuelve la fecha de actualización más reciente entre los blogs
+func Updated(blogs []types.Blog) time.Time {
+	var updated time.Time
+	for _, blog := range blogs {
+		t := lastModified(blog)
+		if t.After(updated) {
+			updated = t
+		}
+	}
+	return updated.UTC()
+}
+
+// lastModified usa fecha_actualizacion y cae en fecha_publicacion para filas antiguas
+func lastModified(blog types.Blog) time.Time {
+	if blog.FechaActualizacion.After(blog.FechaPublicacion) {
+		return blog.FechaActualizacion
+	}
+	return blog.FechaPublicacion
+}
+
+func categories(blog types.Blog) []string {
+	cats := []string{}
+	if blog.Categoria != "" {
+		cats = append(cats, blog.Categoria)
+	}
+	return append(cats, blog.Tags...)
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description"`
+	Author      string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS genera un documento RSS 2.0
+func RSS(f Feed, host string) ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Titulo,
+			Link:        f.Link,
+			Description: f.Descripcion,
+			Language:    "es",
+			AtomLink:    atomLink{Href: f.SelfLink, Rel: "self", Type: "application/rss+xml"},
+			Items:       []rssItem{},
+		},
+	}
+	if !f.Actualizado.IsZero() {
+		doc.Channel.LastBuildDate = f.Actualizado.Format(time.RFC1123Z)
+	}
+
+	for _, blog := range f.Items {
+		link := utils.BlogURL(host, blog.Slug)
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       blog.Titulo,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			Description: blog.Extracto,
+			Author:      blog.AutorApodo,
+			Categories:  categories(blog),
+			PubDate:     blog.FechaPublicacion.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang    string      `xml:"xml:lang,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Summary    string         `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Atom genera un documento Atom 1.0
+func Atom(f Feed, host string) ([]byte, error) {
+	doc := atomFeed{
+		Lang:    "es",
+		ID:      f.SelfLink,
+		Title:   f.Titulo,
+		Updated: f.Actualizado.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.SelfLink, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: []atomEntry{},
+	}
+
+	for _, blog := range f.Items {
+		link := utils.BlogURL(host, blog.Slug)
+		entry := atomEntry{
+			ID:        link,
+			Title:     blog.Titulo,
+			Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: blog.FechaPublicacion.UTC().Format(time.RFC3339),
+			Updated:   lastModified(blog).UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: blog.AutorApodo},
+			Summary:   blog.Extracto,
+		}
+		for _, c := range categories(blog) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	Summary       string           `json:"summary,omitempty"`
+	ContentText   string           `json:"content_text"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// JSONFeed genera un documento JSON Feed 1.1
+func JSONFeed(f Feed, host string) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Titulo,
+		HomePageURL: f.Link,
+		FeedURL:     f.SelfLink,
+		Description: f.Descripcion,
+		Language:    "es",
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, blog := range f.Items {
+		link := utils.BlogURL(host, blog.Slug)
+		doc.Items = append(doc.Items, jsonFeedItem{
+			ID:            link,
+			URL:           link,
+			Title:         blog.Titulo,
+			Summary:       blog.Extracto,
+			ContentText:   blog.Extracto,
+			Image:         blog.ImagenPortada,
+			DatePublished: blog.FechaPublicacion.UTC().Format(time.RFC3339),
+			DateModified:  lastModified(blog).UTC().Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{{Name: blog.AutorApodo}},
+			Tags:          categories(blog),
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
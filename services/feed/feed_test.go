@@ -0,0 +1,141 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func testFeed() Feed {
+	published := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+	items := []types.Blog{
+		{
+			Titulo: "Introducción a Go", Slug: "introduccion-a-go", Extracto: "Primeros pasos & más",
+			Categoria: "Programación", Tags: []string{"go"}, AutorApodo: "profe",
+			FechaPublicacion: published, FechaActualizacion: published.Add(48 * time.Hour),
+		},
+		{
+			Titulo: "Verbos irregulares", Slug: "verbos-irregulares", Extracto: "Lista de verbos",
+			Categoria: "Inglés", AutorApodo: "teacher",
+			FechaPublicacion: published.Add(-24 * time.Hour),
+		},
+	}
+
+	return Feed{
+		Titulo:      "Pardalis Blog",
+		Link:        "https://pardalis.mx/blogs",
+		SelfLink:    "https://pardalis.mx/api/v1/feeds/rss.xml",
+		Actualizado: Updated(items),
+		Items:       items,
+	}
+}
+
+func TestUpdated(t *testing.T) {
+	f := testFeed()
+	want := time.Date(2024, 9, 3, 10, 0, 0, 0, time.UTC)
+	if !f.Actualizado.Equal(want) {
+		t.Errorf("Updated() = %v, want %v", f.Actualizado, want)
+	}
+	if !Updated(nil).IsZero() {
+		t.Errorf("Updated(nil) = %v, want zero", Updated(nil))
+	}
+}
+
+func TestRSS(t *testing.T) {
+	body, err := RSS(testFeed(), "https://pardalis.mx/")
+	if err != nil {
+		t.Fatalf("RSS() error = %v", err)
+	}
+
+	var doc struct {
+		Channel struct {
+			Items []struct {
+				Link       string   `xml:"link"`
+				Categories []string `xml:"category"`
+				PubDate    string   `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("RSS() produced invalid XML: %v", err)
+	}
+
+	if len(doc.Channel.Items) != 2 {
+		t.Fatalf("RSS() items = %d, want 2", len(doc.Channel.Items))
+	}
+	if got := doc.Channel.Items[0].Link; got != "https://pardalis.mx/blogs/introduccion-a-go" {
+		t.Errorf("RSS() link = %q", got)
+	}
+	if got := doc.Channel.Items[0].PubDate; got != "Sun, 01 Sep 2024 10:00:00 +0000" {
+		t.Errorf("RSS() pubDate = %q", got)
+	}
+	if got := strings.Join(doc.Channel.Items[0].Categories, ","); got != "Programación,go" {
+		t.Errorf("RSS() categories = %q", got)
+	}
+	if !strings.Contains(string(body), "Primeros pasos &amp; más") {
+		t.Errorf("RSS() must escape XML entities")
+	}
+}
+
+func TestAtom(t *testing.T) {
+	body, err := Atom(testFeed(), "https://pardalis.mx")
+	if err != nil {
+		t.Fatalf("Atom() error = %v", err)
+	}
+
+	var doc struct {
+		Updated string `xml:"updated"`
+		Entries []struct {
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("Atom() produced invalid XML: %v", err)
+	}
+
+	if doc.Updated != "2024-09-03T10:00:00Z" {
+		t.Errorf("Atom() feed updated = %q", doc.Updated)
+	}
+	if doc.Entries[0].Published != "2024-09-01T10:00:00Z" || doc.Entries[0].Updated != "2024-09-03T10:00:00Z" {
+		t.Errorf("Atom() entry dates = %+v", doc.Entries[0])
+	}
+	// Una entrada sin fecha_actualizacion usa su fecha de publicación
+	if doc.Entries[1].Updated != "2024-08-31T10:00:00Z" {
+		t.Errorf("Atom() entry updated fallback = %q", doc.Entries[1].Updated)
+	}
+}
+
+func TestJSONFeed(t *testing.T) {
+	body, err := JSONFeed(testFeed(), "https://pardalis.mx")
+	if err != nil {
+		t.Fatalf("JSONFeed() error = %v", err)
+	}
+
+	var doc struct {
+		Version string `json:"version"`
+		Items   []struct {
+			URL     string `json:"url"`
+			Authors []struct {
+				Name string `json:"name"`
+			} `json:"authors"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("JSONFeed() produced invalid JSON: %v", err)
+	}
+
+	if doc.Version != "https://jsonfeed.org/version/1.1" {
+		t.Errorf("JSONFeed() version = %q", doc.Version)
+	}
+	if len(doc.Items) != 2 || doc.Items[1].URL != "https://pardalis.mx/blogs/verbos-irregulares" {
+		t.Errorf("JSONFeed() items = %+v", doc.Items)
+	}
+	if doc.Items[0].Authors[0].Name != "profe" {
+		t.Errorf("JSONFeed() author = %+v", doc.Items[0].Authors)
+	}
+}
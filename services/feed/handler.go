@@ -0,0 +1,115 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// feedSize es el número de entradas de cada feed
+const feedSize = 50
+
+// formats asocia cada nombre de archivo con su generador y tipo de contenido
+var formats = map[string]struct {
+	contentType string
+	build       func(Feed, string) ([]byte, error)
+}{
+	"rss.xml":   {contentType: "application/rss+xml; charset=utf-8", build: RSS},
+	"atom.xml":  {contentType: "application/atom+xml; charset=utf-8", build: Atom},
+	"feed.json": {contentType: "application/feed+json; charset=utf-8", build: JSONFeed},
+}
+
+// Handler maneja las rutas de los feeds
+type Handler struct {
+	store types.BlogStore
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.BlogStore) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	format := "{format:rss\\.xml|atom\\.xml|feed\\.json}"
+	router.HandleFunc("/feeds/"+format, h.handleFeed).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/feeds/categorias/{categoria}/"+format, h.handleFeed).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/feeds/tags/{tag}/"+format, h.handleFeed).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/feeds/autores/{apodo}/"+format, h.handleFeed).Methods(http.MethodGet, http.MethodHead)
+}
+
+// handleFeed genera el feed general o una de sus variantes según las variables de la ruta
+func (h *Handler) handleFeed(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	format := formats[vars["format"]]
+	host := strings.TrimRight(configs.Envs.PublicHost, "/")
+
+	f := Feed{
+		Titulo:      "Pardalis Blog",
+		Descripcion: "Publicaciones de la comunidad educativa de Pardalis",
+		Link:        host + "/blogs",
+		SelfLink:    host + r.URL.Path,
+	}
+
+	var (
+		items []types.Blog
+		err   error
+	)
+	switch {
+	case vars["categoria"] != "":
+		categoria := utils.GenerateSlug(vars["categoria"])
+		f.Link = host + "/blogs?categoria=" + url.QueryEscape(categoria)
+		items, err = h.store.GetBlogs(types.BlogQuery{Categoria: categoria, Limit: feedSize})
+		f.Titulo = fmt.Sprintf("Pardalis Blog – %s", vars["categoria"])
+		if len(items) > 0 {
+			f.Titulo = fmt.Sprintf("Pardalis Blog – %s", items[0].Categoria)
+		}
+	case vars["tag"] != "":
+		tag := vars["tag"]
+		f.Titulo = fmt.Sprintf("Pardalis Blog – #%s", tag)
+		f.Link = host + "/blogs?tag=" + url.QueryEscape(tag)
+		items, err = h.store.GetBlogs(types.BlogQuery{Tags: []string{tag}, Limit: feedSize})
+	case vars["apodo"] != "":
+		apodo := vars["apodo"]
+		f.Titulo = fmt.Sprintf("Pardalis Blog – %s", apodo)
+		f.Link = host + "/users/" + url.PathEscape(apodo)
+		items, err = h.store.GetBlogs(types.BlogQuery{Autor: apodo, Limit: feedSize})
+	default:
+		items, err = h.store.GetBlogs(types.BlogQuery{Limit: feedSize})
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	f.Items = items
+	f.Actualizado = Updated(items)
+	if f.Actualizado.IsZero() {
+		// Un feed vacío sigue necesitando una fecha estable para las peticiones condicionales
+		f.Actualizado = time.Unix(0, 0).UTC()
+	}
+
+	body, err := format.build(f, host)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if utils.CheckNotModified(w, r, utils.ETag(body), f.Actualizado) {
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
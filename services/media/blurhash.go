@@ -0,0 +1,109 @@
+package media
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+)
+
+// Componentes del blurhash: 4×3 bastan para un degradado reconocible
+const (
+	blurhashX = 4
+	blurhashY = 3
+)
+
+const base83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// blurhash codifica img según https://blurha.sh. Conviene pasar una imagen
+// pequeña: el coste crece con el número de píxeles. Devuelve también el color
+// medio en formato #rrggbb, que es la componente constante del hash.
+func blurhash(img *image.RGBA) (string, string) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	var factors [blurhashX * blurhashY][3]float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			o := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			r := srgbToLinear(img.Pix[o])
+			g := srgbToLinear(img.Pix[o+1])
+			bl := srgbToLinear(img.Pix[o+2])
+			for j := 0; j < blurhashY; j++ {
+				for i := 0; i < blurhashX; i++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(w)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(h))
+					f := &factors[j*blurhashX+i]
+					f[0] += basis * r
+					f[1] += basis * g
+					f[2] += basis * bl
+				}
+			}
+		}
+	}
+	for k := range factors {
+		normalisation := 2.0
+		if k == 0 {
+			normalisation = 1
+		}
+		for ch := range factors[k] {
+			factors[k][ch] *= normalisation / float64(w*h)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(encode83((blurhashX-1)+(blurhashY-1)*9, 1))
+
+	maximum := 0.0
+	for _, f := range factors[1:] {
+		for _, v := range f {
+			maximum = math.Max(maximum, math.Abs(v))
+		}
+	}
+	quantisedMax := int(math.Max(0, math.Min(82, math.Floor(maximum*166-0.5))))
+	maxValue := float64(quantisedMax+1) / 166
+	sb.WriteString(encode83(quantisedMax, 1))
+
+	dc := factors[0]
+	r, g, bl := linearToSRGB(dc[0]), linearToSRGB(dc[1]), linearToSRGB(dc[2])
+	sb.WriteString(encode83(r<<16|g<<8|bl, 4))
+
+	for _, f := range factors[1:] {
+		var q [3]int
+		for ch, v := range f {
+			q[ch] = int(math.Max(0, math.Min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		sb.WriteString(encode83(q[0]*19*19+q[1]*19+q[2], 2))
+	}
+
+	return sb.String(), fmt.Sprintf("#%02x%02x%02x", r, g, bl)
+}
+
+func encode83(value int, length int) string {
+	out := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		out[i] = base83[value%83]
+		value /= 83
+	}
+	return string(out)
+}
+
+func srgbToLinear(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	c := math.Max(0, math.Min(1, v))
+	if c <= 0.0031308 {
+		return int(c*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(c, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v float64, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
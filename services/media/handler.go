@@ -0,0 +1,263 @@
+package media
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/services/collaborator"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Tamaño máximo de cada tipo de subida
+const (
+	maxPortadaSize = 8 << 20
+	maxAvatarSize  = 2 << 20
+)
+
+// formField es el campo multipart que lleva el archivo
+const formField = "archivo"
+
+// directories es el prefijo de las claves de cada propósito
+var directories = map[string]string{
+	types.MediaPortada: "portadas",
+	types.MediaAvatar:  "avatares",
+}
+
+// Handler maneja la subida y la descarga de imágenes
+type Handler struct {
+	store                types.MediaStore
+	storage              types.BlobStorage
+	processor            *Processor
+	blogStore            types.BlogStore
+	personalizationStore types.PersonalizationStore
+	userStore            types.UserStore
+	authorizer           *collaborator.Authorizer
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.MediaStore, storage types.BlobStorage, processor *Processor, blogStore types.BlogStore, personalizationStore types.PersonalizationStore, userStore types.UserStore, authorizer *collaborator.Authorizer) *Handler {
+	return &Handler{
+		store:                store,
+		storage:              storage,
+		processor:            processor,
+		blogStore:            blogStore,
+		personalizationStore: personalizationStore,
+		userStore:            userStore,
+		authorizer:           authorizer,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/blogs/{id}/portada", auth.WithJWTAuth(h.handleUploadPortada, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/users/{userApodo}/personalization/foto", auth.WithJWTAuth(h.handleUploadAvatar, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/media/{clave:.+}", h.handleGetMedia).Methods(http.MethodGet)
+}
+
+// handleUploadPortada sube la imagen de portada de un blog que el usuario puede
+// editar y la asigna
+func (h *Handler) handleUploadPortada(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.authorizer.Authorize(w, r, mux.Vars(r)["id"], types.PermisoEditar)
+	if !ok {
+		return
+	}
+
+	media, ok := h.upload(w, r, types.MediaPortada, maxPortadaSize)
+	if !ok {
+		return
+	}
+
+	if err := h.setPortada(blog, media.URL); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusCreated, media)
+	if err != nil {
+		return
+	}
+}
+
+// handleUploadAvatar sube la foto de perfil del usuario autenticado y la asigna
+func (h *Handler) handleUploadAvatar(w http.ResponseWriter, r *http.Request) {
+	apodo := auth.GetUserApodoFromContext(r.Context())
+	if apodo != mux.Vars(r)["userApodo"] {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("unauthorized access"))
+		return
+	}
+
+	media, ok := h.upload(w, r, types.MediaAvatar, maxAvatarSize)
+	if !ok {
+		return
+	}
+
+	p, err := h.personalizationStore.GetPersonalization(apodo)
+	switch {
+	case err != nil && err.Error() == "personalization not found":
+		err = h.personalizationStore.CreatePersonalization(types.Personalization{Apodo: apodo, Foto: media.URL})
+	case err == nil:
+		err = h.setFoto(p, media.URL)
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusCreated, media)
+	if err != nil {
+		return
+	}
+}
+
+// Veces que se reintenta asignar una imagen si otra petición cambia el blog o
+// la personalización a la vez. Solo cambia un campo, así que reintentar con la
+// versión nueva no pisa nada.
+const maxAssignAttempts = 3
+
+// setPortada asigna la portada a blog, releyéndolo si cambió mientras se subía
+func (h *Handler) setPortada(blog *types.Blog, url string) error {
+	for attempt := 1; ; attempt++ {
+		blog.ImagenPortada = url
+		err := h.blogStore.UpdateBlog(*blog)
+		if err == nil || err.Error() != "version conflict" || attempt == maxAssignAttempts {
+			return err
+		}
+
+		blog, err = h.blogStore.GetBlogByID(blog.ID)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// setFoto asigna la foto de perfil, releyendo la personalización si cambió
+// mientras se subía
+func (h *Handler) setFoto(p *types.Personalization, url string) error {
+	for attempt := 1; ; attempt++ {
+		p.Foto = url
+		err := h.personalizationStore.UpdatePersonalization(*p)
+		if err == nil || err.Error() != "version conflict" || attempt == maxAssignAttempts {
+			return err
+		}
+
+		p, err = h.personalizationStore.GetPersonalization(p.Apodo)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleGetMedia sirve un archivo del almacenamiento. Con S3 las URLs públicas
+// apuntan al bucket y esta ruta solo hace de respaldo.
+func (h *Handler) handleGetMedia(w http.ResponseWriter, r *http.Request) {
+	clave := mux.Vars(r)["clave"]
+	if !validKey.MatchString(clave) {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("media not found"))
+		return
+	}
+
+	body, err := h.storage.Get(r.Context(), clave)
+	if err != nil {
+		if err.Error() == "blob not found" {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("media not found"))
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer func(body io.ReadCloser) {
+		err := body.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(body)
+
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(clave)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("Error al servir %s: %v", clave, err)
+	}
+}
+
+// upload lee el archivo multipart de la petición, lo valida, le quita los
+// metadatos y lo guarda. Si algo falla responde con el error y devuelve false.
+func (h *Handler) upload(w http.ResponseWriter, r *http.Request, proposito string, maxSize int64) (*types.Media, bool) {
+	// El margen cubre las cabeceras multipart; el límite real se comprueba abajo
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+
+	file, _, err := r.FormFile(formField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.WriteError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("file must be at most %d MB", maxSize>>20))
+			return nil, false
+		}
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing %s file", formField))
+		return nil, false
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+	if int64(len(data)) > maxSize {
+		utils.WriteError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("file must be at most %d MB", maxSize>>20))
+		return nil, false
+	}
+
+	img, err := processImage(data)
+	if err != nil {
+		if err.Error() == "unsupported image type" {
+			utils.WriteError(w, http.StatusUnsupportedMediaType, fmt.Errorf("only jpeg, png, gif and webp images are allowed"))
+			return nil, false
+		}
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	sum := sha256.Sum256(img.data)
+	hash := hex.EncodeToString(sum[:])
+	clave := fmt.Sprintf("%s/%s/%s.%s", directories[proposito], hash[:2], hash, img.ext)
+
+	err = h.storage.Put(r.Context(), clave, bytes.NewReader(img.data), int64(len(img.data)), img.tipo)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	media := &types.Media{
+		ID:            uuid.New().String(),
+		Clave:         clave,
+		URL:           h.storage.URL(clave),
+		Tipo:          img.tipo,
+		Tamano:        int64(len(img.data)),
+		Ancho:         img.ancho,
+		Alto:          img.alto,
+		Proposito:     proposito,
+		AutorApodo:    auth.GetUserApodoFromContext(r.Context()),
+		FechaCreacion: time.Now(),
+	}
+	if err := h.store.CreateMedia(*media); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	h.processor.Enqueue(*media)
+
+	return media, true
+}
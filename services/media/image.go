@@ -0,0 +1,325 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"  // registra el decodificador para image.DecodeConfig
+	_ "image/jpeg" // registra el decodificador para image.DecodeConfig
+	_ "image/png"  // registra el decodificador para image.DecodeConfig
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// maxPixels limita el tamaño de una imagen ya decodificada: un PNG de pocos
+// kilobytes puede declarar dimensiones de varios gigapíxeles
+const maxPixels = 40_000_000
+
+// extensions son los tipos admitidos y la extensión con la que se guardan
+var extensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// upload es una imagen validada y sin metadatos, lista para guardar
+type upload struct {
+	data  []byte
+	tipo  string
+	ext   string
+	ancho int
+	alto  int
+}
+
+// processImage comprueba el tipo real de data leyendo su contenido, no la
+// extensión ni la cabecera que envía el cliente, y quita sus metadatos
+func processImage(data []byte) (*upload, error) {
+	tipo := mimetype.Detect(data).String()
+	ext, ok := extensions[tipo]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image type")
+	}
+
+	ancho, alto, err := dimensions(data, tipo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image")
+	}
+	if tipo == "image/jpeg" && jpegOrientation(data) >= 5 {
+		// La imagen se muestra girada 90°
+		ancho, alto = alto, ancho
+	}
+	if ancho < 1 || alto < 1 || ancho*alto > maxPixels {
+		return nil, fmt.Errorf("image dimensions are too large")
+	}
+
+	stripped, err := stripMetadata(data, tipo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image")
+	}
+
+	return &upload{data: stripped, tipo: tipo, ext: ext, ancho: ancho, alto: alto}, nil
+}
+
+func dimensions(data []byte, tipo string) (int, int, error) {
+	if tipo == "image/webp" {
+		return webpDimensions(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// stripMetadata quita EXIF, XMP, IPTC y comentarios sin recodificar la imagen,
+// así que no pierde calidad. Los GIF no llevan EXIF y se guardan tal cual.
+func stripMetadata(data []byte, tipo string) ([]byte, error) {
+	switch tipo {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	default:
+		return data, nil
+	}
+}
+
+// stripJPEG quita los segmentos APP1 (EXIF y XMP), APP13 (IPTC) y COM. Se
+// conservan JFIF, el perfil ICC y Adobe, que afectan a los colores. Si el EXIF
+// giraba la imagen, se sustituye por uno que solo contiene la orientación.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, fmt.Errorf("invalid jpeg")
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+
+	orientation := uint16(1)
+	insertAt := len(out)
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, fmt.Errorf("invalid jpeg")
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Relleno entre segmentos
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			// A partir del inicio del escaneo solo hay datos de imagen
+			out = append(out, data[i:]...)
+			if orientation != 1 {
+				// El EXIF va justo después de SOI o de JFIF, donde lo buscan los lectores
+				out = append(out[:insertAt], append(exifOrientation(orientation), out[insertAt:]...)...)
+			}
+			return out, nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, fmt.Errorf("invalid jpeg")
+		}
+		segment := data[i:end]
+		i = end
+
+		switch {
+		case marker == 0xE1:
+			if o := readOrientation(segment[4:]); o != 0 {
+				orientation = o
+			}
+		case marker == 0xED, marker == 0xFE:
+		case marker == 0xE0 && len(out) == 2:
+			out = append(out, segment...)
+			insertAt = len(out)
+		default:
+			out = append(out, segment...)
+		}
+	}
+}
+
+// readOrientation lee la etiqueta de orientación del IFD0 de un segmento EXIF.
+// Devuelve 0 si el segmento no es EXIF o no la contiene.
+func readOrientation(payload []byte) uint16 {
+	if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+		return 0
+	}
+	tiff := payload[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < count; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := order.Uint16(tiff[entry+8:]); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// exifOrientation construye un segmento APP1 cuyo único dato es la orientación
+func exifOrientation(orientation uint16) []byte {
+	segment := []byte{
+		0xFF, 0xE1, 0x00, 0x22,
+		'E', 'x', 'i', 'f', 0x00, 0x00,
+		// Cabecera TIFF big-endian con el IFD0 justo detrás
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		// Una entrada: Orientation, SHORT, 1 valor
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+		// Sin más IFD
+		0x00, 0x00, 0x00, 0x00,
+	}
+	binary.BigEndian.PutUint16(segment[28:], orientation)
+	return segment
+}
+
+// pngMetadata son los chunks de PNG que no afectan a cómo se ve la imagen
+var pngMetadata = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, fmt.Errorf("invalid png")
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, signature...)
+	for i := len(signature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, fmt.Errorf("invalid png")
+		}
+		// Longitud, tipo, datos y CRC
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, fmt.Errorf("invalid png")
+		}
+		if !pngMetadata[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// Banderas de la cabecera VP8X que anuncian chunks de metadatos
+const (
+	vp8xEXIF = 0x08
+	vp8xXMP  = 0x04
+)
+
+// stripWebP quita los chunks EXIF y XMP del contenedor RIFF y sus banderas de VP8X
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("invalid webp")
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	err := webpChunks(data, func(fourCC string, chunk []byte) {
+		switch fourCC {
+		case "EXIF", "XMP ":
+			return
+		case "VP8X":
+			start := len(out)
+			out = append(out, chunk...)
+			if len(chunk) > 8 {
+				out[start+8] &^= vp8xEXIF | vp8xXMP
+			}
+			return
+		}
+		out = append(out, chunk...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// webpDimensions lee el ancho y el alto del primer chunk de imagen de un WebP
+func webpDimensions(data []byte) (int, int, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, fmt.Errorf("invalid webp")
+	}
+
+	ancho, alto := 0, 0
+	err := webpChunks(data, func(fourCC string, chunk []byte) {
+		if ancho > 0 {
+			return
+		}
+		payload := chunk[8:]
+		switch {
+		case fourCC == "VP8X" && len(payload) >= 10:
+			ancho = 1 + int(uint32(payload[4])|uint32(payload[5])<<8|uint32(payload[6])<<16)
+			alto = 1 + int(uint32(payload[7])|uint32(payload[8])<<8|uint32(payload[9])<<16)
+		case fourCC == "VP8 " && len(payload) >= 10:
+			ancho = int(binary.LittleEndian.Uint16(payload[6:]) & 0x3FFF)
+			alto = int(binary.LittleEndian.Uint16(payload[8:]) & 0x3FFF)
+		case fourCC == "VP8L" && len(payload) >= 5 && payload[0] == 0x2F:
+			bits := binary.LittleEndian.Uint32(payload[1:])
+			ancho = 1 + int(bits&0x3FFF)
+			alto = 1 + int(bits>>14&0x3FFF)
+		}
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if ancho == 0 {
+		return 0, 0, fmt.Errorf("invalid webp")
+	}
+	return ancho, alto, nil
+}
+
+// webpChunks recorre los chunks de un WebP. Cada chunk incluye su cabecera y
+// el byte de relleno si su tamaño es impar.
+func webpChunks(data []byte, fn func(fourCC string, chunk []byte)) error {
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return fmt.Errorf("invalid webp")
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(data) || end < i {
+			return fmt.Errorf("invalid webp")
+		}
+		fn(string(data[i:i+4]), data[i:end])
+		i = end
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	return img
+}
+
+// jpegSegment construye un segmento JPEG con su marcador y su longitud
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// exifPayload construye un EXIF little-endian con la orientación y una etiqueta GPS
+func exifPayload(orientation uint16) []byte {
+	p := []byte("Exif\x00\x00II\x2A\x00\x08\x00\x00\x00\x02\x00")
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	p = append(p, entry...)
+	gps := make([]byte, 12)
+	binary.LittleEndian.PutUint16(gps[0:], 0x8825)
+	p = append(p, gps...)
+	return append(p, []byte("\x00\x00\x00\x00GPS-SECRETO")...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+
+	// SOI, EXIF, comentario y el resto del JPEG original
+	var data []byte
+	data = append(data, plain[:2]...)
+	data = append(data, jpegSegment(0xE1, exifPayload(6))...)
+	data = append(data, jpegSegment(0xFE, []byte("GPS-SECRETO"))...)
+	data = append(data, plain[2:]...)
+
+	got, err := stripJPEG(data)
+	if err != nil {
+		t.Fatalf("stripJPEG() error = %v", err)
+	}
+	if bytes.Contains(got, []byte("GPS-SECRETO")) {
+		t.Errorf("stripJPEG() kept metadata")
+	}
+	if o := readOrientation(got[6:]); o != 6 {
+		t.Errorf("stripJPEG() orientation = %d, want 6", o)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(got)); err != nil {
+		t.Errorf("stripJPEG() produced an invalid jpeg: %v", err)
+	}
+
+	// Sin orientación no queda ningún EXIF
+	data = append(append([]byte{}, plain[:2]...), jpegSegment(0xE1, exifPayload(1))...)
+	data = append(data, plain[2:]...)
+	got, err = stripJPEG(data)
+	if err != nil {
+		t.Fatalf("stripJPEG() error = %v", err)
+	}
+	if bytes.Contains(got, []byte("Exif")) {
+		t.Errorf("stripJPEG() kept an EXIF segment with orientation 1")
+	}
+
+	if _, err := stripJPEG(plain[:20]); err == nil {
+		t.Errorf("stripJPEG() accepted a truncated jpeg")
+	}
+}
+
+func pngChunk(kind string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], kind)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+
+	// Firma, IHDR (8 + 25 bytes), metadatos y el resto
+	var data []byte
+	data = append(data, plain[:33]...)
+	data = append(data, pngChunk("tEXt", []byte("Author\x00GPS-SECRETO"))...)
+	data = append(data, pngChunk("eXIf", exifPayload(1)[6:])...)
+	data = append(data, plain[33:]...)
+
+	got, err := stripPNG(data)
+	if err != nil {
+		t.Fatalf("stripPNG() error = %v", err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Errorf("stripPNG() = %d bytes, want the original %d bytes", len(got), len(plain))
+	}
+}
+
+// webpChunk construye un chunk RIFF con relleno si hace falta
+func webpChunk(fourCC string, data []byte) []byte {
+	chunk := append([]byte(fourCC), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, c := range chunks {
+		data = append(data, c...)
+	}
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	return data
+}
+
+func TestStripWebP(t *testing.T) {
+	// VP8X de 640x480 con las banderas de EXIF y XMP
+	vp8x := []byte{vp8xEXIF | vp8xXMP, 0, 0, 0, 0x7F, 0x02, 0x00, 0xDF, 0x01, 0x00}
+	// Imagen sin pérdida de 640x480: firma, ancho-1 y alto-1 en 14 bits
+	bits := uint32(639) | uint32(479)<<14
+	vp8l := binary.LittleEndian.AppendUint32([]byte{0x2F}, bits)
+
+	data := webpFile(
+		webpChunk("VP8X", vp8x),
+		webpChunk("VP8L", vp8l),
+		webpChunk("EXIF", []byte("GPS-SECRETO")),
+		webpChunk("XMP ", []byte("<x:xmpmeta/>")),
+	)
+
+	ancho, alto, err := webpDimensions(data)
+	if err != nil || ancho != 640 || alto != 480 {
+		t.Fatalf("webpDimensions() = %d, %d, %v, want 640, 480", ancho, alto, err)
+	}
+
+	got, err := stripWebP(data)
+	if err != nil {
+		t.Fatalf("stripWebP() error = %v", err)
+	}
+	vp8x[0] = 0
+	if want := webpFile(webpChunk("VP8X", vp8x), webpChunk("VP8L", vp8l)); !bytes.Equal(got, want) {
+		t.Errorf("stripWebP() = %q, want %q", got, want)
+	}
+
+	if ancho, alto, _ := webpDimensions(webpFile(webpChunk("VP8L", vp8l))); ancho != 640 || alto != 480 {
+		t.Errorf("webpDimensions() of a simple webp = %d, %d, want 640, 480", ancho, alto)
+	}
+}
+
+func TestProcessImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := processImage(buf.Bytes())
+	if err != nil {
+		t.Fatalf("processImage() error = %v", err)
+	}
+	if img.tipo != "image/png" || img.ext != "png" || img.ancho != 4 || img.alto != 3 {
+		t.Errorf("processImage() = %s %s %dx%d", img.tipo, img.ext, img.ancho, img.alto)
+	}
+
+	// El tipo sale del contenido: un HTML con nombre .png no pasa
+	if _, err := processImage([]byte("<html><script>alert(1)</script></html>")); err == nil || err.Error() != "unsupported image type" {
+		t.Errorf("processImage(html) error = %v, want unsupported image type", err)
+	}
+
+	// Una cabecera PNG que declara 100000x100000 píxeles
+	huge := append([]byte{}, buf.Bytes()[:33]...)
+	binary.BigEndian.PutUint32(huge[16:], 100000)
+	binary.BigEndian.PutUint32(huge[20:], 100000)
+	if _, err := processImage(huge); err == nil {
+		t.Errorf("processImage() accepted a decompression bomb")
+	}
+}
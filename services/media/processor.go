@@ -0,0 +1,110 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"log"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Tamaño de la cola de imágenes por procesar y de cada tanda de pendientes al arrancar
+const (
+	processorQueue = 100
+	pendingBatch   = 100
+)
+
+// Processor genera las variantes de las imágenes subidas en segundo plano, de
+// una en una, para que las subidas no esperen ni compitan por la CPU
+type Processor struct {
+	store   types.MediaStore
+	storage types.BlobStorage
+	jobs    chan types.Media
+}
+
+// NewProcessor crea una nueva instancia de Processor
+func NewProcessor(store types.MediaStore, storage types.BlobStorage) *Processor {
+	return &Processor{
+		store:   store,
+		storage: storage,
+		jobs:    make(chan types.Media, processorQueue),
+	}
+}
+
+// Start arranca el procesamiento y encola las imágenes que quedaron pendientes
+// en la ejecución anterior
+func (p *Processor) Start() {
+	go p.run()
+
+	go func() {
+		pending, err := p.store.GetPendingMedia(pendingBatch)
+		if err != nil {
+			log.Printf("Error al leer las imágenes pendientes: %v", err)
+			return
+		}
+		for _, m := range pending {
+			p.jobs <- m
+		}
+	}()
+}
+
+// Enqueue pide las variantes de una imagen recién subida. Si la cola está
+// llena, la imagen queda pendiente hasta el próximo arranque.
+func (p *Processor) Enqueue(media types.Media) {
+	select {
+	case p.jobs <- media:
+	default:
+		log.Printf("Cola de imágenes llena; %s se procesará al reiniciar", media.Clave)
+	}
+}
+
+func (p *Processor) run() {
+	for m := range p.jobs {
+		if err := p.process(m); err != nil {
+			log.Printf("Error al generar las variantes de %s: %v", m.Clave, err)
+		}
+	}
+}
+
+func (p *Processor) process(media types.Media) error {
+	ctx := context.Background()
+
+	body, err := p.storage.Get(ctx, media.Clave)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	_ = body.Close()
+	if err != nil {
+		return err
+	}
+
+	d, err := generateVariants(data, media.Proposito)
+	if errors.Is(err, image.ErrFormat) {
+		// Un formato sin decodificador no se reintenta: se sirve el original
+		return p.store.SaveVariants(media.Clave, nil, "", "")
+	}
+	if err != nil {
+		return err
+	}
+
+	variantes := make([]types.ImagenVariante, 0, len(d.variants))
+	for _, v := range d.variants {
+		clave := variantKey(media.Clave, v.nombre, v.ext)
+		if err := p.storage.Put(ctx, clave, bytes.NewReader(v.data), int64(len(v.data)), v.tipo); err != nil {
+			return err
+		}
+		variantes = append(variantes, types.ImagenVariante{
+			Nombre: v.nombre,
+			Clave:  clave,
+			Tipo:   v.tipo,
+			Ancho:  v.ancho,
+			Alto:   v.alto,
+		})
+	}
+
+	return p.store.SaveVariants(media.Clave, variantes, d.blurhash, d.color)
+}
@@ -0,0 +1,161 @@
+package media
+
+import (
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"math"
+)
+
+// toRGBA copia img en un RGBA que empieza en (0, 0)
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// cropSquare recorta el cuadrado central de img
+func cropSquare(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	return img.SubImage(image.Rect(x, y, x+side, y+side)).(*image.RGBA)
+}
+
+// contribution son los píxeles de origen que forman un píxel de destino y su peso
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+// areaWeights reparte cada píxel de destino sobre el tramo de origen que cubre,
+// con pesos proporcionales a la parte de cada píxel que cae dentro. Al reducir
+// equivale a promediar el área, que no produce aliasing.
+func areaWeights(from, to int) []contribution {
+	scale := float64(from) / float64(to)
+	contributions := make([]contribution, to)
+	for i := range contributions {
+		lo := float64(i) * scale
+		hi := lo + scale
+		start := int(lo)
+		end := min(int(math.Ceil(hi)), from)
+
+		weights := make([]float32, end-start)
+		for j := start; j < end; j++ {
+			overlap := math.Min(hi, float64(j+1)) - math.Max(lo, float64(j))
+			weights[j-start] = float32(overlap / scale)
+		}
+		contributions[i] = contribution{start: start, weights: weights}
+	}
+	return contributions
+}
+
+// resize escala src a w×h en dos pasadas, primero en horizontal y después en
+// vertical. Trabaja con alfa premultiplicado, así que los bordes transparentes
+// no oscurecen la imagen.
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	xs := areaWeights(sw, w)
+	ys := areaWeights(sh, h)
+
+	tmp := make([]float32, w*sh*4)
+	for y := 0; y < sh; y++ {
+		row := src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x, c := range xs {
+			var px [4]float32
+			for k, weight := range c.weights {
+				i := (c.start + k) * 4
+				for ch := 0; ch < 4; ch++ {
+					px[ch] += weight * float32(row[i+ch])
+				}
+			}
+			copy(tmp[(y*w+x)*4:], px[:])
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y, c := range ys {
+		for x := 0; x < w; x++ {
+			var px [4]float32
+			for k, weight := range c.weights {
+				i := ((c.start+k)*w + x) * 4
+				for ch := 0; ch < 4; ch++ {
+					px[ch] += weight * tmp[i+ch]
+				}
+			}
+			o := dst.PixOffset(x, y)
+			for ch := 0; ch < 4; ch++ {
+				dst.Pix[o+ch] = uint8(math.Min(255, math.Max(0, math.Round(float64(px[ch])))))
+			}
+		}
+	}
+	return dst
+}
+
+// jpegOrientation devuelve la orientación EXIF de un JPEG, o 1 si no tiene
+func jpegOrientation(data []byte) uint16 {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			break
+		}
+		if marker == 0xE1 {
+			if o := readOrientation(data[i+4 : end]); o != 0 {
+				return o
+			}
+		}
+		i = end
+	}
+	return 1
+}
+
+// applyOrientation gira o voltea src según la orientación EXIF, para que las
+// variantes, que no llevan EXIF, se vean derechas
+func applyOrientation(src *image.RGBA, orientation uint16) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for sy := 0; sy < h; sy++ {
+		for sx := 0; sx < w; sx++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Volteo horizontal
+				dx, dy = w-1-sx, sy
+			case 3: // 180°
+				dx, dy = w-1-sx, h-1-sy
+			case 4: // Volteo vertical
+				dx, dy = sx, h-1-sy
+			case 5: // Trasposición
+				dx, dy = sy, sx
+			case 6: // 90° en sentido horario
+				dx, dy = h-1-sy, sx
+			case 7: // Trasposición inversa
+				dx, dy = h-1-sy, w-1-sx
+			case 8: // 90° en sentido antihorario
+				dx, dy = sy, w-1-sx
+			}
+			s := src.PixOffset(b.Min.X+sx, b.Min.Y+sy)
+			copy(dst.Pix[dst.PixOffset(dx, dy):], src.Pix[s:s+4])
+		}
+	}
+	return dst
+}
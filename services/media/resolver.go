@@ -0,0 +1,158 @@
+package media
+
+import (
+	"log"
+	"strings"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Resolver implementa ImageResolver con las imágenes guardadas en storage
+type Resolver struct {
+	store   types.MediaStore
+	storage types.BlobStorage
+}
+
+// NewResolver crea una nueva instancia de Resolver
+func NewResolver(store types.MediaStore, storage types.BlobStorage) *Resolver {
+	return &Resolver{store: store, storage: storage}
+}
+
+// Resolve reconoce las URLs de imágenes subidas porque empiezan por la URL
+// pública del almacenamiento; las demás se ignoran
+func (r *Resolver) Resolve(urls []string) (map[string]*types.ImagenResponsive, error) {
+	base := r.storage.URL("")
+	claves := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if clave, ok := strings.CutPrefix(url, base); ok && validKey.MatchString(clave) {
+			claves = append(claves, clave)
+		}
+	}
+
+	images, err := r.store.GetImages(claves)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]*types.ImagenResponsive, len(images))
+	for clave, img := range images {
+		img.URL = r.storage.URL(clave)
+		for i := range img.Variantes {
+			img.Variantes[i].URL = r.storage.URL(img.Variantes[i].Clave)
+		}
+		img.SrcSet = srcSet(img)
+		resolved[img.URL] = img
+	}
+	return resolved, nil
+}
+
+// ImageBlogStore envuelve un BlogStore y añade las variantes de la portada a
+// los blogs que devuelve
+type ImageBlogStore struct {
+	types.BlogStore
+	resolver types.ImageResolver
+}
+
+// NewImageBlogStore crea un BlogStore que rellena Blog.Portada
+func NewImageBlogStore(store types.BlogStore, resolver types.ImageResolver) *ImageBlogStore {
+	return &ImageBlogStore{
+		BlogStore: store,
+		resolver:  resolver,
+	}
+}
+
+func (s *ImageBlogStore) GetBlogBySlug(slug string) (*types.Blog, error) {
+	blog, err := s.BlogStore.GetBlogBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	blogs := []types.Blog{*blog}
+	s.attach(blogs)
+	blog.Portada = blogs[0].Portada
+	return blog, nil
+}
+
+func (s *ImageBlogStore) GetBlogByID(id string) (*types.Blog, error) {
+	blog, err := s.BlogStore.GetBlogByID(id)
+	if err != nil {
+		return nil, err
+	}
+	blogs := []types.Blog{*blog}
+	s.attach(blogs)
+	blog.Portada = blogs[0].Portada
+	return blog, nil
+}
+
+func (s *ImageBlogStore) GetTranslation(grupo string, idioma string) (*types.Blog, error) {
+	blog, err := s.BlogStore.GetTranslation(grupo, idioma)
+	if err != nil {
+		return nil, err
+	}
+	blogs := []types.Blog{*blog}
+	s.attach(blogs)
+	blog.Portada = blogs[0].Portada
+	return blog, nil
+}
+
+func (s *ImageBlogStore) GetBlogs(q types.BlogQuery) ([]types.Blog, error) {
+	blogs, err := s.BlogStore.GetBlogs(q)
+	if err != nil {
+		return nil, err
+	}
+	s.attach(blogs)
+	return blogs, nil
+}
+
+// attach rellena la portada de blogs. Sin variantes el blog se sigue
+// sirviendo, así que un error solo se registra.
+func (s *ImageBlogStore) attach(blogs []types.Blog) {
+	urls := make([]string, 0, len(blogs))
+	for _, blog := range blogs {
+		if blog.ImagenPortada != "" {
+			urls = append(urls, blog.ImagenPortada)
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	images, err := s.resolver.Resolve(urls)
+	if err != nil {
+		log.Printf("Error al obtener las variantes de las portadas: %v", err)
+		return
+	}
+
+	for i := range blogs {
+		blogs[i].Portada = images[blogs[i].ImagenPortada]
+	}
+}
+
+// ImagePersonalizationStore envuelve un PersonalizationStore y añade las
+// variantes de la foto de perfil
+type ImagePersonalizationStore struct {
+	types.PersonalizationStore
+	resolver types.ImageResolver
+}
+
+// NewImagePersonalizationStore crea un PersonalizationStore que rellena Personalization.Avatar
+func NewImagePersonalizationStore(store types.PersonalizationStore, resolver types.ImageResolver) *ImagePersonalizationStore {
+	return &ImagePersonalizationStore{
+		PersonalizationStore: store,
+		resolver:             resolver,
+	}
+}
+
+func (s *ImagePersonalizationStore) GetPersonalization(apodo string) (*types.Personalization, error) {
+	p, err := s.PersonalizationStore.GetPersonalization(apodo)
+	if err != nil || p.Foto == "" {
+		return p, err
+	}
+
+	images, err := s.resolver.Resolve([]string{p.Foto})
+	if err != nil {
+		log.Printf("Error al obtener las variantes de la foto de %s: %v", apodo, err)
+		return p, nil
+	}
+	p.Avatar = images[p.Foto]
+	return p, nil
+}
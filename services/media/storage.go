@@ -0,0 +1,130 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// ServePath es la ruta de la API que sirve los archivos guardados
+const ServePath = "/api/v1/media/"
+
+// validKey acepta claves como "portadas/ab/abcdef.jpg": segmentos en minúsculas
+// separados por "/" y una extensión, sin forma de salir del almacenamiento
+var validKey = regexp.MustCompile(`^[a-z0-9_-]+(/[a-z0-9_-]+)*\.[a-z0-9]+$`)
+
+// NewStorage crea el almacenamiento configurado en MEDIA_STORAGE
+func NewStorage(cfg configs.Config) (types.BlobStorage, error) {
+	publicURL := cfg.MediaPublicURL
+	if publicURL == "" {
+		publicURL = utils.AbsoluteURL(cfg.PublicHost, ServePath)
+	}
+
+	switch cfg.MediaStorage {
+	case "local":
+		return NewLocalStorage(cfg.MediaDir, publicURL)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PublicURL: cfg.MediaPublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown media storage %q", cfg.MediaStorage)
+	}
+}
+
+// LocalStorage guarda los archivos en un directorio del disco
+type LocalStorage struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStorage crea el directorio si no existe
+func NewLocalStorage(dir string, publicURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir, publicURL: publicURL}, nil
+}
+
+// Put escribe el archivo en uno temporal y lo renombra, para que nadie lea
+// nunca un archivo a medias
+func (s *LocalStorage) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".subida-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("blob not found")
+	}
+	return f, err
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return strings.TrimRight(s.publicURL, "/") + "/" + key
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if !validKey.MatchString(key) {
+		return "", fmt.Errorf("invalid key")
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,154 @@
+package media
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBlobStorage sube, lee y borra un archivo con cualquier almacenamiento
+func testBlobStorage(t *testing.T, storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}) {
+	t.Helper()
+	ctx := context.Background()
+	key := "portadas/ab/abcdef.png"
+
+	if err := storage.Put(ctx, key, strings.NewReader("contenido"), 9, "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	body, err := storage.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+	if string(got) != "contenido" {
+		t.Errorf("Get() = %q, want %q", got, "contenido")
+	}
+
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := storage.Get(ctx, key); err == nil || err.Error() != "blob not found" {
+		t.Errorf("Get() after Delete() error = %v, want blob not found", err)
+	}
+
+	for _, bad := range []string{"../secreto.png", "portadas/../../x.png", "/abs.png", "Mayus.png", "sin-extension"} {
+		if err := storage.Put(ctx, bad, strings.NewReader("x"), 1, "image/png"); err == nil {
+			t.Errorf("Put(%q) accepted an invalid key", bad)
+		}
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/api/v1/media/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testBlobStorage(t, storage)
+
+	if got, want := storage.URL("portadas/ab/abcdef.png"), "http://localhost:8080/api/v1/media/portadas/ab/abcdef.png"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
+
+// fakeS3 imita un servidor compatible con S3, como MinIO: guarda los objetos
+// en memoria y rechaza las peticiones mal firmadas
+type fakeS3 struct {
+	storage *S3Storage
+
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	amzDate := r.Header.Get("X-Amz-Date")
+	date, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil {
+		http.Error(w, "missing date", http.StatusForbidden)
+		return
+	}
+	scope := date.Format("20060102") + "/us-east-1/s3/aws4_request"
+	want := "AWS4-HMAC-SHA256 Credential=minio/" + scope + ", SignedHeaders=" + signedHeaders +
+		", Signature=" + f.storage.signature(r, amzDate, scope)
+	if r.Header.Get("Authorization") != want {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: map[string]string{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	storage, err := NewS3Storage(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "pardalis",
+		AccessKey: "minio",
+		SecretKey: "minio-secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.storage = storage
+
+	// Los objetos viven en /bucket/clave y conservan su tipo
+	ctx := context.Background()
+	if err := storage.Put(ctx, "avatares/00/00.webp", strings.NewReader("x"), 1, "image/webp"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := fake.types["/pardalis/avatares/00/00.webp"]; got != "image/webp" {
+		t.Errorf("Content-Type = %q, want image/webp", got)
+	}
+
+	testBlobStorage(t, storage)
+
+	if got, want := storage.URL("portadas/ab/abcdef.png"), server.URL+"/pardalis/portadas/ab/abcdef.png"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+
+	// Con otras credenciales el servidor rechaza la firma
+	other := *storage
+	other.cfg.SecretKey = "otra"
+	if err := other.Put(ctx, "portadas/00/00.png", strings.NewReader("x"), 1, "image/png"); err == nil {
+		t.Errorf("Put() with a wrong secret succeeded")
+	}
+}
+
+func TestNewS3Storage(t *testing.T) {
+	if _, err := NewS3Storage(S3Config{Endpoint: "http://localhost:9000", Bucket: "b"}); err == nil {
+		t.Errorf("NewS3Storage() without credentials succeeded")
+	}
+}
@@ -0,0 +1,168 @@
+// Package media sube y sirve las imágenes de los usuarios: portadas de blogs y fotos de perfil.
+package media
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Store implementa MediaStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// CreateMedia registra una subida. Varias subidas del mismo archivo comparten clave.
+func (s *Store) CreateMedia(media types.Media) error {
+	_, err := s.db.Exec(`
+        INSERT INTO media (id, clave, tipo, tamano, ancho, alto, proposito, autor_apodo)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `, media.ID, media.Clave, media.Tipo, media.Tamano, media.Ancho, media.Alto, media.Proposito, media.AutorApodo)
+	return err
+}
+
+// GetPendingMedia devuelve las subidas cuyas variantes aún no se han generado, de la más antigua a la más reciente
+func (s *Store) GetPendingMedia(limit int) ([]types.Media, error) {
+	rows, err := s.db.Query(`
+        SELECT id, clave, tipo, tamano, ancho, alto, proposito, autor_apodo, fecha_creacion
+        FROM media
+        WHERE fecha_procesado IS NULL
+        ORDER BY fecha_creacion
+        LIMIT ?
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	pending := []types.Media{}
+	for rows.Next() {
+		var m types.Media
+		err := rows.Scan(&m.ID, &m.Clave, &m.Tipo, &m.Tamano, &m.Ancho, &m.Alto, &m.Proposito, &m.AutorApodo, &m.FechaCreacion)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, m)
+	}
+
+	return pending, rows.Err()
+}
+
+// SaveVariants sustituye las variantes de una imagen y la marca como procesada,
+// en todas las subidas que comparten su clave
+func (s *Store) SaveVariants(clave string, variantes []types.ImagenVariante, blurhash string, color string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM media_variantes WHERE clave_original = ?", clave); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	for _, v := range variantes {
+		_, err := tx.Exec(`
+            INSERT INTO media_variantes (clave_original, nombre, clave, tipo, ancho, alto)
+            VALUES (?, ?, ?, ?, ?, ?)
+        `, clave, v.Nombre, v.Clave, v.Tipo, v.Ancho, v.Alto)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE media SET blurhash = NULLIF(?, ''), color = NULLIF(?, ''), fecha_procesado = CURRENT_TIMESTAMP WHERE clave = ?",
+		blurhash, color, clave,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetImages devuelve las imágenes subidas con esas claves y sus variantes, sin
+// URLs, que dependen del almacenamiento. Las claves desconocidas se omiten.
+func (s *Store) GetImages(claves []string) (map[string]*types.ImagenResponsive, error) {
+	images := make(map[string]*types.ImagenResponsive)
+	if len(claves) == 0 {
+		return images, nil
+	}
+
+	args := make([]interface{}, len(claves))
+	for i, clave := range claves {
+		args[i] = clave
+	}
+	in := strings.TrimSuffix(strings.Repeat("?, ", len(claves)), ", ")
+
+	rows, err := s.db.Query(`
+        SELECT clave, MAX(ancho), MAX(alto), COALESCE(MAX(blurhash), ''), COALESCE(MAX(color), '')
+        FROM media
+        WHERE clave IN (`+in+`)
+        GROUP BY clave
+    `, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		img := &types.ImagenResponsive{Variantes: []types.ImagenVariante{}}
+		if err := rows.Scan(&img.Clave, &img.Ancho, &img.Alto, &img.Blurhash, &img.Color); err != nil {
+			return nil, err
+		}
+		images[img.Clave] = img
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	variantRows, err := s.db.Query(`
+        SELECT clave_original, nombre, clave, tipo, ancho, alto
+        FROM media_variantes
+        WHERE clave_original IN (`+in+`)
+        ORDER BY clave_original, ancho
+    `, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(variantRows)
+
+	for variantRows.Next() {
+		var original string
+		var v types.ImagenVariante
+		if err := variantRows.Scan(&original, &v.Nombre, &v.Clave, &v.Tipo, &v.Ancho, &v.Alto); err != nil {
+			return nil, err
+		}
+		if img, ok := images[original]; ok {
+			img.Variantes = append(img.Variantes, v)
+		}
+	}
+
+	return images, variantRows.Err()
+}
@@ -0,0 +1,159 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// jpegQuality es la calidad de las variantes JPEG
+const jpegQuality = 82
+
+// blurhashWidth es el ancho de la copia con la que se calcula el blurhash
+const blurhashWidth = 32
+
+// variantSpec describe una variante: las de ancho fijo conservan la proporción
+// y las cuadradas recortan el centro
+type variantSpec struct {
+	nombre   string
+	ancho    int
+	cuadrada bool
+}
+
+// variantSpecs son las variantes de cada propósito, de menor a mayor
+var variantSpecs = map[string][]variantSpec{
+	types.MediaPortada: {
+		{nombre: types.VarianteMiniatura, ancho: 320},
+		{nombre: types.VarianteTarjeta, ancho: 640},
+		{nombre: types.VarianteHero, ancho: 1600},
+	},
+	types.MediaAvatar: {
+		{nombre: types.VarianteMiniatura, ancho: 64, cuadrada: true},
+		{nombre: types.VarianteTarjeta, ancho: 160, cuadrada: true},
+		{nombre: types.VarianteHero, ancho: 400, cuadrada: true},
+	},
+}
+
+// variant es una variante ya codificada
+type variant struct {
+	nombre string
+	data   []byte
+	tipo   string
+	ext    string
+	ancho  int
+	alto   int
+}
+
+// derivatives son las variantes de una imagen y su marcador
+type derivatives struct {
+	variants []variant
+	blurhash string
+	color    string
+}
+
+// generateVariants decodifica una imagen subida y genera sus variantes. No se
+// amplía nunca: las variantes más anchas que el original se omiten. Las
+// variantes son JPEG, o PNG si la imagen tiene transparencias; la biblioteca
+// estándar no sabe leer ni escribir WebP, así que un WebP devuelve
+// image.ErrFormat y se sirve solo el original.
+func generateVariants(data []byte, proposito string) (*derivatives, error) {
+	specs, ok := variantSpecs[proposito]
+	if !ok {
+		return nil, fmt.Errorf("unknown media purpose %q", proposito)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	src := applyOrientation(toRGBA(decoded), jpegOrientation(data))
+	opaque := src.Opaque()
+
+	square := src
+	if len(specs) > 0 && specs[0].cuadrada {
+		square = cropSquare(src)
+	}
+
+	out := &derivatives{}
+	for _, spec := range specs {
+		base := src
+		if spec.cuadrada {
+			base = square
+		}
+		b := base.Bounds()
+		if spec.ancho >= b.Dx() {
+			continue
+		}
+		alto := max(1, (b.Dy()*spec.ancho+b.Dx()/2)/b.Dx())
+
+		v, err := encodeVariant(resize(base, spec.ancho, alto), opaque)
+		if err != nil {
+			return nil, err
+		}
+		v.nombre = spec.nombre
+		out.variants = append(out.variants, *v)
+	}
+
+	b := square.Bounds()
+	small := square
+	if b.Dx() > blurhashWidth {
+		small = resize(square, blurhashWidth, max(1, b.Dy()*blurhashWidth/b.Dx()))
+	}
+	out.blurhash, out.color = blurhash(small)
+
+	return out, nil
+}
+
+func encodeVariant(img *image.RGBA, opaque bool) (*variant, error) {
+	var buf bytes.Buffer
+	v := &variant{ancho: img.Bounds().Dx(), alto: img.Bounds().Dy()}
+
+	if opaque {
+		v.tipo, v.ext = "image/jpeg", "jpg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+	} else {
+		v.tipo, v.ext = "image/png", "png"
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	}
+
+	v.data = buf.Bytes()
+	return v, nil
+}
+
+// variantKey guarda la variante junto al original: portadas/ab/abcd.png
+// produce portadas/ab/abcd_tarjeta.jpg
+func variantKey(clave string, nombre string, ext string) string {
+	if i := strings.LastIndexByte(clave, '.'); i >= 0 {
+		clave = clave[:i]
+	}
+	return clave + "_" + nombre + "." + ext
+}
+
+// srcSet construye el valor del atributo srcset con las variantes y el original
+func srcSet(img *types.ImagenResponsive) string {
+	parts := make([]string, 0, len(img.Variantes)+1)
+	for _, v := range img.Variantes {
+		parts = append(parts, fmt.Sprintf("%s %dw", v.URL, v.Ancho))
+	}
+	// El original solo entra si tiene la misma proporción que las variantes:
+	// las de los avatares están recortadas
+	if img.Ancho > 0 && (len(img.Variantes) == 0 || sameAspect(img.Variantes[0], img.Ancho, img.Alto)) {
+		parts = append(parts, fmt.Sprintf("%s %dw", img.URL, img.Ancho))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sameAspect indica si v tiene la proporción de ancho×alto, salvo el redondeo de su alto
+func sameAspect(v types.ImagenVariante, ancho int, alto int) bool {
+	diff := v.Alto*ancho - v.Ancho*alto
+	return diff <= ancho && diff >= -ancho
+}
@@ -0,0 +1,177 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestResize(t *testing.T) {
+	// Mitad izquierda negra y mitad derecha blanca
+	src := solid(100, 10, color.RGBA{A: 255})
+	for y := 0; y < 10; y++ {
+		for x := 50; x < 100; x++ {
+			src.Set(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+
+	dst := resize(src, 3, 1)
+	if b := dst.Bounds(); b.Dx() != 3 || b.Dy() != 1 {
+		t.Fatalf("resize() bounds = %v", b)
+	}
+	// El píxel central cubre las dos mitades a partes iguales
+	if got := dst.RGBAAt(0, 0).R; got != 0 {
+		t.Errorf("resize() left = %d, want 0", got)
+	}
+	if got := dst.RGBAAt(1, 0).R; got < 126 || got > 129 {
+		t.Errorf("resize() center = %d, want ~128", got)
+	}
+	if got := dst.RGBAAt(2, 0).R; got != 255 {
+		t.Errorf("resize() right = %d, want 255", got)
+	}
+
+	// Funciona igual sobre un recorte que no empieza en (0, 0)
+	sub := src.SubImage(image.Rect(50, 0, 100, 10)).(*image.RGBA)
+	if got := resize(sub, 5, 1).RGBAAt(0, 0).R; got != 255 {
+		t.Errorf("resize() of a subimage = %d, want 255", got)
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 3×2 con un píxel rojo arriba a la izquierda
+	src := solid(3, 2, color.RGBA{A: 255})
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	tests := []struct {
+		orientation uint16
+		w, h        int
+		red         image.Point
+	}{
+		{1, 3, 2, image.Pt(0, 0)},
+		{2, 3, 2, image.Pt(2, 0)},
+		{3, 3, 2, image.Pt(2, 1)},
+		{4, 3, 2, image.Pt(0, 1)},
+		{5, 2, 3, image.Pt(0, 0)},
+		{6, 2, 3, image.Pt(1, 0)},
+		{7, 2, 3, image.Pt(1, 2)},
+		{8, 2, 3, image.Pt(0, 2)},
+	}
+
+	for _, tt := range tests {
+		got := applyOrientation(src, tt.orientation)
+		if b := got.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("applyOrientation(%d) bounds = %v, want %dx%d", tt.orientation, b, tt.w, tt.h)
+			continue
+		}
+		if got.RGBAAt(tt.red.X, tt.red.Y).R != 255 {
+			t.Errorf("applyOrientation(%d) red pixel not at %v", tt.orientation, tt.red)
+		}
+	}
+}
+
+func TestGenerateVariants(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, solid(800, 400, color.RGBA{R: 200, G: 100, B: 50, A: 255}), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Portada de 800 px: no se amplía a hero
+	d, err := generateVariants(buf.Bytes(), types.MediaPortada)
+	if err != nil {
+		t.Fatalf("generateVariants() error = %v", err)
+	}
+	if len(d.variants) != 2 {
+		t.Fatalf("generateVariants() = %d variants, want 2", len(d.variants))
+	}
+	if v := d.variants[1]; v.nombre != types.VarianteTarjeta || v.ancho != 640 || v.alto != 320 || v.tipo != "image/jpeg" {
+		t.Errorf("generateVariants() tarjeta = %s %dx%d %s", v.nombre, v.ancho, v.alto, v.tipo)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(d.variants[0].data)); err != nil {
+		t.Errorf("generateVariants() produced an invalid jpeg: %v", err)
+	}
+	if len(d.blurhash) != 28 || d.color == "" {
+		t.Errorf("generateVariants() placeholder = %q %q", d.blurhash, d.color)
+	}
+
+	// Los avatares son cuadrados
+	d, err = generateVariants(buf.Bytes(), types.MediaAvatar)
+	if err != nil {
+		t.Fatalf("generateVariants() error = %v", err)
+	}
+	for _, v := range d.variants {
+		if v.ancho != v.alto {
+			t.Errorf("generateVariants() avatar %s = %dx%d, want a square", v.nombre, v.ancho, v.alto)
+		}
+	}
+
+	// Las transparencias se conservan en PNG
+	buf.Reset()
+	if err := png.Encode(&buf, solid(400, 400, color.RGBA{})); err != nil {
+		t.Fatal(err)
+	}
+	d, err = generateVariants(buf.Bytes(), types.MediaPortada)
+	if err != nil {
+		t.Fatalf("generateVariants() error = %v", err)
+	}
+	if len(d.variants) == 0 || d.variants[0].tipo != "image/png" {
+		t.Errorf("generateVariants() of a transparent image = %+v, want png", d.variants)
+	}
+}
+
+func TestBlurhash(t *testing.T) {
+	hash, c := blurhash(solid(8, 8, color.RGBA{R: 255, A: 255}))
+
+	// 4×3 componentes: tamaño, máximo, color medio en 4 caracteres y 11 componentes de 2
+	if len(hash) != 28 || hash[0] != 'L' {
+		t.Errorf("blurhash() = %q, want 28 characters starting with L", hash)
+	}
+	if got := hash[2:6]; got != encode83(255<<16, 4) {
+		t.Errorf("blurhash() average = %q, want pure red", got)
+	}
+	if c != "#ff0000" {
+		t.Errorf("blurhash() color = %q, want #ff0000", c)
+	}
+
+	if got := encode83(83*83+5, 3); got != "105" {
+		t.Errorf("encode83() = %q, want 105", got)
+	}
+}
+
+func TestSrcSet(t *testing.T) {
+	img := &types.ImagenResponsive{
+		URL:   "https://cdn/p.jpg",
+		Ancho: 1000,
+		Alto:  500,
+		Variantes: []types.ImagenVariante{
+			{URL: "https://cdn/p_miniatura.jpg", Ancho: 320, Alto: 160},
+			{URL: "https://cdn/p_tarjeta.jpg", Ancho: 640, Alto: 320},
+		},
+	}
+	want := "https://cdn/p_miniatura.jpg 320w, https://cdn/p_tarjeta.jpg 640w, https://cdn/p.jpg 1000w"
+	if got := srcSet(img); got != want {
+		t.Errorf("srcSet() = %q, want %q", got, want)
+	}
+
+	// Un avatar recortado no mezcla el original rectangular
+	img.Variantes = []types.ImagenVariante{{URL: "https://cdn/a_miniatura.jpg", Ancho: 64, Alto: 64}}
+	if got := srcSet(img); got != "https://cdn/a_miniatura.jpg 64w" {
+		t.Errorf("srcSet() = %q", got)
+	}
+
+	if got := variantKey("portadas/ab/abcd.png", types.VarianteHero, "jpg"); got != "portadas/ab/abcd_hero.jpg" {
+		t.Errorf("variantKey() = %q", got)
+	}
+}
@@ -0,0 +1,251 @@
+package moderation
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// adminAction completa el error de auth.RequireAdmin en las rutas de este handler
+const adminAction = "review moderated content"
+
+// Handler maneja la cola de revisión de la moderación
+type Handler struct {
+	store                types.ModerationStore
+	blogStore            types.BlogStore
+	commentStore         types.CommentStore
+	personalizationStore types.PersonalizationStore
+	userStore            types.UserStore
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.ModerationStore, blogStore types.BlogStore, commentStore types.CommentStore, personalizationStore types.PersonalizationStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:                store,
+		blogStore:            blogStore,
+		commentStore:         commentStore,
+		personalizationStore: personalizationStore,
+		userStore:            userStore,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/admin/moderation", auth.WithJWTAuth(h.handleGetQueue, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/moderation/{id}", auth.WithJWTAuth(h.handleGetCase, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/moderation/{id}", auth.WithJWTAuth(h.handleResolveCase, h.userStore)).Methods(http.MethodPost)
+}
+
+// handleGetQueue lista los casos pendientes, los más antiguos primero. Con
+// ?tipo= se filtra por blog, comentario o descripcion.
+func (h *Handler) handleGetQueue(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	tipo := r.URL.Query().Get("tipo")
+	switch tipo {
+	case "", types.ContenidoBlog, types.ContenidoComentario, types.ContenidoDescripcion:
+	default:
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("tipo must be blog, comentario or descripcion"))
+		return
+	}
+
+	pagination, err := utils.ParsePagination(r, 20, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cases, err := h.store.GetPendingCases(tipo, pagination.After.Fecha, pagination.After.ID, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WritePage(w, r, utils.NewPage(cases, pagination.Limit, caseCursor))
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleGetCase(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	c, ok := h.getCase(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusOK, c)
+	if err != nil {
+		return
+	}
+}
+
+// handleResolveCase aprueba o rechaza un caso. Aprobar publica lo retenido;
+// rechazar retira lo que se publicó marcado.
+func (h *Handler) handleResolveCase(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	var payload types.ResolveModerationCasePayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	c, ok := h.getCase(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	if c.Estado != types.CasoPendiente {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("moderation case already resolved"))
+		return
+	}
+
+	estado := types.CasoAprobado
+	if payload.Accion == "rechazar" {
+		estado = types.CasoRechazado
+	}
+
+	err := h.apply(c, estado)
+	if err != nil {
+		switch err.Error() {
+		case "version conflict":
+			// El autor guardó mientras tanto, lo que ya abrió otro caso
+			utils.WriteError(w, http.StatusConflict, fmt.Errorf("content changed while it was being reviewed"))
+		case "blog not found", "comment not found", "personalization not found":
+			utils.WriteError(w, http.StatusNotFound, err)
+		default:
+			utils.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	revisor := auth.GetUserApodoFromContext(r.Context())
+	err = h.store.ResolveCase(c.ID, estado, revisor)
+	if err != nil {
+		if err.Error() == "moderation case already resolved" {
+			utils.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resolved, err := h.store.GetCaseByID(c.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, resolved)
+	if err != nil {
+		return
+	}
+}
+
+// apply lleva la decisión al recurso del caso
+func (h *Handler) apply(c *types.ModerationCase, estado string) error {
+	switch c.Tipo {
+	case types.ContenidoBlog:
+		return h.applyBlog(c, estado)
+	case types.ContenidoComentario:
+		return h.applyComment(c, estado)
+	case types.ContenidoDescripcion:
+		return h.applyDescription(c, estado)
+	}
+	return nil
+}
+
+// applyBlog publica el blog retenido si el autor lo pidió, o lo devuelve a
+// borrador si se rechaza
+func (h *Handler) applyBlog(c *types.ModerationCase, estado string) error {
+	blog, err := h.blogStore.GetBlogByID(c.RecursoID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case estado == types.CasoAprobado && c.Publicar && blog.Estado == "borrador":
+		blog.Estado = "publicado"
+	case estado == types.CasoRechazado && blog.Estado == "publicado":
+		blog.Estado = "borrador"
+	default:
+		return nil
+	}
+
+	return h.blogStore.UpdateBlog(*blog)
+}
+
+// applyComment muestra el comentario retenido u oculta el rechazado
+func (h *Handler) applyComment(c *types.ModerationCase, estado string) error {
+	comment, err := h.commentStore.GetCommentByID(c.RecursoID)
+	if err != nil {
+		return err
+	}
+	if comment.Estado == types.ComentarioEliminado {
+		return nil
+	}
+
+	switch {
+	case estado == types.CasoAprobado && comment.Estado == types.ComentarioPendiente:
+		return h.commentStore.SetCommentState(comment.ID, types.ComentarioVisible)
+	case estado == types.CasoRechazado:
+		return h.commentStore.SetCommentState(comment.ID, types.ComentarioOculto)
+	}
+	return nil
+}
+
+// applyDescription guarda la descripción retenida o borra la marcada. Si el
+// usuario ya la cambió no se toca.
+func (h *Handler) applyDescription(c *types.ModerationCase, estado string) error {
+	p, err := h.personalizationStore.GetPersonalization(c.RecursoID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case estado == types.CasoAprobado && c.Decision == types.ModeracionRetener:
+		p.Descripcion = c.Contenido
+	case estado == types.CasoRechazado && c.Decision == types.ModeracionMarcar && p.Descripcion == c.Contenido:
+		p.Descripcion = ""
+	default:
+		return nil
+	}
+
+	return h.personalizationStore.UpdatePersonalization(*p)
+}
+
+func (h *Handler) getCase(w http.ResponseWriter, id string) (*types.ModerationCase, bool) {
+	c, err := h.store.GetCaseByID(id)
+	if err != nil {
+		if err.Error() == "moderation case not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return nil, false
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return c, true
+}
+
+// caseCursor devuelve la posición de un caso en la cola, que se ordena por
+// fecha de creación e id
+func caseCursor(c types.ModerationCase) types.Cursor {
+	return types.Cursor{Fecha: c.FechaCreacion, ID: c.ID}
+}
@@ -0,0 +1,134 @@
+// Package moderation revisa el texto que escriben los usuarios antes de
+// guardarlo: blogs, comentarios y descripciones de perfil. Según la política,
+// el texto se permite, se marca para revisión, se retiene sin publicar hasta
+// que lo apruebe un administrador o se rechaza.
+package moderation
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// DecisionHeader indica en la respuesta que el contenido se marcó o se retuvo
+const DecisionHeader = "X-Moderation-Decision"
+
+// Pipeline junta el moderador y la cola de revisión. Los handlers llaman a
+// Check antes de guardar y a Record después, cuando ya conocen el id.
+type Pipeline struct {
+	moderator types.Moderator
+	store     types.ModerationStore
+}
+
+// NewPipeline crea una nueva instancia de Pipeline
+func NewPipeline(moderator types.Moderator, store types.ModerationStore) *Pipeline {
+	return &Pipeline{
+		moderator: moderator,
+		store:     store,
+	}
+}
+
+// Check modera el texto que autor quiere guardar. Lo que escriben profesores y
+// administradores no se modera, igual que sus comentarios no se retienen.
+func (p *Pipeline) Check(tipo string, autor *types.User, texto string) (types.ModerationResult, error) {
+	if autor.EsModerador() {
+		return types.ModerationResult{Decision: types.ModeracionPermitir, Hallazgos: []types.ModerationFinding{}}, nil
+	}
+
+	return p.moderator.Moderate(types.ModerationRequest{
+		Tipo:  tipo,
+		Texto: texto,
+		Autor: autor,
+	})
+}
+
+// Record abre un caso para el recurso ya guardado si hay que revisarlo, o
+// cierra el que tuviera pendiente si su contenido nuevo está limpio. publicar
+// indica si un blog retenido se publica al aprobarlo.
+func (p *Pipeline) Record(tipo string, recursoID string, autor *types.User, texto string, result types.ModerationResult, publicar bool) error {
+	if result.Decision != types.ModeracionMarcar && result.Decision != types.ModeracionRetener {
+		return p.store.SupersedeCases(tipo, recursoID)
+	}
+
+	return p.store.CreateCase(types.ModerationCase{
+		ID:            uuid.New().String(),
+		Tipo:          tipo,
+		RecursoID:     recursoID,
+		AutorApodo:    autor.Apodo,
+		Contenido:     texto,
+		Decision:      result.Decision,
+		Hallazgos:     result.Hallazgos,
+		Publicar:      publicar,
+		Estado:        types.CasoPendiente,
+		FechaCreacion: time.Now(),
+	})
+}
+
+// IsHeld indica si el recurso está retenido esperando a un administrador
+func (p *Pipeline) IsHeld(tipo string, recursoID string) (bool, error) {
+	c, err := p.store.GetPendingCase(tipo, recursoID)
+	if err != nil {
+		if err.Error() == "moderation case not found" {
+			return false, nil
+		}
+		return false, err
+	}
+	return c.Decision == types.ModeracionRetener, nil
+}
+
+// WriteRejection responde 422 con las reglas que rechazaron el contenido
+func WriteRejection(w http.ResponseWriter, result types.ModerationResult) {
+	var reglas []string
+	seen := map[string]bool{}
+	for _, h := range result.Hallazgos {
+		if !seen[h.Regla] {
+			seen[h.Regla] = true
+			reglas = append(reglas, h.Regla)
+		}
+	}
+	utils.WriteError(w, http.StatusUnprocessableEntity, fmt.Errorf("content rejected by moderation: %s", strings.Join(reglas, ", ")))
+}
+
+// SetDecisionHeader avisa al cliente de que el contenido se marcó o se retuvo
+func SetDecisionHeader(w http.ResponseWriter, result types.ModerationResult) {
+	if result.Decision == types.ModeracionMarcar || result.Decision == types.ModeracionRetener {
+		w.Header().Set(DecisionHeader, result.Decision)
+	}
+}
+
+// BlogText es el texto de un blog que se modera, bloques incluidos
+func BlogText(blog types.Blog) string {
+	parts := []string{blog.Titulo, blog.Extracto, blog.MetaDescripcion, strings.Join(blog.Tags, ", "), blog.Contenido}
+	for _, b := range blog.Bloques {
+		parts = append(parts, blockText(b)...)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// blockText devuelve lo que el lector ve de un bloque, enlaces incluidos
+func blockText(b types.Bloque) []string {
+	var parts []string
+	switch {
+	case b.Codigo != nil:
+		parts = append(parts, b.Codigo.Codigo, b.Codigo.Entrada, b.Codigo.SalidaEsperada)
+	case b.Pregunta != nil:
+		parts = append(parts, b.Pregunta.Enunciado, b.Pregunta.Explicacion)
+		for _, o := range b.Pregunta.Opciones {
+			parts = append(parts, o.Texto)
+		}
+	case b.Galeria != nil:
+		for _, img := range b.Galeria.Imagenes {
+			parts = append(parts, img.URL, img.Alt, img.Pie)
+		}
+	case b.Video != nil:
+		parts = append(parts, b.Video.URL, b.Video.Titulo)
+	case b.Aviso != nil:
+		parts = append(parts, b.Aviso.Titulo, b.Aviso.Texto)
+	}
+	return parts
+}
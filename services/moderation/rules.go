@@ -0,0 +1,277 @@
+package moderation
+
+import (
+	_ "embed"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// defaultWords es la lista de palabras que se usa siempre
+//
+//go:embed palabras.txt
+var defaultWords string
+
+var (
+	emailPattern = regexp.MustCompile(`[\p{L}0-9._%+-]+@[\p{L}0-9-]+(?:\.[\p{L}0-9-]+)*\.\p{L}{2,}`)
+	// phonePattern encuentra candidatos; containsPhone cuenta después sus dígitos
+	phonePattern = regexp.MustCompile(`\+?\(?\d[\d \t().-]{7,}\d`)
+	// addressPattern cubre "calle Mayor 12", "Av. Arequipa nº 1520" y "221 Baker Street"
+	addressPattern = regexp.MustCompile(`(?i)(?:^|[^\p{L}])((?:calle|c/|avenida|avda\.?|av\.|plaza|pza\.|paseo|carrera|cra\.|jir[oó]n|jr\.|pasaje|psje\.|street|avenue|road)\s+[\p{L} .'-]{2,40}?,?\s*(?:n[º°o.]\s*)?\d{1,5}\b|\b\d{1,5}\s+[\p{L} .'-]{2,40}?\s(?:street|st\.|avenue|ave\.|road|rd\.))`)
+	linkPattern    = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()\[\]"'` + "`" + `]+`)
+)
+
+// severity ordena las decisiones para quedarse con la más grave
+var severity = map[string]int{
+	types.ModeracionPermitir: 0,
+	types.ModeracionMarcar:   1,
+	types.ModeracionRetener:  2,
+	types.ModeracionRechazar: 3,
+}
+
+// Policy asigna una decisión a cada regla. Las reglas que no aparecen se permiten.
+type Policy map[string]string
+
+// ParsePolicy lee una política escrita como "regla=decision", por ejemplo
+// "palabras=retener,datos_personales=rechazar"
+func ParsePolicy(entries []string) (Policy, error) {
+	policy := Policy{}
+	for _, entry := range entries {
+		regla, decision, ok := strings.Cut(entry, "=")
+		regla, decision = strings.TrimSpace(regla), strings.TrimSpace(decision)
+		if !ok {
+			return nil, fmt.Errorf("invalid moderation policy entry %q", entry)
+		}
+		switch regla {
+		case types.ReglaPalabras, types.ReglaDatosPersonales, types.ReglaEnlaces:
+		default:
+			return nil, fmt.Errorf("unknown moderation rule %q", regla)
+		}
+		if _, ok := severity[decision]; !ok {
+			return nil, fmt.Errorf("unknown moderation decision %q", decision)
+		}
+		policy[regla] = decision
+	}
+	return policy, nil
+}
+
+// RuleModerator es el moderador local: busca palabras de una lista, datos
+// personales y enlaces fuera de los dominios permitidos. Los menores tienen
+// su propia política, normalmente más estricta.
+type RuleModerator struct {
+	words          []string
+	allowedDomains []string
+	policy         Policy
+	minorsPolicy   Policy
+	now            func() time.Time
+}
+
+// NewRuleModerator crea un moderador con las palabras y los dominios dados
+func NewRuleModerator(words []string, allowedDomains []string, policy Policy, minorsPolicy Policy) *RuleModerator {
+	m := &RuleModerator{
+		policy:       policy,
+		minorsPolicy: minorsPolicy,
+		now:          time.Now,
+	}
+
+	seen := map[string]bool{}
+	for _, word := range words {
+		word = strings.Join(tokenize(word), " ")
+		if word != "" && !seen[word] {
+			seen[word] = true
+			m.words = append(m.words, word)
+		}
+	}
+	for _, domain := range allowedDomains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		if domain != "" {
+			m.allowedDomains = append(m.allowedDomains, domain)
+		}
+	}
+
+	return m
+}
+
+// NewModerator crea el moderador local con la configuración de MODERATION_*.
+// El dominio de PUBLIC_HOST siempre está permitido.
+func NewModerator(cfg configs.Config) (*RuleModerator, error) {
+	policy, err := ParsePolicy(cfg.ModerationPolicy)
+	if err != nil {
+		return nil, err
+	}
+	minorsPolicy, err := ParsePolicy(cfg.ModerationPolicyMinors)
+	if err != nil {
+		return nil, err
+	}
+
+	words := parseWordList(defaultWords)
+	if cfg.ModerationWordsFile != "" {
+		data, err := os.ReadFile(cfg.ModerationWordsFile)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, parseWordList(string(data))...)
+	}
+
+	domains := cfg.ModerationAllowedDomains
+	if host, err := url.Parse(cfg.PublicHost); err == nil && host.Hostname() != "" {
+		domains = append([]string{host.Hostname()}, domains...)
+	}
+
+	return NewRuleModerator(words, domains, policy, minorsPolicy), nil
+}
+
+// Moderate aplica al texto la política del autor. Solo cuentan los hallazgos
+// de las reglas que la política no permite.
+func (m *RuleModerator) Moderate(req types.ModerationRequest) (types.ModerationResult, error) {
+	policy := m.policy
+	if req.Autor != nil && req.Autor.EsMenor(m.now()) {
+		policy = m.minorsPolicy
+	}
+
+	result := types.ModerationResult{
+		Decision:  types.ModeracionPermitir,
+		Hallazgos: []types.ModerationFinding{},
+	}
+	for _, finding := range m.findings(req.Texto) {
+		decision := policy[finding.Regla]
+		if decision == "" || decision == types.ModeracionPermitir {
+			continue
+		}
+		result.Hallazgos = append(result.Hallazgos, finding)
+		if severity[decision] > severity[result.Decision] {
+			result.Decision = decision
+		}
+	}
+
+	return result, nil
+}
+
+// findings devuelve todas las coincidencias de las reglas en el texto, sin
+// repetir fragmentos
+func (m *RuleModerator) findings(text string) []types.ModerationFinding {
+	var findings []types.ModerationFinding
+	seen := map[string]bool{}
+	add := func(regla, tipo, fragmento string) {
+		key := tipo + "\x00" + strings.ToLower(fragmento)
+		if !seen[key] {
+			seen[key] = true
+			findings = append(findings, types.ModerationFinding{Regla: regla, Tipo: tipo, Fragmento: fragmento})
+		}
+	}
+
+	// Los enlaces y los correos se quitan antes de buscar teléfonos y
+	// direcciones para no confundir sus números con ellos
+	rest := text
+	for _, link := range linkPattern.FindAllString(rest, -1) {
+		link = strings.TrimRight(link, ".,;:!?")
+		if !m.allowedLink(link) {
+			add(types.ReglaEnlaces, "enlace", link)
+		}
+	}
+	rest = linkPattern.ReplaceAllString(rest, " ")
+
+	for _, email := range emailPattern.FindAllString(rest, -1) {
+		add(types.ReglaDatosPersonales, "correo", email)
+	}
+	rest = emailPattern.ReplaceAllString(rest, " ")
+
+	for _, phone := range phonePattern.FindAllString(rest, -1) {
+		if containsPhone(phone) {
+			add(types.ReglaDatosPersonales, "telefono", strings.TrimSpace(phone))
+		}
+	}
+	for _, match := range addressPattern.FindAllStringSubmatch(rest, -1) {
+		add(types.ReglaDatosPersonales, "direccion", strings.TrimSpace(match[1]))
+	}
+
+	// Las palabras se buscan como secuencias completas de tokens normalizados
+	normalized := " " + strings.Join(tokenize(text), " ") + " "
+	for _, word := range m.words {
+		if strings.Contains(normalized, " "+word+" ") {
+			add(types.ReglaPalabras, "palabra", word)
+		}
+	}
+
+	return findings
+}
+
+// allowedLink indica si el enlace apunta a un dominio permitido o a uno de sus subdominios
+func (m *RuleModerator) allowedLink(link string) bool {
+	host := link
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if i := strings.Index(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+
+	for _, domain := range m.allowedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPhone acepta los candidatos con entre 9 y 15 dígitos, lo que dejan
+// fuera años, fechas y cantidades
+func containsPhone(candidate string) bool {
+	digits := 0
+	for _, r := range candidate {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
+
+// leet deshace las sustituciones habituales para esquivar la lista de palabras
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// accents quita las tildes y la diéresis; la ñ se conserva
+var accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u")
+
+// tokenize divide el texto en palabras en minúsculas y sin tildes. Las
+// palabras que mezclan letras y cifras se leen como leetspeak.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '$'
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = accents.Replace(field)
+		if strings.IndexFunc(field, unicode.IsLetter) >= 0 {
+			field = leet.Replace(field)
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// parseWordList lee una lista de palabras: una por línea, con # para comentarios
+func parseWordList(data string) []string {
+	var words []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	return words
+}
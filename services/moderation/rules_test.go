@@ -0,0 +1,138 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func newTestModerator() *RuleModerator {
+	m := NewRuleModerator(
+		[]string{"idiota", "hijo de puta", "  "},
+		[]string{"wikipedia.org", "www.pardalis.edu"},
+		Policy{types.ReglaPalabras: types.ModeracionMarcar},
+		Policy{
+			types.ReglaPalabras:        types.ModeracionRetener,
+			types.ReglaDatosPersonales: types.ModeracionRechazar,
+			types.ReglaEnlaces:         types.ModeracionRetener,
+		},
+	)
+	m.now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	return m
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]string{"palabras=retener", " enlaces = marcar "})
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	want := Policy{types.ReglaPalabras: types.ModeracionRetener, types.ReglaEnlaces: types.ModeracionMarcar}
+	if !reflect.DeepEqual(policy, want) {
+		t.Errorf("ParsePolicy() = %v, want %v", policy, want)
+	}
+
+	for _, entries := range [][]string{{"palabras"}, {"spam=marcar"}, {"enlaces=borrar"}} {
+		if _, err := ParsePolicy(entries); err == nil {
+			t.Errorf("ParsePolicy(%v) should fail", entries)
+		}
+	}
+}
+
+func TestModerate(t *testing.T) {
+	m := newTestModerator()
+	nacimiento := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	mayoria := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	menor := &types.User{Apodo: "luz", FechaNacimiento: &nacimiento}
+	adulto := &types.User{Apodo: "ana", FechaNacimiento: &mayoria}
+	sinFecha := &types.User{Apodo: "leo"}
+
+	tests := []struct {
+		name     string
+		autor    *types.User
+		texto    string
+		decision string
+		tipos    []string
+	}{
+		{"texto limpio", menor, "Las plantas hacen la fotosíntesis.", types.ModeracionPermitir, nil},
+		{"palabra de adulto", adulto, "Eres un IDIOTA.", types.ModeracionMarcar, []string{"palabra"}},
+		{"palabra con tildes y leetspeak", menor, "qué 1d10ta", types.ModeracionRetener, []string{"palabra"}},
+		{"expresión", adulto, "hijo de  puta", types.ModeracionMarcar, []string{"palabra"}},
+		{"palabra dentro de otra", adulto, "idiotamente", types.ModeracionPermitir, nil},
+		{"datos personales de adulto", adulto, "llámame al 600 123 456", types.ModeracionPermitir, nil},
+		{"sin fecha de nacimiento cuenta como menor", sinFecha, "llámame al 600 123 456", types.ModeracionRechazar, []string{"telefono"}},
+		{"teléfono de menor", menor, "llámame al +34 600-123-456", types.ModeracionRechazar, []string{"telefono"}},
+		{"correo de menor", menor, "escríbeme a luz.perez@correo.es", types.ModeracionRechazar, []string{"correo"}},
+		{"dirección de menor", menor, "vivo en la calle Mayor 12, 3º", types.ModeracionRechazar, []string{"direccion"}},
+		{"enlace permitido", menor, "mira https://es.wikipedia.org/wiki/Fotos%C3%ADntesis.", types.ModeracionPermitir, nil},
+		{"enlace a otro dominio", menor, "mira www.ejemplo.com/2024/06/01/123456789", types.ModeracionRetener, []string{"enlace"}},
+		{"lo más grave gana", menor, "idiota, mi número es 600123456", types.ModeracionRechazar, []string{"telefono", "palabra"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.Moderate(types.ModerationRequest{Tipo: types.ContenidoComentario, Texto: tt.texto, Autor: tt.autor})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Decision != tt.decision {
+				t.Errorf("Moderate() decision = %q, want %q (%+v)", result.Decision, tt.decision, result.Hallazgos)
+			}
+			var tipos []string
+			for _, h := range result.Hallazgos {
+				tipos = append(tipos, h.Tipo)
+			}
+			if !reflect.DeepEqual(tipos, tt.tipos) {
+				t.Errorf("Moderate() findings = %v, want %v", tipos, tt.tipos)
+			}
+		})
+	}
+}
+
+func TestAllowedLink(t *testing.T) {
+	m := newTestModerator()
+	tests := map[string]bool{
+		"https://wikipedia.org":                true,
+		"http://es.wikipedia.org/wiki/Go":      true,
+		"www.pardalis.edu/blogs/hola":          true,
+		"https://PARDALIS.edu:8080/x":          true,
+		"https://wikipedia.org.ejemplo.com":    false,
+		"https://notwikipedia.org":             false,
+		"https://wikipedia.org@ejemplo.com/x":  false,
+		"https://ejemplo.com/?r=wikipedia.org": false,
+	}
+
+	for link, want := range tests {
+		if got := m.allowedLink(link); got != want {
+			t.Errorf("allowedLink(%q) = %v, want %v", link, got, want)
+		}
+	}
+}
+
+func TestContainsPhone(t *testing.T) {
+	tests := map[string]bool{
+		"600 123 456":      true,
+		"+51 (1) 234-5678": true,
+		"1492 1500":        false,
+		"2024-06-01":       false,
+		"1234567890123456": false,
+	}
+
+	for candidate, want := range tests {
+		if got := containsPhone(candidate); got != want {
+			t.Errorf("containsPhone(%q) = %v, want %v", candidate, got, want)
+		}
+	}
+}
+
+func TestParseWordList(t *testing.T) {
+	got := parseWordList("# comentario\n\nuno\r\n  dos palabras  \n")
+	if want := []string{"uno", "dos palabras"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWordList() = %v, want %v", got, want)
+	}
+
+	if len(parseWordList(defaultWords)) == 0 {
+		t.Errorf("default word list is empty")
+	}
+}
@@ -0,0 +1,192 @@
+package moderation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// caseColumns son las columnas que scanCase espera, en orden
+const caseColumns = `
+	id, tipo, recurso_id, autor_apodo, contenido, decision, hallazgos,
+	publicar, estado, fecha_creacion, revisado_por, fecha_revision
+`
+
+// Store implementa ModerationStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// CreateCase añade un caso a la cola y supera el caso pendiente que tuviera
+// el mismo recurso, que ya no corresponde a su contenido
+func (s *Store) CreateCase(c types.ModerationCase) error {
+	hallazgos, err := json.Marshal(c.Hallazgos)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE moderacion_casos SET estado = 'superado' WHERE tipo = ? AND recurso_id = ? AND estado = 'pendiente'",
+		c.Tipo, c.RecursoID,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO moderacion_casos (
+            id, tipo, recurso_id, autor_apodo, contenido, decision,
+            hallazgos, publicar, estado, fecha_creacion
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, c.ID, c.Tipo, c.RecursoID, c.AutorApodo, c.Contenido, c.Decision,
+		hallazgos, c.Publicar, c.Estado, c.FechaCreacion)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SupersedeCases cierra el caso pendiente de un recurso cuyo contenido nuevo
+// ya no necesita revisión
+func (s *Store) SupersedeCases(tipo string, recursoID string) error {
+	_, err := s.db.Exec(
+		"UPDATE moderacion_casos SET estado = 'superado' WHERE tipo = ? AND recurso_id = ? AND estado = 'pendiente'",
+		tipo, recursoID,
+	)
+	return err
+}
+
+// GetCaseByID obtiene un caso por su id
+func (s *Store) GetCaseByID(id string) (*types.ModerationCase, error) {
+	row := s.db.QueryRow("SELECT "+caseColumns+" FROM moderacion_casos WHERE id = ?", id)
+
+	c, err := scanCase(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("moderation case not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetPendingCase obtiene el caso pendiente de un recurso
+func (s *Store) GetPendingCase(tipo string, recursoID string) (*types.ModerationCase, error) {
+	row := s.db.QueryRow(
+		"SELECT "+caseColumns+" FROM moderacion_casos WHERE tipo = ? AND recurso_id = ? AND estado = 'pendiente'",
+		tipo, recursoID,
+	)
+
+	c, err := scanCase(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("moderation case not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetPendingCases devuelve la cola de revisión, de lo más antiguo a lo más
+// reciente. Si tipo no está vacío solo incluye casos de ese tipo.
+func (s *Store) GetPendingCases(tipo string, afterFecha time.Time, afterID string, limit int) ([]types.ModerationCase, error) {
+	rows, err := s.db.Query(`
+        SELECT `+caseColumns+`
+        FROM moderacion_casos
+        WHERE estado = 'pendiente' AND (? = '' OR tipo = ?)
+            AND (fecha_creacion > ? OR (fecha_creacion = ? AND id > ?))
+        ORDER BY fecha_creacion, id
+        LIMIT ?
+    `, tipo, tipo, afterFecha, afterFecha, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	cases := []types.ModerationCase{}
+	for rows.Next() {
+		c, err := scanCase(rows)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, *c)
+	}
+
+	return cases, rows.Err()
+}
+
+// ResolveCase aprueba o rechaza un caso pendiente. Si otra revisión o un
+// cambio del autor lo cerró antes devuelve "moderation case already resolved".
+func (s *Store) ResolveCase(id string, estado string, revisor string) error {
+	result, err := s.db.Exec(`
+        UPDATE moderacion_casos
+        SET estado = ?, revisado_por = ?, fecha_revision = CURRENT_TIMESTAMP
+        WHERE id = ? AND estado = 'pendiente'
+    `, estado, revisor, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("moderation case already resolved")
+	}
+
+	return nil
+}
+
+// scanCase convierte una fila en un caso
+func scanCase(row interface{ Scan(...any) error }) (*types.ModerationCase, error) {
+	c := new(types.ModerationCase)
+	var hallazgos []byte
+	var revisadoPor sql.NullString
+	var fechaRevision sql.NullTime
+
+	err := row.Scan(
+		&c.ID, &c.Tipo, &c.RecursoID, &c.AutorApodo, &c.Contenido, &c.Decision, &hallazgos,
+		&c.Publicar, &c.Estado, &c.FechaCreacion, &revisadoPor, &fechaRevision,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(hallazgos, &c.Hallazgos); err != nil {
+		return nil, err
+	}
+	if revisadoPor.Valid {
+		c.RevisadoPor = &revisadoPor.String
+	}
+	if fechaRevision.Valid {
+		c.FechaRevision = &fechaRevision.Time
+	}
+
+	return c, nil
+}
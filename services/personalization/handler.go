@@ -7,21 +7,24 @@ import (
 	"github.com/gorilla/mux"
 	"gitlab.com/pardalis/pardalis-api/configs"
 	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/services/moderation"
 	"gitlab.com/pardalis/pardalis-api/types"
 	"gitlab.com/pardalis/pardalis-api/utils"
 )
 
 // Handler maneja las rutas relacionadas con la personalización
 type Handler struct {
-	store     types.PersonalizationStore
-	userStore types.UserStore
+	store      types.PersonalizationStore
+	userStore  types.UserStore
+	moderation *moderation.Pipeline
 }
 
 // NewHandler crea una nueva instancia de Handler
-func NewHandler(store types.PersonalizationStore, userStore types.UserStore) *Handler {
+func NewHandler(store types.PersonalizationStore, userStore types.UserStore, pipeline *moderation.Pipeline) *Handler {
 	return &Handler{
-		store:     store,
-		userStore: userStore,
+		store:      store,
+		userStore:  userStore,
+		moderation: pipeline,
 	}
 }
 
@@ -66,10 +69,12 @@ func (h *Handler) handleGetPersonalization(w http.ResponseWriter, r *http.Reques
 	}
 
 	response := p.ToResponse()
+	w.Header().Set("ETag", utils.VersionETag(p.Version))
 	utils.WriteJSON(w, http.StatusOK, response)
 }
 
-// handleUpdatePersonalization maneja la actualización de la personalización de un usuario
+// handleUpdatePersonalization maneja la actualización de la personalización de un usuario.
+// Crearla no tiene condiciones; modificarla exige If-Match con la versión actual.
 func (h *Handler) handleUpdatePersonalization(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userApodo := vars["userApodo"]
@@ -105,21 +110,30 @@ func (h *Handler) handleUpdatePersonalization(w http.ResponseWriter, r *http.Req
 	p.Apodo = userApodo
 
 	// Verificar que el usuario existe
-	_, err = h.userStore.GetUserByApodo(userApodo)
+	user, err := h.userStore.GetUserByApodo(userApodo)
 	if err != nil {
 		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("user not found"))
 		return
 	}
 
-	// Intentar actualizar, si no existe crear uno nuevo
-	err = h.store.UpdatePersonalization(p)
+	// Si no existe se crea; si existe, solo sobre la versión que el cliente leyó
+	current, err := h.store.GetPersonalization(userApodo)
 	if err != nil {
 		if err.Error() == "personalization not found" {
+			moderated, ok := h.moderateDescription(w, user, &p, "")
+			if !ok {
+				return
+			}
 			err = h.store.CreatePersonalization(p)
 			if err != nil {
 				utils.WriteError(w, http.StatusInternalServerError, err)
 				return
 			}
+			if !h.recordModeration(w, user, moderated) {
+				return
+			}
+			p.Version = 1
+			w.Header().Set("ETag", utils.VersionETag(p.Version))
 			utils.WriteJSON(w, http.StatusCreated, p.ToResponse())
 			return
 		}
@@ -127,5 +141,34 @@ func (h *Handler) handleUpdatePersonalization(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if !utils.CheckIfMatch(w, r, current.Version) {
+		return
+	}
+
+	moderated, ok := h.moderateDescription(w, user, &p, current.Descripcion)
+	if !ok {
+		return
+	}
+
+	p.Version = current.Version
+	err = h.store.UpdatePersonalization(p)
+	if err != nil {
+		if err.Error() == "version conflict" {
+			// Otra petición guardó entre la lectura y la escritura
+			if latest, err := h.store.GetPersonalization(userApodo); err == nil {
+				utils.WriteVersionConflict(w, latest.Version)
+				return
+			}
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	p.Version++
+
+	if !h.recordModeration(w, user, moderated) {
+		return
+	}
+
+	w.Header().Set("ETag", utils.VersionETag(p.Version))
 	utils.WriteJSON(w, http.StatusOK, p.ToResponse())
 }
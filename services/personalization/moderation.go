@@ -0,0 +1,59 @@
+package personalization
+
+import (
+	"net/http"
+
+	"gitlab.com/pardalis/pardalis-api/services/moderation"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// moderatedDescription es el resultado de moderar una descripción nueva.
+// Descripcion es el texto que escribió el usuario, se haya aplicado o no.
+type moderatedDescription struct {
+	result      types.ModerationResult
+	descripcion string
+}
+
+// moderateDescription modera la descripción de p si cambia respecto a
+// anterior. Si se rechaza responde 422 y devuelve false. Si se retiene, p
+// conserva la anterior hasta que un administrador la apruebe. Devuelve nil si
+// la descripción no cambia.
+func (h *Handler) moderateDescription(w http.ResponseWriter, user *types.User, p *types.Personalization, anterior string) (*moderatedDescription, bool) {
+	if p.Descripcion == anterior {
+		return nil, true
+	}
+
+	result, err := h.moderation.Check(types.ContenidoDescripcion, user, p.Descripcion)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if result.Decision == types.ModeracionRechazar {
+		moderation.WriteRejection(w, result)
+		return nil, false
+	}
+
+	m := &moderatedDescription{result: result, descripcion: p.Descripcion}
+	if result.Decision == types.ModeracionRetener {
+		p.Descripcion = anterior
+	}
+	return m, true
+}
+
+// recordModeration deja el caso en la cola de revisión una vez guardada la
+// personalización. Si falla responde 500 y devuelve false.
+func (h *Handler) recordModeration(w http.ResponseWriter, user *types.User, m *moderatedDescription) bool {
+	if m == nil {
+		return true
+	}
+
+	err := h.moderation.Record(types.ContenidoDescripcion, user.Apodo, user, m.descripcion, m.result, false)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	moderation.SetDecisionHeader(w, m.result)
+	return true
+}
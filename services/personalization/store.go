@@ -16,13 +16,17 @@ func NewStore(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-// GetPersonalization obtiene la personalización de un usuario por su apodo
+// GetPersonalization obtiene la personalización de un usuario por su apodo. La
+// de un usuario en la papelera no se encuentra.
 func (s *Store) GetPersonalization(apodo string) (*types.Personalization, error) {
 	p := new(types.Personalization)
-	err := s.db.QueryRow(
-		"SELECT apodo, descripcion, foto, fecha_actualizacion FROM personalizacion WHERE apodo = ?",
+	err := s.db.QueryRow(`
+        SELECT p.apodo, p.descripcion, p.foto, p.fecha_actualizacion, p.version
+        FROM personalizacion p
+        JOIN usuarios u ON u.apodo = p.apodo
+        WHERE p.apodo = ? AND u.eliminado_en IS NULL`,
 		apodo,
-	).Scan(&p.Apodo, &p.Descripcion, &p.Foto, &p.FechaActualizacion)
+	).Scan(&p.Apodo, &p.Descripcion, &p.Foto, &p.FechaActualizacion, &p.Version)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("personalization not found")
@@ -43,11 +47,12 @@ func (s *Store) CreatePersonalization(p types.Personalization) error {
 	return err
 }
 
-// UpdatePersonalization actualiza una personalización existente
+// UpdatePersonalization actualiza una personalización existente si sigue en
+// p.Version. Si otra petición la cambió antes devuelve "version conflict".
 func (s *Store) UpdatePersonalization(p types.Personalization) error {
 	result, err := s.db.Exec(
-		"UPDATE personalizacion SET descripcion = ?, foto = ? WHERE apodo = ?",
-		p.Descripcion, p.Foto, p.Apodo,
+		"UPDATE personalizacion SET descripcion = ?, foto = ?, version = version + 1 WHERE apodo = ? AND version = ?",
+		p.Descripcion, p.Foto, p.Apodo, p.Version,
 	)
 	if err != nil {
 		return err
@@ -58,6 +63,14 @@ func (s *Store) UpdatePersonalization(p types.Personalization) error {
 		return err
 	}
 	if rows == 0 {
+		var exists bool
+		err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM personalizacion WHERE apodo = ?)", p.Apodo).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("version conflict")
+		}
 		return fmt.Errorf("personalization not found")
 	}
 
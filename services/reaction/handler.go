@@ -0,0 +1,111 @@
+package reaction
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Handler maneja las reacciones de los lectores
+type Handler struct {
+	store     types.ReactionStore
+	blogStore types.BlogStore
+	userStore types.UserStore
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.ReactionStore, blogStore types.BlogStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:     store,
+		blogStore: blogStore,
+		userStore: userStore,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/blogs/{id}/reactions", auth.WithJWTAuth(h.handleGetReactions, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/blogs/{id}/reactions/{tipo}", auth.WithJWTAuth(h.handleAddReaction, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/blogs/{id}/reactions/{tipo}", auth.WithJWTAuth(h.handleRemoveReaction, h.userStore)).Methods(http.MethodDelete)
+}
+
+func (h *Handler) handleGetReactions(w http.ResponseWriter, r *http.Request) {
+	h.writeSummary(w, mux.Vars(r)["id"], auth.GetUserApodoFromContext(r.Context()))
+}
+
+func (h *Handler) handleAddReaction(w http.ResponseWriter, r *http.Request) {
+	blogID, tipo, apodo, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.AddReaction(blogID, apodo, tipo); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeSummary(w, blogID, apodo)
+}
+
+func (h *Handler) handleRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	blogID, tipo, apodo, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveReaction(blogID, apodo, tipo); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeSummary(w, blogID, apodo)
+}
+
+// parseRequest valida el tipo de reacción y que el blog esté publicado
+func (h *Handler) parseRequest(w http.ResponseWriter, r *http.Request) (string, string, string, bool) {
+	vars := mux.Vars(r)
+	blogID, tipo := vars["id"], vars["tipo"]
+
+	apodo := auth.GetUserApodoFromContext(r.Context())
+	if apodo == "" {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return "", "", "", false
+	}
+
+	if _, ok := counterColumns[tipo]; !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid reaction"))
+		return "", "", "", false
+	}
+
+	blog, err := h.blogStore.GetBlogByID(blogID)
+	if err != nil || blog.Estado != "publicado" {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("blog not found"))
+		return "", "", "", false
+	}
+
+	return blogID, tipo, apodo, true
+}
+
+// writeSummary responde con los totales del blog y las reacciones del usuario
+func (h *Handler) writeSummary(w http.ResponseWriter, blogID string, apodo string) {
+	counts, err := h.store.GetReactionCounts(blogID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	mias, err := h.store.GetUserReactions(blogID, apodo)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, types.ReactionSummary{Reacciones: counts, Mias: mias})
+	if err != nil {
+		return
+	}
+}
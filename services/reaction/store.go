@@ -0,0 +1,156 @@
+// Package reaction implementa las reacciones de los lectores a los blogs.
+package reaction
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// counterColumns relaciona cada tipo de reacción con su columna en blog_contadores.
+// También sirve de lista blanca: ningún otro valor llega a la consulta.
+var counterColumns = map[string]string{
+	types.ReaccionLike:    "likes",
+	types.ReaccionUtil:    "utiles",
+	types.ReaccionConfuso: "confusos",
+}
+
+// Store implementa ReactionStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// AddReaction registra la reacción del usuario e incrementa el total del blog.
+// El total solo cambia si la fila se insertó, así que repetir la petición o
+// recibir dos peticiones simultáneas no cuenta la reacción dos veces.
+func (s *Store) AddReaction(blogID string, apodo string, tipo string) error {
+	column, ok := counterColumns[tipo]
+	if !ok {
+		return fmt.Errorf("invalid reaction")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(
+		"INSERT IGNORE INTO blog_reacciones (blog_id, apodo, tipo) VALUES (?, ?, ?)",
+		blogID, apodo, tipo,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if inserted == 1 {
+		_, err = tx.Exec(
+			"INSERT INTO blog_contadores (blog_id, "+column+") VALUES (?, 1) "+
+				"ON DUPLICATE KEY UPDATE "+column+" = "+column+" + 1",
+			blogID,
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RemoveReaction elimina la reacción del usuario y decrementa el total del blog
+func (s *Store) RemoveReaction(blogID string, apodo string, tipo string) error {
+	column, ok := counterColumns[tipo]
+	if !ok {
+		return fmt.Errorf("invalid reaction")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(
+		"DELETE FROM blog_reacciones WHERE blog_id = ? AND apodo = ? AND tipo = ?",
+		blogID, apodo, tipo,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if deleted == 1 {
+		_, err = tx.Exec(
+			"UPDATE blog_contadores SET "+column+" = "+column+" - 1 WHERE blog_id = ? AND "+column+" > 0",
+			blogID,
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetReactionCounts devuelve los totales de reacciones de un blog
+func (s *Store) GetReactionCounts(blogID string) (types.ReactionCounts, error) {
+	var counts types.ReactionCounts
+	err := s.db.QueryRow(
+		"SELECT likes, utiles, confusos FROM blog_contadores WHERE blog_id = ?",
+		blogID,
+	).Scan(&counts.Like, &counts.Util, &counts.Confuso)
+	if errors.Is(err, sql.ErrNoRows) {
+		return counts, nil
+	}
+
+	return counts, err
+}
+
+// GetUserReactions devuelve los tipos de reacción que el usuario dejó en un blog
+func (s *Store) GetUserReactions(blogID string, apodo string) ([]string, error) {
+	rows, err := s.db.Query(
+		"SELECT tipo FROM blog_reacciones WHERE blog_id = ? AND apodo = ? ORDER BY tipo",
+		blogID, apodo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	tipos := []string{}
+	for rows.Next() {
+		var tipo string
+		if err := rows.Scan(&tipo); err != nil {
+			return nil, err
+		}
+		tipos = append(tipos, tipo)
+	}
+
+	return tipos, rows.Err()
+}
@@ -0,0 +1,385 @@
+package readinglist
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Handler maneja los marcadores y las listas de lectura
+type Handler struct {
+	store     types.ReadingListStore
+	blogStore types.BlogStore
+	userStore types.UserStore
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.ReadingListStore, blogStore types.BlogStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:     store,
+		blogStore: blogStore,
+		userStore: userStore,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/bookmarks", auth.WithJWTAuth(h.handleGetBookmarks, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/blogs/{id}/bookmark", auth.WithJWTAuth(h.handleAddBookmark, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/blogs/{id}/bookmark", auth.WithJWTAuth(h.handleRemoveBookmark, h.userStore)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/reading-lists", auth.WithJWTAuth(h.handleGetReadingLists, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/reading-lists", auth.WithJWTAuth(h.handleCreateReadingList, h.userStore)).Methods(http.MethodPost)
+	// Las listas públicas se comparten por enlace, así que esta ruta no exige sesión
+	router.HandleFunc("/reading-lists/{id}", h.handleGetReadingList).Methods(http.MethodGet)
+	router.HandleFunc("/reading-lists/{id}", auth.WithJWTAuth(h.handleUpdateReadingList, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/reading-lists/{id}", auth.WithJWTAuth(h.handleDeleteReadingList, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/reading-lists/{id}/items", auth.WithJWTAuth(h.handleAddItem, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/reading-lists/{id}/items/{blogId}", auth.WithJWTAuth(h.handleRemoveItem, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/reading-lists/{id}/order", auth.WithJWTAuth(h.handleReorder, h.userStore)).Methods(http.MethodPut)
+}
+
+// handleGetBookmarks lista los marcadores del usuario, del más reciente al más antiguo
+func (h *Handler) handleGetBookmarks(w http.ResponseWriter, r *http.Request) {
+	pagination, err := utils.ParsePagination(r, 20, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apodo := auth.GetUserApodoFromContext(r.Context())
+	bookmarks, err := h.store.GetBookmarks(apodo, pagination.After.Fecha, pagination.After.ID, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WritePage(w, r, utils.NewPage(bookmarks, pagination.Limit, bookmarkCursor))
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleAddBookmark(w http.ResponseWriter, r *http.Request) {
+	blogID := mux.Vars(r)["id"]
+	if !h.requirePublishedBlog(w, blogID) {
+		return
+	}
+
+	if err := h.store.AddBookmark(auth.GetUserApodoFromContext(r.Context()), blogID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Blog bookmarked successfully"})
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleRemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.RemoveBookmark(auth.GetUserApodoFromContext(r.Context()), mux.Vars(r)["id"]); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Bookmark removed successfully"})
+	if err != nil {
+		return
+	}
+}
+
+// handleGetReadingLists lista las listas de lectura del usuario, de la más reciente a la más antigua
+func (h *Handler) handleGetReadingLists(w http.ResponseWriter, r *http.Request) {
+	pagination, err := utils.ParsePagination(r, 20, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	apodo := auth.GetUserApodoFromContext(r.Context())
+	lists, err := h.store.GetReadingLists(apodo, pagination.After.Fecha, pagination.After.ID, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WritePage(w, r, utils.NewPage(lists, pagination.Limit, readingListCursor))
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleCreateReadingList(w http.ResponseWriter, r *http.Request) {
+	var payload types.CreateReadingListPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	list := types.ReadingList{
+		ID:          uuid.New().String(),
+		Apodo:       auth.GetUserApodoFromContext(r.Context()),
+		Nombre:      strings.TrimSpace(payload.Nombre),
+		Descripcion: payload.Descripcion,
+		Publica:     payload.Publica,
+	}
+
+	if err := h.store.CreateReadingList(list); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	created, err := h.store.GetReadingListByID(list.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusCreated, created)
+	if err != nil {
+		return
+	}
+}
+
+// handleGetReadingList devuelve una lista con sus blogs. Las listas privadas
+// solo las ve su dueño.
+func (h *Handler) handleGetReadingList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.GetReadingListByID(mux.Vars(r)["id"])
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if !list.Publica && requestApodo(r) != list.Apodo {
+		// Se responde 404 para no revelar que la lista existe
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("reading list not found"))
+		return
+	}
+
+	list.Blogs, err = h.store.GetReadingListItems(list.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, list)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleUpdateReadingList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.getOwnList(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.UpdateReadingListPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if nombre := strings.TrimSpace(payload.Nombre); nombre != "" {
+		list.Nombre = nombre
+	}
+	if payload.Descripcion != nil {
+		list.Descripcion = *payload.Descripcion
+	}
+	if payload.Publica != nil {
+		list.Publica = *payload.Publica
+	}
+
+	if err := h.store.UpdateReadingList(*list); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusOK, list)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleDeleteReadingList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.getOwnList(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteReadingList(list.ID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Reading list deleted successfully"})
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleAddItem(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.getOwnList(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.AddReadingListItemPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !h.requirePublishedBlog(w, payload.BlogID) {
+		return
+	}
+
+	if err := h.store.AddReadingListItem(list.ID, payload.BlogID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeList(w, list)
+}
+
+func (h *Handler) handleRemoveItem(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.getOwnList(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveReadingListItem(list.ID, mux.Vars(r)["blogId"]); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.writeList(w, list)
+}
+
+func (h *Handler) handleReorder(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.getOwnList(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.ReorderReadingListPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.store.ReorderReadingList(list.ID, payload.BlogIDs); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.writeList(w, list)
+}
+
+// getOwnList obtiene la lista de la ruta y comprueba que pertenece al usuario autenticado
+func (h *Handler) getOwnList(w http.ResponseWriter, r *http.Request) (*types.ReadingList, bool) {
+	list, err := h.store.GetReadingListByID(mux.Vars(r)["id"])
+	if err != nil {
+		writeStoreError(w, err)
+		return nil, false
+	}
+
+	if list.Apodo != auth.GetUserApodoFromContext(r.Context()) {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to modify this reading list"))
+		return nil, false
+	}
+
+	return list, true
+}
+
+// writeList responde con la lista actualizada y sus blogs
+func (h *Handler) writeList(w http.ResponseWriter, list *types.ReadingList) {
+	blogs, err := h.store.GetReadingListItems(list.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	list.Blogs = blogs
+	list.TotalBlogs = len(blogs)
+
+	err = utils.WriteJSON(w, http.StatusOK, list)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) requirePublishedBlog(w http.ResponseWriter, blogID string) bool {
+	blog, err := h.blogStore.GetBlogByID(blogID)
+	if err != nil || blog.Estado != "publicado" {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("blog not found"))
+		return false
+	}
+	return true
+}
+
+// requestApodo devuelve el usuario del token de la petición, o "" si no hay un token válido
+func requestApodo(r *http.Request) string {
+	tokenString := utils.GetTokenFromRequest(r)
+	if tokenString == "" {
+		return ""
+	}
+
+	claims, err := auth.VerifyJWT(tokenString, []byte(configs.Envs.JWTSecret))
+	if err != nil {
+		return ""
+	}
+
+	apodo, _ := claims["userApodo"].(string)
+	return apodo
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "reading list not found", "blog not in reading list":
+		utils.WriteError(w, http.StatusNotFound, err)
+	case "order must contain every blog in the list exactly once":
+		utils.WriteError(w, http.StatusBadRequest, err)
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// bookmarkCursor devuelve la posición de un marcador, que se ordena por fecha
+// de guardado e id del blog
+func bookmarkCursor(b types.Bookmark) types.Cursor {
+	return types.Cursor{Fecha: b.FechaGuardado, ID: b.ID}
+}
+
+// readingListCursor devuelve la posición de una lista, que se ordena por fecha
+// de creación e id
+func readingListCursor(l types.ReadingList) types.Cursor {
+	return types.Cursor{Fecha: l.FechaCreacion, ID: l.ID}
+}
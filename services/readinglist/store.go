@@ -0,0 +1,325 @@
+// Package readinglist implementa los marcadores y las listas de lectura de los usuarios.
+package readinglist
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// blogSummaryColumns son las columnas de blog que queryBlogs espera, en orden
+const blogSummaryColumns = `
+	b.id, b.titulo, b.slug, b.extracto, b.imagen_portada, b.fecha_publicacion,
+	COALESCE(cat.nombre, b.categoria), COALESCE(b.categoria_id, ''), COALESCE(cat.slug, ''),
+	b.tiempo_lectura, b.autor_apodo, b.fecha_actualizacion
+`
+
+// Store implementa ReadingListStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// AddBookmark guarda un blog en los marcadores del usuario. Guardarlo dos veces no es un error.
+func (s *Store) AddBookmark(apodo string, blogID string) error {
+	_, err := s.db.Exec("INSERT IGNORE INTO marcadores (apodo, blog_id) VALUES (?, ?)", apodo, blogID)
+	return err
+}
+
+// RemoveBookmark quita un blog de los marcadores del usuario
+func (s *Store) RemoveBookmark(apodo string, blogID string) error {
+	_, err := s.db.Exec("DELETE FROM marcadores WHERE apodo = ? AND blog_id = ?", apodo, blogID)
+	return err
+}
+
+// GetBookmarks devuelve los blogs publicados guardados por el usuario posteriores
+// al cursor, del guardado más reciente al más antiguo
+func (s *Store) GetBookmarks(apodo string, afterFecha time.Time, afterID string, limit int) ([]types.Bookmark, error) {
+	rows, err := s.db.Query(`
+        SELECT `+blogSummaryColumns+`, m.fecha_creacion
+        FROM marcadores m
+        JOIN blogs b ON b.id = m.blog_id
+        LEFT JOIN categorias cat ON cat.id = b.categoria_id
+        WHERE m.apodo = ? AND b.estado = 'publicado' AND b.eliminado_en IS NULL
+            AND (? = '' OR (m.fecha_creacion, b.id) < (?, ?))
+        ORDER BY m.fecha_creacion DESC, b.id DESC
+        LIMIT ?
+    `, apodo, afterID, afterFecha, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	bookmarks := []types.Bookmark{}
+	for rows.Next() {
+		var bookmark types.Bookmark
+		if err := scanBlogSummary(rows, &bookmark.Blog, &bookmark.FechaGuardado); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+
+	return bookmarks, rows.Err()
+}
+
+// CreateReadingList guarda una lista de lectura nueva
+func (s *Store) CreateReadingList(list types.ReadingList) error {
+	_, err := s.db.Exec(
+		"INSERT INTO listas_lectura (id, apodo, nombre, descripcion, publica) VALUES (?, ?, ?, ?, ?)",
+		list.ID, list.Apodo, list.Nombre, list.Descripcion, list.Publica,
+	)
+	return err
+}
+
+// GetReadingListByID obtiene una lista de lectura sin sus blogs
+func (s *Store) GetReadingListByID(id string) (*types.ReadingList, error) {
+	row := s.db.QueryRow(`
+        SELECT l.id, l.apodo, l.nombre, l.descripcion, l.publica,
+            l.fecha_creacion, l.fecha_actualizacion,
+            (SELECT COUNT(*) FROM listas_lectura_blogs lb WHERE lb.lista_id = l.id)
+        FROM listas_lectura l
+        JOIN usuarios u ON u.apodo = l.apodo
+        WHERE l.id = ? AND u.eliminado_en IS NULL
+    `, id)
+
+	list, err := scanReadingList(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("reading list not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetReadingLists devuelve las listas de lectura de un usuario posteriores al
+// cursor, de la más reciente a la más antigua
+func (s *Store) GetReadingLists(apodo string, afterFecha time.Time, afterID string, limit int) ([]types.ReadingList, error) {
+	rows, err := s.db.Query(`
+        SELECT l.id, l.apodo, l.nombre, l.descripcion, l.publica,
+            l.fecha_creacion, l.fecha_actualizacion,
+            COUNT(lb.blog_id)
+        FROM listas_lectura l
+        JOIN usuarios u ON u.apodo = l.apodo
+        LEFT JOIN listas_lectura_blogs lb ON lb.lista_id = l.id
+        WHERE l.apodo = ? AND u.eliminado_en IS NULL
+            AND (? = '' OR (l.fecha_creacion, l.id) < (?, ?))
+        GROUP BY l.id
+        ORDER BY l.fecha_creacion DESC, l.id DESC
+        LIMIT ?
+    `, apodo, afterID, afterFecha, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	lists := []types.ReadingList{}
+	for rows.Next() {
+		list, err := scanReadingList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, *list)
+	}
+
+	return lists, rows.Err()
+}
+
+// UpdateReadingList actualiza el nombre, la descripción y la visibilidad de una lista
+func (s *Store) UpdateReadingList(list types.ReadingList) error {
+	_, err := s.db.Exec(
+		"UPDATE listas_lectura SET nombre = ?, descripcion = ?, publica = ? WHERE id = ?",
+		list.Nombre, list.Descripcion, list.Publica, list.ID,
+	)
+	return err
+}
+
+// DeleteReadingList elimina una lista; sus entradas se borran en cascada
+func (s *Store) DeleteReadingList(id string) error {
+	_, err := s.db.Exec("DELETE FROM listas_lectura WHERE id = ?", id)
+	return err
+}
+
+// GetReadingListItems devuelve los blogs publicados de una lista en su orden
+func (s *Store) GetReadingListItems(listID string) ([]types.Blog, error) {
+	return s.queryBlogs(`
+        SELECT `+blogSummaryColumns+`
+        FROM listas_lectura_blogs lb
+        JOIN blogs b ON b.id = lb.blog_id
+        LEFT JOIN categorias cat ON cat.id = b.categoria_id
+        WHERE lb.lista_id = ? AND b.estado = 'publicado' AND b.eliminado_en IS NULL
+        ORDER BY lb.posicion
+    `, listID)
+}
+
+// AddReadingListItem añade un blog al final de una lista. Si ya estaba, no cambia su posición.
+func (s *Store) AddReadingListItem(listID string, blogID string) error {
+	_, err := s.db.Exec(`
+        INSERT IGNORE INTO listas_lectura_blogs (lista_id, blog_id, posicion)
+        SELECT ?, ?, COALESCE(MAX(posicion), 0) + 1
+        FROM listas_lectura_blogs
+        WHERE lista_id = ?
+    `, listID, blogID, listID)
+	return err
+}
+
+// RemoveReadingListItem quita un blog de una lista
+func (s *Store) RemoveReadingListItem(listID string, blogID string) error {
+	result, err := s.db.Exec("DELETE FROM listas_lectura_blogs WHERE lista_id = ? AND blog_id = ?", listID, blogID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("blog not in reading list")
+	}
+
+	return nil
+}
+
+// ReorderReadingList asigna las posiciones de una lista según el orden de blogIDs,
+// que debe contener exactamente los blogs de la lista. Las entradas se bloquean
+// durante la transacción para que un alta simultánea no quede sin posición.
+func (s *Store) ReorderReadingList(listID string, blogIDs []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT blog_id FROM listas_lectura_blogs WHERE lista_id = ? FOR UPDATE", listID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	var current []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			_ = tx.Rollback()
+			return err
+		}
+		current = append(current, id)
+	}
+	if err := rows.Close(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if !isPermutation(current, blogIDs) {
+		_ = tx.Rollback()
+		return fmt.Errorf("order must contain every blog in the list exactly once")
+	}
+
+	for i, blogID := range blogIDs {
+		_, err := tx.Exec(
+			"UPDATE listas_lectura_blogs SET posicion = ? WHERE lista_id = ? AND blog_id = ?",
+			i+1, listID, blogID,
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// isPermutation indica si requested contiene exactamente los mismos ids que current, sin repetir
+func isPermutation(current []string, requested []string) bool {
+	if len(current) != len(requested) {
+		return false
+	}
+
+	pending := make(map[string]bool, len(current))
+	for _, id := range current {
+		pending[id] = true
+	}
+	for _, id := range requested {
+		if !pending[id] {
+			return false
+		}
+		delete(pending, id)
+	}
+
+	return true
+}
+
+func (s *Store) queryBlogs(query string, args ...interface{}) ([]types.Blog, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	blogs := []types.Blog{}
+	for rows.Next() {
+		var blog types.Blog
+		if err := scanBlogSummary(rows, &blog); err != nil {
+			return nil, err
+		}
+		blogs = append(blogs, blog)
+	}
+
+	return blogs, rows.Err()
+}
+
+// scanBlogSummary lee las columnas de blogSummaryColumns y, detrás, las de extra
+func scanBlogSummary(rows *sql.Rows, blog *types.Blog, extra ...any) error {
+	dest := []any{
+		&blog.ID, &blog.Titulo, &blog.Slug, &blog.Extracto, &blog.ImagenPortada, &blog.FechaPublicacion,
+		&blog.Categoria, &blog.CategoriaID, &blog.CategoriaSlug, &blog.TiempoLectura, &blog.AutorApodo, &blog.FechaActualizacion,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	blog.Estado = "publicado"
+	return nil
+}
+
+// scanReadingList convierte una fila en una lista de lectura
+func scanReadingList(row interface{ Scan(...any) error }) (*types.ReadingList, error) {
+	list := new(types.ReadingList)
+	var descripcion sql.NullString
+
+	err := row.Scan(
+		&list.ID, &list.Apodo, &list.Nombre, &descripcion, &list.Publica,
+		&list.FechaCreacion, &list.FechaActualizacion, &list.TotalBlogs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	list.Descripcion = descripcion.String
+
+	return list, nil
+}
@@ -0,0 +1,27 @@
+package readinglist
+
+import "testing"
+
+func TestIsPermutation(t *testing.T) {
+	current := []string{"a", "b", "c"}
+
+	tests := []struct {
+		name      string
+		requested []string
+		want      bool
+	}{
+		{"Mismo orden", []string{"a", "b", "c"}, true},
+		{"Orden nuevo", []string{"c", "a", "b"}, true},
+		{"Falta un blog", []string{"a", "b"}, false},
+		{"Blog repetido", []string{"a", "a", "b"}, false},
+		{"Blog ajeno", []string{"a", "b", "d"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPermutation(current, tt.requested); got != tt.want {
+				t.Errorf("isPermutation(%v) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
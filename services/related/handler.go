@@ -0,0 +1,100 @@
+package related
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+const (
+	// maxRelated es el número de recomendaciones que se calculan y guardan por blog
+	maxRelated = 20
+
+	// maxCandidates limita los blogs que se puntúan por cada fuente de señales
+	maxCandidates = 100
+)
+
+// Handler sirve las recomendaciones de lectura
+type Handler struct {
+	store     types.RelatedStore
+	blogStore types.BlogStore
+	index     types.SimilarityIndex
+	cache     *Cache
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.RelatedStore, blogStore types.BlogStore, index types.SimilarityIndex) *Handler {
+	return &Handler{
+		store:     store,
+		blogStore: blogStore,
+		index:     index,
+		cache:     NewCache(CacheTTL),
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/blogs/{slug}/related", h.handleGetRelated).Methods(http.MethodGet)
+}
+
+// handleGetRelated devuelve los blogs recomendados tras leer uno, con ?limit= (5 por defecto)
+func (h *Handler) handleGetRelated(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > maxRelated {
+		limit = 5
+	}
+
+	blog, err := h.blogStore.GetBlogBySlug(mux.Vars(r)["slug"])
+	if err != nil {
+		if err.Error() == "blog not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	related, err := h.related(*blog)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, related)
+	if err != nil {
+		return
+	}
+}
+
+// related devuelve las recomendaciones de un blog, desde la caché si siguen valiendo
+func (h *Handler) related(blog types.Blog) ([]types.RelatedBlog, error) {
+	now := time.Now()
+	// La versión se lee antes de calcular: si el índice cambia mientras tanto,
+	// la entrada nace ya invalidada
+	version := h.index.Version()
+	if related, ok := h.cache.Get(blog.ID, version, now); ok {
+		return related, nil
+	}
+
+	candidates, err := h.store.GetRelatedCandidates(blog, maxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	similar, err := h.index.Similar(blog.ID, maxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	related := rank(candidates, similar, maxRelated)
+	h.cache.Set(blog.ID, version, now, related)
+
+	return related, nil
+}
@@ -0,0 +1,149 @@
+package related
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Pesos de cada señal en la puntuación de una recomendación
+const (
+	pesoContenido = 4.0 // Por la similitud del coseno, entre 0 y 1
+	pesoTag       = 1.0 // Por tag compartida, hasta maxTags
+	maxTags       = 3
+	pesoCategoria = 0.75
+	pesoSerie     = 2.0
+	pesoLectores  = 1.0 // Por log(1 + lectores en común)
+
+	// minSimilitud descarta los parecidos de contenido que solo comparten palabras sueltas
+	minSimilitud = 0.05
+)
+
+const (
+	// CacheTTL es el tiempo máximo que se reutilizan unas recomendaciones. Los
+	// cambios en los blogs las invalidan antes; las series y los lectores no.
+	CacheTTL = 15 * time.Minute
+
+	// maxCacheEntries limita la memoria de la caché
+	maxCacheEntries = 1000
+)
+
+// rank combina las señales de la base de datos con la similitud de contenido
+// y devuelve como mucho limit recomendaciones, de mayor a menor puntuación
+func rank(candidates []types.RelatedCandidate, similar []types.SearchResult, limit int) []types.RelatedBlog {
+	byID := map[string]*types.RelatedBlog{}
+	get := func(blog types.Blog) *types.RelatedBlog {
+		r, ok := byID[blog.ID]
+		if !ok {
+			r = &types.RelatedBlog{Blog: blog, Motivos: []string{}}
+			byID[blog.ID] = r
+		}
+		return r
+	}
+
+	for _, c := range candidates {
+		r := get(c.Blog)
+		if c.MismaSerie {
+			r.Puntuacion += pesoSerie
+			r.Motivos = append(r.Motivos, types.MotivoSerie)
+		}
+		if c.TagsCompartidas > 0 {
+			r.Puntuacion += pesoTag * float64(min(c.TagsCompartidas, maxTags))
+			r.Motivos = append(r.Motivos, types.MotivoTags)
+		}
+		if c.MismaCategoria {
+			r.Puntuacion += pesoCategoria
+			r.Motivos = append(r.Motivos, types.MotivoCategoria)
+		}
+		if c.Colectores > 0 {
+			r.Puntuacion += pesoLectores * math.Log1p(float64(c.Colectores))
+			r.Motivos = append(r.Motivos, types.MotivoLectores)
+		}
+	}
+
+	for _, s := range similar {
+		if s.Puntuacion < minSimilitud {
+			continue
+		}
+		r := get(s.Blog)
+		// El índice guarda el blog con sus tags, más completo que el de la consulta
+		r.Blog = s.Blog
+		r.Puntuacion += pesoContenido * s.Puntuacion
+		r.Motivos = append(r.Motivos, types.MotivoContenido)
+	}
+
+	related := make([]types.RelatedBlog, 0, len(byID))
+	for _, r := range byID {
+		if r.Puntuacion > 0 {
+			related = append(related, *r)
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Puntuacion != related[j].Puntuacion {
+			return related[i].Puntuacion > related[j].Puntuacion
+		}
+		return related[i].Blog.FechaPublicacion.After(related[j].Blog.FechaPublicacion)
+	})
+
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	return related
+}
+
+// Cache guarda las recomendaciones de cada blog junto con la versión del índice
+// con la que se calcularon. Es segura para uso concurrente.
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	version uint64
+	expires time.Time
+	related []types.RelatedBlog
+}
+
+// NewCache crea una caché vacía cuyas entradas caducan tras ttl
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+// Get devuelve las recomendaciones de blogID si se calcularon con la misma
+// versión del índice y no han caducado
+func (c *Cache) Get(blogID string, version uint64, now time.Time) ([]types.RelatedBlog, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[blogID]
+	if !ok || entry.version != version || now.After(entry.expires) {
+		return nil, false
+	}
+	return entry.related, true
+}
+
+// Set guarda las recomendaciones de blogID. Si la caché está llena se descartan
+// primero las entradas que ya no valen y, si no basta, todas.
+func (c *Cache) Set(blogID string, version uint64, now time.Time, related []types.RelatedBlog) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCacheEntries {
+		for id, entry := range c.entries {
+			if entry.version != version || now.After(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = map[string]cacheEntry{}
+		}
+	}
+
+	c.entries[blogID] = cacheEntry{version: version, expires: now.Add(c.ttl), related: related}
+}
@@ -0,0 +1,63 @@
+package related
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestRank(t *testing.T) {
+	candidates := []types.RelatedCandidate{
+		{Blog: types.Blog{ID: "serie"}, MismaSerie: true, MismaCategoria: true},
+		{Blog: types.Blog{ID: "tags"}, TagsCompartidas: 5},
+		{Blog: types.Blog{ID: "categoria"}, MismaCategoria: true},
+	}
+	similar := []types.SearchResult{
+		{Blog: types.Blog{ID: "tags", Tags: []string{"go"}}, Puntuacion: 0.5},
+		{Blog: types.Blog{ID: "ruido"}, Puntuacion: 0.01},
+	}
+
+	related := rank(candidates, similar, 10)
+
+	ids := make([]string, len(related))
+	for i, r := range related {
+		ids[i] = r.Blog.ID
+	}
+	if want := []string{"tags", "serie", "categoria"}; len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Fatalf("rank() = %v, want %v", ids, want)
+	}
+
+	top := related[0]
+	if top.Puntuacion != pesoTag*maxTags+pesoContenido*0.5 {
+		t.Errorf("rank() puntuacion = %v", top.Puntuacion)
+	}
+	if len(top.Motivos) != 2 || top.Motivos[0] != types.MotivoTags || top.Motivos[1] != types.MotivoContenido {
+		t.Errorf("rank() motivos = %v", top.Motivos)
+	}
+	if len(top.Blog.Tags) != 1 {
+		t.Errorf("rank() must prefer the indexed blog, got %+v", top.Blog)
+	}
+
+	if got := rank(candidates, similar, 1); len(got) != 1 {
+		t.Errorf("rank() with limit 1 = %d results", len(got))
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache(time.Minute)
+	now := time.Now()
+	related := []types.RelatedBlog{{Blog: types.Blog{ID: "b"}}}
+
+	cache.Set("a", 1, now, related)
+
+	if got, ok := cache.Get("a", 1, now.Add(30*time.Second)); !ok || len(got) != 1 {
+		t.Errorf("Get() = %v, %v, want cached entry", got, ok)
+	}
+	if _, ok := cache.Get("a", 2, now); ok {
+		t.Errorf("Get() returned an entry from an older index version")
+	}
+	if _, ok := cache.Get("a", 1, now.Add(2*time.Minute)); ok {
+		t.Errorf("Get() returned an expired entry")
+	}
+}
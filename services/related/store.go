@@ -0,0 +1,84 @@
+// Package related recomienda qué leer después de un blog.
+package related
+
+import (
+	"database/sql"
+	"log"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Store implementa RelatedStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// GetRelatedCandidates devuelve los blogs publicados que comparten tags,
+// categoría, serie o lectores recientes con blog, con el valor de cada señal.
+// Los lectores salen de blog_vistas_visitantes, que solo guarda la ventana de
+// deduplicación de visitas, así que reflejan lo que se lee ahora.
+func (s *Store) GetRelatedCandidates(blog types.Blog, limit int) ([]types.RelatedCandidate, error) {
+	rows, err := s.db.Query(`
+        SELECT b.id, b.titulo, b.slug, b.extracto, b.imagen_portada, b.fecha_publicacion,
+            COALESCE(cat.nombre, b.categoria), COALESCE(b.categoria_id, ''), COALESCE(cat.slug, ''),
+            b.tiempo_lectura, b.autor_apodo, b.fecha_actualizacion,
+            COALESCE(tc.compartidas, 0),
+            COALESCE(b.categoria_id = ?, FALSE),
+            sb.serie_id IS NOT NULL,
+            COALESCE(co.lectores, 0)
+        FROM blogs b
+        LEFT JOIN categorias cat ON cat.id = b.categoria_id
+        LEFT JOIN (
+            SELECT pt.blog_id, COUNT(*) AS compartidas
+            FROM blog_posts_tags pt
+            WHERE pt.tag_id IN (SELECT tag_id FROM blog_posts_tags WHERE blog_id = ?)
+            GROUP BY pt.blog_id
+        ) tc ON tc.blog_id = b.id
+        LEFT JOIN series_blogs sb ON sb.blog_id = b.id
+            AND sb.serie_id = (SELECT serie_id FROM series_blogs WHERE blog_id = ?)
+        LEFT JOIN (
+            SELECT v2.blog_id, COUNT(*) AS lectores
+            FROM blog_vistas_visitantes v1
+            JOIN blog_vistas_visitantes v2 ON v2.visitante = v1.visitante AND v2.blog_id <> v1.blog_id
+            WHERE v1.blog_id = ?
+            GROUP BY v2.blog_id
+        ) co ON co.blog_id = b.id
+        WHERE b.estado = 'publicado' AND b.eliminado_en IS NULL AND b.id <> ?
+            AND (tc.compartidas IS NOT NULL OR b.categoria_id = ? OR sb.serie_id IS NOT NULL OR co.lectores IS NOT NULL)
+        ORDER BY sb.serie_id IS NOT NULL DESC, COALESCE(tc.compartidas, 0) DESC, COALESCE(co.lectores, 0) DESC,
+            b.fecha_publicacion DESC
+        LIMIT ?
+    `, blog.CategoriaID, blog.ID, blog.ID, blog.ID, blog.ID, blog.CategoriaID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	candidates := []types.RelatedCandidate{}
+	for rows.Next() {
+		var c types.RelatedCandidate
+		err := rows.Scan(
+			&c.Blog.ID, &c.Blog.Titulo, &c.Blog.Slug, &c.Blog.Extracto, &c.Blog.ImagenPortada, &c.Blog.FechaPublicacion,
+			&c.Blog.Categoria, &c.Blog.CategoriaID, &c.Blog.CategoriaSlug,
+			&c.Blog.TiempoLectura, &c.Blog.AutorApodo, &c.Blog.FechaActualizacion,
+			&c.TagsCompartidas, &c.MismaCategoria, &c.MismaSerie, &c.Colectores,
+		)
+		if err != nil {
+			return nil, err
+		}
+		c.Blog.Estado = "publicado"
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
@@ -0,0 +1,138 @@
+// Package search implementa la búsqueda de texto completo sobre los blogs:
+// un analizador para español y un índice invertido embebido en memoria.
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// token es una palabra del texto original con su posición en bytes y su término normalizado
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+// stopwords son palabras demasiado frecuentes en español para aportar a la búsqueda
+var stopwords = map[string]bool{
+	"a": true, "al": true, "algo": true, "ante": true, "como": true, "con": true,
+	"de": true, "del": true, "desde": true, "donde": true, "e": true, "el": true,
+	"ella": true, "en": true, "entre": true, "era": true, "es": true, "esta": true,
+	"este": true, "esto": true, "fue": true, "ha": true, "hay": true, "la": true,
+	"las": true, "le": true, "les": true, "lo": true, "los": true, "mas": true,
+	"me": true, "mi": true, "muy": true, "ni": true, "no": true, "o": true,
+	"para": true, "pero": true, "por": true, "que": true, "se": true, "si": true,
+	"sin": true, "sobre": true, "son": true, "su": true, "sus": true, "tambien": true,
+	"te": true, "tu": true, "u": true, "un": true, "una": true, "uno": true,
+	"unos": true, "unas": true, "y": true, "ya": true, "yo": true,
+}
+
+// suffixes son las terminaciones derivativas que se recortan, de la más larga a la más corta
+var suffixes = []string{
+	"amientos", "imientos", "aciones", "uciones", "amiento", "imiento",
+	"idades", "mente", "acion", "ucion", "idad", "ismos", "ismo",
+}
+
+// tokenize divide el texto en palabras y devuelve sus términos normalizados,
+// descartando palabras vacías
+func tokenize(text string) []token {
+	var tokens []token
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = appendToken(tokens, text, start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = appendToken(tokens, text, start, len(text))
+	}
+
+	return tokens
+}
+
+func appendToken(tokens []token, text string, start, end int) []token {
+	term := analyze(text[start:end])
+	if term == "" {
+		return tokens
+	}
+	return append(tokens, token{term: term, start: start, end: end})
+}
+
+// analyze normaliza una palabra: minúsculas, sin acentos, sin palabras vacías y con raíz
+func analyze(word string) string {
+	folded := fold(word)
+	if folded == "" || stopwords[folded] {
+		return ""
+	}
+	return stem(folded)
+}
+
+// fold pasa a minúsculas y elimina los diacríticos: "Programación" -> "programacion"
+func fold(word string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(word)) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// stem es un lematizador ligero para español: elimina sufijos derivativos
+// comunes, plurales y la vocal final de género. "programaciones", "programación"
+// y "programacion" comparten la raíz "programacion"; "gatos" y "gata" comparten "gat".
+func stem(word string) string {
+	if utf8.RuneCountInString(word) <= 3 {
+		return word
+	}
+
+	derived := false
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			word = strings.TrimSuffix(word, suffix)
+			switch suffix {
+			case "aciones", "acion":
+				return word + "acion"
+			case "uciones", "ucion":
+				return word + "ucion"
+			}
+			derived = true
+			break
+		}
+	}
+
+	switch {
+	case derived:
+		// "rápidamente" -> "rapida" -> "rapid"
+	case strings.HasSuffix(word, "ces") && len(word) >= 5 && isVowel(word[len(word)-4]):
+		// "luces" -> "luz"
+		return strings.TrimSuffix(word, "ces") + "z"
+	case strings.HasSuffix(word, "es") && len(word) > 4 && !isVowel(word[len(word)-3]):
+		// "redes" -> "red", "ciudades" -> "ciudad"
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s") && len(word) > 3:
+		word = strings.TrimSuffix(word, "s")
+	}
+
+	if len(word) > 3 && (strings.HasSuffix(word, "a") || strings.HasSuffix(word, "o") || strings.HasSuffix(word, "e")) {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
@@ -0,0 +1,369 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitlab.com/pardalis/pardalis-api/services/content"
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Parámetros de BM25
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// snippetLength es el tamaño aproximado en bytes de los fragmentos de extracto y contenido
+const snippetLength = 200
+
+// fields son los campos indexados con su peso en la puntuación
+var fields = []struct {
+	name    string
+	boost   float64
+	snippet int // 0 = se devuelve el campo completo resaltado
+}{
+	{name: "titulo", boost: 3, snippet: 0},
+	{name: "tags", boost: 2, snippet: 0},
+	{name: "extracto", boost: 1.5, snippet: snippetLength},
+	{name: "contenido", boost: 1, snippet: snippetLength},
+}
+
+const numFields = 4
+
+// document es la copia de un blog que guarda el índice
+type document struct {
+	blog    types.Blog
+	texts   [numFields]string
+	lengths [numFields]int
+	terms   map[string]float64 // Frecuencia de cada término ponderada por el peso de su campo
+}
+
+// MemoryIndex es un índice invertido en memoria con puntuación BM25 por campos.
+// Es seguro para uso concurrente.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]*[numFields]int // término -> id de blog -> frecuencias por campo
+	totals   [numFields]int
+	version  uint64 // Aumenta con cada cambio, para invalidar cálculos derivados del índice
+}
+
+// NewMemoryIndex crea un índice vacío
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]*[numFields]int),
+	}
+}
+
+// Index añade o reemplaza un blog. Los blogs no publicados se eliminan del índice.
+func (idx *MemoryIndex) Index(blog types.Blog) error {
+	if blog.Estado != "publicado" {
+		return idx.Remove(blog.ID)
+	}
+
+	doc := &document{
+		texts: [numFields]string{
+			blog.Titulo,
+			strings.Join(blog.Tags, ", "),
+			blog.Extracto,
+			content.PlainText(blog.Contenido),
+		},
+		terms: map[string]float64{},
+	}
+
+	// El índice no necesita devolver el contenido completo en los resultados
+	blog.Contenido = ""
+	blog.ContenidoHTML = ""
+	blog.TablaContenidos = nil
+	blog.Bloques = nil
+	doc.blog = blog
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(blog.ID)
+
+	for f, text := range doc.texts {
+		tokens := tokenize(text)
+		doc.lengths[f] = len(tokens)
+		idx.totals[f] += len(tokens)
+
+		for _, tok := range tokens {
+			docs, ok := idx.postings[tok.term]
+			if !ok {
+				docs = make(map[string]*[numFields]int)
+				idx.postings[tok.term] = docs
+			}
+			freqs, ok := docs[blog.ID]
+			if !ok {
+				freqs = new([numFields]int)
+				docs[blog.ID] = freqs
+			}
+			freqs[f]++
+			doc.terms[tok.term] += fields[f].boost
+		}
+	}
+
+	idx.docs[blog.ID] = doc
+	idx.version++
+	return nil
+}
+
+// Remove elimina un blog del índice. No es un error eliminar un blog que no está.
+func (idx *MemoryIndex) Remove(id string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(id)
+	return nil
+}
+
+func (idx *MemoryIndex) removeLocked(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for f := range doc.texts {
+		idx.totals[f] -= doc.lengths[f]
+	}
+	for term := range doc.terms {
+		if docs, ok := idx.postings[term]; ok {
+			delete(docs, id)
+			if len(docs) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+
+	delete(idx.docs, id)
+	idx.version++
+}
+
+// Search devuelve los blogs que contienen alguno de los términos de la consulta
+// posteriores al cursor, ordenados por relevancia, con fragmentos resaltados de
+// los campos que coinciden
+func (idx *MemoryIndex) Search(query string, after types.Cursor, limit int) ([]types.SearchResult, error) {
+	terms := map[string]bool{}
+	for _, tok := range tokenize(query) {
+		terms[tok.term] = true
+	}
+
+	results := []types.SearchResult{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docs))
+	var avg [numFields]float64
+	for f := range fields {
+		if n > 0 {
+			avg[f] = math.Max(float64(idx.totals[f])/n, 1)
+		}
+	}
+
+	scores := map[string]float64{}
+	for term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for id, freqs := range docs {
+			doc := idx.docs[id]
+			for f, field := range fields {
+				tf := float64(freqs[f])
+				if tf == 0 {
+					continue
+				}
+				norm := 1 - b + b*float64(doc.lengths[f])/avg[f]
+				scores[id] += field.boost * idf * tf * (k1 + 1) / (tf + k1*norm)
+			}
+		}
+	}
+
+	for id, score := range scores {
+		doc := idx.docs[id]
+		fragments := map[string]string{}
+		for f, field := range fields {
+			if fragment, ok := highlight(doc.texts[f], terms, field.snippet); ok {
+				fragments[field.name] = fragment
+			}
+		}
+
+		results = append(results, types.SearchResult{
+			Blog:       doc.blog,
+			Puntuacion: score,
+			Fragmentos: fragments,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return ranksBefore(results[i], results[j])
+	})
+
+	if !after.IsZero() {
+		last := types.SearchResult{
+			Blog:       types.Blog{ID: after.ID, FechaPublicacion: after.Fecha},
+			Puntuacion: math.Float64frombits(uint64(after.Valor)),
+		}
+		start := sort.Search(len(results), func(i int) bool {
+			return ranksBefore(last, results[i])
+		})
+		results = results[start:]
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// Similar devuelve los blogs más parecidos al blog id según la similitud del
+// coseno de sus vectores TF-IDF, con la puntuación entre 0 y 1. Si el blog no
+// está en el índice no hay resultados.
+func (idx *MemoryIndex) Similar(id string, limit int) ([]types.SearchResult, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	results := []types.SearchResult{}
+	doc, ok := idx.docs[id]
+	if !ok {
+		return results, nil
+	}
+
+	n := float64(len(idx.docs))
+	idf := func(term string) float64 {
+		return math.Log(1 + n/float64(len(idx.postings[term])))
+	}
+	norm := func(d *document) float64 {
+		var sum float64
+		for term, tf := range d.terms {
+			w := tf * idf(term)
+			sum += w * w
+		}
+		return math.Sqrt(sum)
+	}
+
+	dots := map[string]float64{}
+	for term, tf := range doc.terms {
+		weight := idf(term)
+		for other := range idx.postings[term] {
+			if other != id {
+				dots[other] += tf * weight * idx.docs[other].terms[term] * weight
+			}
+		}
+	}
+
+	docNorm := norm(doc)
+	if docNorm == 0 {
+		return results, nil
+	}
+	for other, dot := range dots {
+		d := idx.docs[other]
+		if otherNorm := norm(d); otherNorm > 0 {
+			results = append(results, types.SearchResult{Blog: d.blog, Puntuacion: dot / (docNorm * otherNorm)})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return ranksBefore(results[i], results[j])
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// Version devuelve un número que cambia cada vez que se añade o elimina un blog
+func (idx *MemoryIndex) Version() uint64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.version
+}
+
+// highlight escapa el texto y envuelve en <mark> las palabras que coinciden con
+// algún término. Si maxLength > 0 devuelve solo una ventana alrededor de la
+// primera coincidencia. El segundo valor indica si hubo coincidencias.
+func highlight(text string, terms map[string]bool, maxLength int) (string, bool) {
+	tokens := tokenize(text)
+
+	first := -1
+	for i, tok := range tokens {
+		if terms[tok.term] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := 0, len(text)
+	if maxLength > 0 && len(text) > maxLength {
+		// Dejamos algo de contexto antes de la primera coincidencia,
+		// empezando y terminando siempre en un límite de palabra
+		start = tokens[first].start
+		for i := first; i >= 0 && tokens[first].start-tokens[i].start <= maxLength/4; i-- {
+			start = tokens[i].start
+		}
+		end = len(text)
+		for i := first; i < len(tokens); i++ {
+			if tokens[i].end-start > maxLength {
+				break
+			}
+			end = tokens[i].end
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+
+	pos := start
+	for _, tok := range tokens {
+		if tok.start < start || tok.end > end || !terms[tok.term] {
+			continue
+		}
+		sb.WriteString(html.EscapeString(text[pos:tok.start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(text[tok.start:tok.end]))
+		sb.WriteString("</mark>")
+		pos = tok.end
+	}
+	sb.WriteString(html.EscapeString(text[pos:end]))
+
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+
+	return strings.TrimSpace(sb.String()), true
+}
+
+// ranksBefore indica si a va antes que b en los resultados: más puntuación,
+// después más reciente y, para desempatar, por id
+func ranksBefore(a, b types.SearchResult) bool {
+	if a.Puntuacion != b.Puntuacion {
+		return a.Puntuacion > b.Puntuacion
+	}
+	if !a.Blog.FechaPublicacion.Equal(b.Blog.FechaPublicacion) {
+		return a.Blog.FechaPublicacion.After(b.Blog.FechaPublicacion)
+	}
+	return a.Blog.ID < b.Blog.ID
+}
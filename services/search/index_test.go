@@ -0,0 +1,243 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"programación", "programaciones"},
+		{"gatos", "gata"},
+		{"redes", "red"},
+		{"luces", "luz"},
+		{"clases", "clase"},
+		{"rápidamente", "rápido"},
+		{"Árbol", "arboles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			a, b := analyze(tt.a), analyze(tt.b)
+			if a != b {
+				t.Errorf("analyze(%q) = %q, analyze(%q) = %q, want equal", tt.a, a, tt.b, b)
+			}
+		})
+	}
+}
+
+func TestTokenize_Stopwords(t *testing.T) {
+	tokens := tokenize("Introducción a la programación")
+	if len(tokens) != 2 {
+		t.Fatalf("tokenize() = %+v, want 2 tokens", tokens)
+	}
+	if tokens[0].start != 0 || tokens[0].end != len("Introducción") {
+		t.Errorf("tokenize() first token offsets = %d-%d", tokens[0].start, tokens[0].end)
+	}
+}
+
+func newTestIndex(t *testing.T) *MemoryIndex {
+	t.Helper()
+
+	idx := NewMemoryIndex()
+	blogs := []types.Blog{
+		{
+			ID: "1", Titulo: "Introducción a la programación", Slug: "introduccion",
+			Extracto: "Primeros pasos", Contenido: "Aprende **variables** y funciones.",
+			Estado: "publicado", Tags: []string{"go"}, FechaPublicacion: time.Now(),
+		},
+		{
+			ID: "2", Titulo: "Recetas de cocina", Slug: "recetas",
+			Extracto: "Para programadores hambrientos", Contenido: "La programación no lo es todo; también hay tacos.",
+			Estado: "publicado", Tags: []string{"cocina"}, FechaPublicacion: time.Now(),
+		},
+		{
+			ID: "3", Titulo: "Programación en borrador", Slug: "borrador",
+			Estado: "borrador", FechaPublicacion: time.Now(),
+		},
+	}
+	for _, blog := range blogs {
+		if err := idx.Index(blog); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	return idx
+}
+
+func TestMemoryIndex_Search(t *testing.T) {
+	idx := newTestIndex(t)
+
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []string
+	}{
+		{name: "Ranking por título", query: "programacion", wantIDs: []string{"1", "2"}},
+		{name: "Sin acentos ni mayúsculas", query: "PROGRAMACIÓN", wantIDs: []string{"1", "2"}},
+		{name: "Plural", query: "programaciones", wantIDs: []string{"1", "2"}},
+		{name: "Tags", query: "cocina", wantIDs: []string{"2"}},
+		{name: "Contenido en Markdown", query: "variable", wantIDs: []string{"1"}},
+		{name: "Solo palabras vacías", query: "de la", wantIDs: []string{}},
+		{name: "Sin resultados", query: "astronomía", wantIDs: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := idx.Search(tt.query, types.Cursor{}, 10)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			if len(results) != len(tt.wantIDs) {
+				t.Fatalf("Search(%q) returned %d results, want %d", tt.query, len(results), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if results[i].Blog.ID != id {
+					t.Errorf("Search(%q)[%d] = %s, want %s", tt.query, i, results[i].Blog.ID, id)
+				}
+				if results[i].Blog.Contenido != "" {
+					t.Errorf("Search(%q)[%d] must not include contenido", tt.query, i)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryIndex_SearchCursor(t *testing.T) {
+	idx := newTestIndex(t)
+
+	first, err := idx.Search("programación", types.Cursor{}, 1)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(first) != 1 || first[0].Blog.ID != "1" {
+		t.Fatalf("Search() first page = %+v, want blog 1", first)
+	}
+
+	// La página siguiente empieza justo detrás del último resultado
+	next, err := idx.Search("programación", first[0].CursorOf(), 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(next) != 1 || next[0].Blog.ID != "2" {
+		t.Errorf("Search() next page = %+v, want blog 2", next)
+	}
+
+	if last, _ := idx.Search("programación", next[0].CursorOf(), 10); len(last) != 0 {
+		t.Errorf("Search() after last result = %d results, want 0", len(last))
+	}
+}
+
+func TestMemoryIndex_Highlight(t *testing.T) {
+	idx := newTestIndex(t)
+
+	results, err := idx.Search("programación", types.Cursor{}, 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	want := "Introducción a la <mark>programación</mark>"
+	if got := results[0].Fragmentos["titulo"]; got != want {
+		t.Errorf("Fragmentos[titulo] = %q, want %q", got, want)
+	}
+
+	want = "La <mark>programación</mark> no lo es todo; también hay tacos."
+	if got := results[1].Fragmentos["contenido"]; got != want {
+		t.Errorf("Fragmentos[contenido] = %q, want %q", got, want)
+	}
+	if _, ok := results[1].Fragmentos["titulo"]; ok {
+		t.Errorf("Fragmentos must only include matching fields, got %v", results[1].Fragmentos)
+	}
+}
+
+func TestMemoryIndex_UpdateAndRemove(t *testing.T) {
+	idx := newTestIndex(t)
+
+	// Al despublicar un blog desaparece de los resultados
+	if err := idx.Index(types.Blog{ID: "1", Titulo: "Introducción a la programación", Estado: "borrador"}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, _ := idx.Search("introduccion", types.Cursor{}, 10)
+	if len(results) != 0 {
+		t.Errorf("Search() after unpublish = %d results, want 0", len(results))
+	}
+
+	// Al cambiar el título se reemplazan los términos antiguos
+	if err := idx.Index(types.Blog{ID: "2", Titulo: "Astronomía", Estado: "publicado"}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if results, _ := idx.Search("cocina", types.Cursor{}, 10); len(results) != 0 {
+		t.Errorf("Search(old term) = %d results, want 0", len(results))
+	}
+	if results, _ := idx.Search("astronomia", types.Cursor{}, 10); len(results) != 1 {
+		t.Errorf("Search(new term) = %d results, want 1", len(results))
+	}
+
+	if err := idx.Remove("2"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if len(idx.docs) != 0 || len(idx.postings) != 0 {
+		t.Errorf("index not empty after Remove: %d docs, %d terms", len(idx.docs), len(idx.postings))
+	}
+}
+
+func TestHighlight_Snippet(t *testing.T) {
+	text := "Lorem ipsum dolor sit amet. " +
+		"Esto es un texto largo que habla de muchas cosas antes de llegar a la palabra clave que buscamos, " +
+		"que es tortuga, y después sigue hablando durante bastante tiempo sobre otras cosas sin importancia " +
+		"hasta terminar el párrafo."
+
+	got, ok := highlight(text, map[string]bool{analyze("tortuga"): true}, 80)
+	if !ok {
+		t.Fatal("highlight() found no match")
+	}
+	if len(got) > 80+len("……<mark></mark>") {
+		t.Errorf("highlight() length = %d, want <= snippet size", len(got))
+	}
+	if want := "<mark>tortuga</mark>"; !strings.Contains(got, want) {
+		t.Errorf("highlight() = %q, want it to contain %q", got, want)
+	}
+	if !strings.HasPrefix(got, "…") {
+		t.Errorf("highlight() = %q, want leading ellipsis", got)
+	}
+}
+
+func TestMemoryIndex_Similar(t *testing.T) {
+	idx := newTestIndex(t)
+	err := idx.Index(types.Blog{
+		ID: "4", Titulo: "Funciones y variables en Go", Slug: "funciones",
+		Contenido: "Las funciones reciben variables.", Estado: "publicado", Tags: []string{"go"},
+	})
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	version := idx.Version()
+
+	results, err := idx.Similar("1", 10)
+	if err != nil {
+		t.Fatalf("Similar() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Blog.ID != "4" || results[1].Blog.ID != "2" {
+		t.Fatalf("Similar() = %+v, want 4 then 2", results)
+	}
+	if s := results[0].Puntuacion; s <= results[1].Puntuacion || s > 1 {
+		t.Errorf("Similar() scores = %v, %v", s, results[1].Puntuacion)
+	}
+
+	if results, _ := idx.Similar("3", 10); len(results) != 0 {
+		t.Errorf("Similar(unpublished) = %d results, want 0", len(results))
+	}
+
+	if err := idx.Remove("4"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if idx.Version() == version {
+		t.Errorf("Version() did not change after Remove")
+	}
+}
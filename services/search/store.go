@@ -0,0 +1,102 @@
+package search
+
+import (
+	"log"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// IndexedBlogStore envuelve un BlogStore y mantiene el índice de búsqueda
+// sincronizado con CreateBlog, UpdateBlog, DeleteBlog y RestoreBlog
+type IndexedBlogStore struct {
+	types.BlogStore
+	index types.SearchIndex
+}
+
+// NewIndexedBlogStore crea un BlogStore que actualiza el índice en cada escritura
+func NewIndexedBlogStore(store types.BlogStore, index types.SearchIndex) *IndexedBlogStore {
+	return &IndexedBlogStore{
+		BlogStore: store,
+		index:     index,
+	}
+}
+
+func (s *IndexedBlogStore) CreateBlog(blog types.Blog) error {
+	if err := s.BlogStore.CreateBlog(blog); err != nil {
+		return err
+	}
+	s.reindex(blog.ID)
+	return nil
+}
+
+func (s *IndexedBlogStore) UpdateBlog(blog types.Blog) error {
+	if err := s.BlogStore.UpdateBlog(blog); err != nil {
+		return err
+	}
+	s.reindex(blog.ID)
+	return nil
+}
+
+func (s *IndexedBlogStore) DeleteBlog(id string) error {
+	if err := s.BlogStore.DeleteBlog(id); err != nil {
+		return err
+	}
+	if err := s.index.Remove(id); err != nil {
+		log.Printf("Error al eliminar el blog %s del índice: %v", id, err)
+	}
+	return nil
+}
+
+func (s *IndexedBlogStore) RestoreBlog(id string) error {
+	if err := s.BlogStore.RestoreBlog(id); err != nil {
+		return err
+	}
+	s.reindex(id)
+	return nil
+}
+
+func (s *IndexedBlogStore) AddBlogTag(blogID string, tag string) error {
+	if err := s.BlogStore.AddBlogTag(blogID, tag); err != nil {
+		return err
+	}
+	s.reindex(blogID)
+	return nil
+}
+
+func (s *IndexedBlogStore) RemoveBlogTag(blogID string, tag string) error {
+	if err := s.BlogStore.RemoveBlogTag(blogID, tag); err != nil {
+		return err
+	}
+	s.reindex(blogID)
+	return nil
+}
+
+// reindex vuelve a leer el blog de la base de datos para indexar lo que realmente
+// quedó guardado. Un fallo del índice no debe deshacer una escritura exitosa.
+func (s *IndexedBlogStore) reindex(id string) {
+	blog, err := s.BlogStore.GetBlogByID(id)
+	if err != nil {
+		log.Printf("Error al leer el blog %s para indexarlo: %v", id, err)
+		return
+	}
+	if err := s.index.Index(*blog); err != nil {
+		log.Printf("Error al indexar el blog %s: %v", id, err)
+	}
+}
+
+// Rebuild indexa todos los blogs publicados. Se llama al arrancar el servidor.
+func Rebuild(index types.SearchIndex, store types.BlogStore) error {
+	blogs, err := store.GetPublishedBlogs()
+	if err != nil {
+		return err
+	}
+
+	for _, blog := range blogs {
+		if err := index.Index(blog); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Índice de búsqueda construido con %d blogs", len(blogs))
+	return nil
+}
@@ -0,0 +1,126 @@
+package seo
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// sitemapTTL es cuánto tiempo se reutiliza un sitemap ya generado
+const sitemapTTL = 10 * time.Minute
+
+// Handler maneja sitemap.xml, robots.txt y los metadatos SEO de los blogs
+type Handler struct {
+	store types.BlogStore
+
+	mu          sync.Mutex
+	sitemaps    [][]byte
+	generatedAt time.Time
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.BlogStore) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRootRoutes registra las rutas que los buscadores esperan en la raíz del sitio
+func (h *Handler) RegisterRootRoutes(router *mux.Router) {
+	router.HandleFunc("/robots.txt", h.handleRobots).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/sitemap.xml", h.handleSitemap).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/sitemaps/sitemap-{n:[0-9]+}.xml", h.handleSitemap).Methods(http.MethodGet, http.MethodHead)
+}
+
+// RegisterRoutes registra las rutas del handler en el router de la API
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/seo/blogs/{slug}", h.handleGetBlogMetadata).Methods(http.MethodGet)
+}
+
+func (h *Handler) handleRobots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(Robots(configs.Envs.PublicHost))
+	}
+}
+
+// handleSitemap sirve /sitemap.xml (urlset o índice) y los archivos numerados del índice
+func (h *Handler) handleSitemap(w http.ResponseWriter, r *http.Request) {
+	docs, generatedAt, err := h.getSitemaps()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	n := 0
+	if v, ok := mux.Vars(r)["n"]; ok {
+		n, _ = strconv.Atoi(v)
+		// Los archivos numerados solo existen cuando el sitemap se dividió en un índice
+		if n < 1 || n >= len(docs) {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("sitemap not found"))
+			return
+		}
+	}
+	body := docs[n]
+
+	w.Header().Set("Cache-Control", "public, max-age=600")
+	if utils.CheckNotModified(w, r, utils.ETag(body), generatedAt) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
+
+// getSitemaps devuelve los sitemaps en caché o los regenera si caducaron
+func (h *Handler) getSitemaps() ([][]byte, time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.sitemaps != nil && time.Since(h.generatedAt) < sitemapTTL {
+		return h.sitemaps, h.generatedAt, nil
+	}
+
+	blogs, err := h.store.GetPublishedBlogSummaries()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	docs, err := Sitemap(configs.Envs.PublicHost, blogs, MaxURLsPerSitemap)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	h.sitemaps = docs
+	h.generatedAt = time.Now().Truncate(time.Second)
+	return h.sitemaps, h.generatedAt, nil
+}
+
+func (h *Handler) handleGetBlogMetadata(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	blog, err := h.store.GetBlogBySlug(slug)
+	if err != nil {
+		if err.Error() == "blog not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, Metadata(configs.Envs.PublicHost, *blog))
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,121 @@
+package seo
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// SiteName es el nombre del sitio en Open Graph y en el publisher de JSON-LD
+const SiteName = "Pardalis"
+
+// Metadata construye las etiquetas SEO de un blog. La descripción y las
+// palabras clave usan meta_descripcion y meta_keywords si el autor las definió,
+// y en su defecto el extracto y los tags.
+func Metadata(host string, blog types.Blog) types.SEOMetadata {
+	canonical := utils.BlogURL(host, blog.Slug)
+
+	descripcion := strings.TrimSpace(blog.MetaDescripcion)
+	if descripcion == "" {
+		descripcion = strings.TrimSpace(blog.Extracto)
+	}
+
+	keywords := splitKeywords(blog.MetaKeywords)
+	if len(keywords) == 0 {
+		keywords = append(keywords, blog.Tags...)
+	}
+
+	imagen := ""
+	if blog.ImagenPortada != "" {
+		imagen = utils.AbsoluteURL(host, blog.ImagenPortada)
+	}
+
+	modified := blog.FechaPublicacion
+	if blog.FechaActualizacion.After(modified) {
+		modified = blog.FechaActualizacion
+	}
+	autorURL := utils.AbsoluteURL(host, "/users/"+url.PathEscape(blog.AutorApodo))
+
+	og := map[string]string{
+		"og:type":                "article",
+		"og:site_name":           SiteName,
+		"og:locale":              "es_MX",
+		"og:title":               blog.Titulo,
+		"og:description":         descripcion,
+		"og:url":                 canonical,
+		"article:published_time": blog.FechaPublicacion.UTC().Format(time.RFC3339),
+		"article:modified_time":  modified.UTC().Format(time.RFC3339),
+		"article:author":         autorURL,
+		"article:section":        blog.Categoria,
+	}
+	twitter := map[string]string{
+		"twitter:card":        "summary",
+		"twitter:title":       blog.Titulo,
+		"twitter:description": descripcion,
+	}
+	if imagen != "" {
+		og["og:image"] = imagen
+		og["og:image:alt"] = blog.Titulo
+		twitter["twitter:card"] = "summary_large_image"
+		twitter["twitter:image"] = imagen
+	}
+
+	jsonLD := map[string]any{
+		"@context":         "https://schema.org",
+		"@type":            "BlogPosting",
+		"headline":         blog.Titulo,
+		"description":      descripcion,
+		"url":              canonical,
+		"mainEntityOfPage": map[string]any{"@type": "WebPage", "@id": canonical},
+		"datePublished":    blog.FechaPublicacion.UTC().Format(time.RFC3339),
+		"dateModified":     modified.UTC().Format(time.RFC3339),
+		"author": map[string]any{
+			"@type": "Person",
+			"name":  blog.AutorApodo,
+			"url":   autorURL,
+		},
+		"publisher": map[string]any{
+			"@type": "Organization",
+			"name":  SiteName,
+			"url":   utils.AbsoluteURL(host, "/"),
+		},
+		"inLanguage": "es",
+	}
+	if blog.Categoria != "" {
+		jsonLD["articleSection"] = blog.Categoria
+	}
+	if len(keywords) > 0 {
+		jsonLD["keywords"] = strings.Join(keywords, ", ")
+	}
+	if imagen != "" {
+		jsonLD["image"] = imagen
+	}
+	if blog.TiempoLectura > 0 {
+		jsonLD["timeRequired"] = fmt.Sprintf("PT%dM", blog.TiempoLectura)
+	}
+
+	return types.SEOMetadata{
+		Titulo:      blog.Titulo + " | " + SiteName,
+		Descripcion: descripcion,
+		Keywords:    keywords,
+		Canonical:   canonical,
+		OpenGraph:   og,
+		Twitter:     twitter,
+		JSONLD:      jsonLD,
+	}
+}
+
+// splitKeywords separa meta_keywords por comas descartando entradas vacías
+func splitKeywords(s string) []string {
+	keywords := []string{}
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+	return keywords
+}
@@ -0,0 +1,174 @@
+package seo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func testBlogs(n int) []types.Blog {
+	base := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+	blogs := make([]types.Blog, 0, n)
+	for i := 0; i < n; i++ {
+		blogs = append(blogs, types.Blog{
+			Slug:             fmt.Sprintf("post-%d", i),
+			Categoria:        []string{"Gramática", "Vocabulario"}[i%2],
+			CategoriaSlug:    []string{"gramatica", "vocabulario"}[i%2],
+			AutorApodo:       []string{"ana", "luis", "sofia"}[i%3],
+			FechaPublicacion: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	return blogs
+}
+
+func TestSitemap_Single(t *testing.T) {
+	docs, err := Sitemap("https://pardalis.mx", testBlogs(4), MaxURLsPerSitemap)
+	if err != nil {
+		t.Fatalf("Sitemap() error = %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("Sitemap() = %d documents, want 1", len(docs))
+	}
+
+	var set struct {
+		XMLName xml.Name
+		URLs    []sitemapURL `xml:"url"`
+	}
+	if err := xml.Unmarshal(docs[0], &set); err != nil {
+		t.Fatalf("Sitemap() produced invalid XML: %v", err)
+	}
+	if set.XMLName.Local != "urlset" {
+		t.Errorf("Sitemap() root = %s, want urlset", set.XMLName.Local)
+	}
+
+	var locs []string
+	for _, u := range set.URLs {
+		locs = append(locs, u.Loc)
+	}
+	want := []string{
+		"https://pardalis.mx/blogs",
+		"https://pardalis.mx/blogs/post-0",
+		"https://pardalis.mx/blogs/post-1",
+		"https://pardalis.mx/blogs/post-2",
+		"https://pardalis.mx/blogs/post-3",
+		"https://pardalis.mx/blogs?categoria=gramatica",
+		"https://pardalis.mx/blogs?categoria=vocabulario",
+		"https://pardalis.mx/users/ana",
+		"https://pardalis.mx/users/luis",
+		"https://pardalis.mx/users/sofia",
+	}
+	if !reflect.DeepEqual(locs, want) {
+		t.Errorf("Sitemap() locs = %v, want %v", locs, want)
+	}
+
+	if set.URLs[0].LastMod != "2024-09-01T13:00:00Z" {
+		t.Errorf("Sitemap() /blogs lastmod = %q, want newest post", set.URLs[0].LastMod)
+	}
+}
+
+func TestSitemap_Index(t *testing.T) {
+	// 20 blogs + /blogs + 2 categorías + 3 autores = 26 URLs en archivos de 10
+	docs, err := Sitemap("https://pardalis.mx", testBlogs(20), 10)
+	if err != nil {
+		t.Fatalf("Sitemap() error = %v", err)
+	}
+	if len(docs) != 4 {
+		t.Fatalf("Sitemap() = %d documents, want index + 3 files", len(docs))
+	}
+
+	var index struct {
+		XMLName  xml.Name
+		Sitemaps []sitemapURL `xml:"sitemap"`
+	}
+	if err := xml.Unmarshal(docs[0], &index); err != nil {
+		t.Fatalf("Sitemap() produced invalid index: %v", err)
+	}
+	if index.XMLName.Local != "sitemapindex" || len(index.Sitemaps) != 3 {
+		t.Fatalf("Sitemap() index = %+v", index)
+	}
+	if index.Sitemaps[2].Loc != "https://pardalis.mx/sitemaps/sitemap-3.xml" {
+		t.Errorf("Sitemap() index loc = %q", index.Sitemaps[2].Loc)
+	}
+
+	total := 0
+	for _, doc := range docs[1:] {
+		total += strings.Count(string(doc), "<url>")
+	}
+	if total != 26 {
+		t.Errorf("Sitemap() files contain %d URLs, want 26", total)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	got := string(Robots("https://pardalis.mx/"))
+	if !strings.Contains(got, "Sitemap: https://pardalis.mx/sitemap.xml\n") {
+		t.Errorf("Robots() = %q, want sitemap line", got)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	published := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		blog            types.Blog
+		wantDescripcion string
+		wantKeywords    []string
+		wantCard        string
+		wantImage       string
+	}{
+		{
+			name: "Meta definidos por el autor",
+			blog: types.Blog{
+				Titulo: "Verbos", Slug: "verbos", Extracto: "Extracto",
+				MetaDescripcion: "Descripción SEO", MetaKeywords: "inglés, verbos, ,gramática",
+				Tags: []string{"tag"}, ImagenPortada: "/uploads/verbos.png",
+				AutorApodo: "ana", FechaPublicacion: published,
+			},
+			wantDescripcion: "Descripción SEO",
+			wantKeywords:    []string{"inglés", "verbos", "gramática"},
+			wantCard:        "summary_large_image",
+			wantImage:       "https://pardalis.mx/uploads/verbos.png",
+		},
+		{
+			name: "Valores por defecto",
+			blog: types.Blog{
+				Titulo: "Verbos", Slug: "verbos", Extracto: "Extracto",
+				Tags: []string{"ingles"}, AutorApodo: "ana", FechaPublicacion: published,
+			},
+			wantDescripcion: "Extracto",
+			wantKeywords:    []string{"ingles"},
+			wantCard:        "summary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Metadata("https://pardalis.mx", tt.blog)
+
+			if got.Descripcion != tt.wantDescripcion || got.OpenGraph["og:description"] != tt.wantDescripcion {
+				t.Errorf("Metadata() descripcion = %q / %q", got.Descripcion, got.OpenGraph["og:description"])
+			}
+			if !reflect.DeepEqual(got.Keywords, tt.wantKeywords) {
+				t.Errorf("Metadata() keywords = %v, want %v", got.Keywords, tt.wantKeywords)
+			}
+			if got.Twitter["twitter:card"] != tt.wantCard {
+				t.Errorf("Metadata() twitter:card = %q, want %q", got.Twitter["twitter:card"], tt.wantCard)
+			}
+			if got.OpenGraph["og:image"] != tt.wantImage {
+				t.Errorf("Metadata() og:image = %q, want %q", got.OpenGraph["og:image"], tt.wantImage)
+			}
+			if got.Canonical != "https://pardalis.mx/blogs/verbos" {
+				t.Errorf("Metadata() canonical = %q", got.Canonical)
+			}
+			if got.JSONLD["@type"] != "BlogPosting" || got.JSONLD["datePublished"] != "2024-09-01T10:00:00Z" {
+				t.Errorf("Metadata() json_ld = %v", got.JSONLD)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package seo genera sitemap.xml, robots.txt y los metadatos Open Graph,
+// Twitter y JSON-LD que el frontend SSR necesita para cada blog.
+package seo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// MaxURLsPerSitemap es el límite de URLs por archivo que fija el protocolo sitemaps.org
+const MaxURLsPerSitemap = 50000
+
+// sitemapURL es una entrada <url> del sitemap
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// entry es una URL con su fecha de última modificación
+type entry struct {
+	loc     string
+	lastMod time.Time
+}
+
+// entries construye las URLs públicas a partir de los blogs publicados:
+// cada blog, cada categoría y el perfil de cada autor con publicaciones
+func entries(host string, blogs []types.Blog) []entry {
+	result := []entry{{loc: utils.AbsoluteURL(host, "/blogs")}}
+	categorias := map[string]time.Time{}
+	autores := map[string]time.Time{}
+
+	for _, blog := range blogs {
+		lastMod := blog.FechaPublicacion
+		if blog.FechaActualizacion.After(lastMod) {
+			lastMod = blog.FechaActualizacion
+		}
+
+		result = append(result, entry{loc: utils.BlogURL(host, blog.Slug), lastMod: lastMod})
+
+		if slug := categorySlug(blog); slug != "" && lastMod.After(categorias[slug]) {
+			categorias[slug] = lastMod
+		}
+		if lastMod.After(autores[blog.AutorApodo]) {
+			autores[blog.AutorApodo] = lastMod
+		}
+	}
+
+	for _, categoria := range sortedKeys(categorias) {
+		loc := utils.AbsoluteURL(host, "/blogs?categoria="+url.QueryEscape(categoria))
+		result = append(result, entry{loc: loc, lastMod: categorias[categoria]})
+	}
+	for _, apodo := range sortedKeys(autores) {
+		loc := utils.AbsoluteURL(host, "/users/"+url.PathEscape(apodo))
+		result = append(result, entry{loc: loc, lastMod: autores[apodo]})
+	}
+
+	if len(result) > 1 {
+		result[0].lastMod = latest(result[1:])
+	}
+
+	return result
+}
+
+// categorySlug devuelve el slug de la categoría del blog, con el que se filtra /blogs
+func categorySlug(blog types.Blog) string {
+	if blog.CategoriaSlug != "" || blog.Categoria == "" {
+		return blog.CategoriaSlug
+	}
+	return utils.GenerateSlug(blog.Categoria)
+}
+
+// Sitemap genera los documentos del sitemap. Si hay más de perFile URLs, el
+// primer documento es un índice y los siguientes son los archivos numerados
+// desde 1 que enlaza, servidos en {host}/sitemaps/sitemap-{n}.xml
+func Sitemap(host string, blogs []types.Blog, perFile int) ([][]byte, error) {
+	all := entries(host, blogs)
+
+	if len(all) <= perFile {
+		doc, err := marshal(urlSet{URLs: toSitemapURLs(all)})
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{doc}, nil
+	}
+
+	index := sitemapIndex{}
+	var files [][]byte
+	for start, n := 0, 1; start < len(all); start, n = start+perFile, n+1 {
+		end := start + perFile
+		if end > len(all) {
+			end = len(all)
+		}
+		chunk := all[start:end]
+
+		doc, err := marshal(urlSet{URLs: toSitemapURLs(chunk)})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, doc)
+
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{
+			Loc:     utils.AbsoluteURL(host, fmt.Sprintf("/sitemaps/sitemap-%d.xml", n)),
+			LastMod: formatLastMod(latest(chunk)),
+		})
+	}
+
+	doc, err := marshal(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([][]byte{doc}, files...), nil
+}
+
+// Robots genera robots.txt apuntando al sitemap
+func Robots(host string) []byte {
+	return []byte("User-agent: *\n" +
+		"Allow: /\n" +
+		"Disallow: /api/v1/users/\n" +
+		"Disallow: /api/v1/login\n" +
+		"Disallow: /api/v1/register\n" +
+		"\n" +
+		"Sitemap: " + utils.AbsoluteURL(host, "/sitemap.xml") + "\n")
+}
+
+func toSitemapURLs(list []entry) []sitemapURL {
+	urls := make([]sitemapURL, 0, len(list))
+	for _, e := range list {
+		urls = append(urls, sitemapURL{Loc: e.loc, LastMod: formatLastMod(e.lastMod)})
+	}
+	return urls
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func latest(list []entry) time.Time {
+	var t time.Time
+	for _, e := range list {
+		if e.lastMod.After(t) {
+			t = e.lastMod
+		}
+	}
+	return t
+}
+
+func sortedKeys(m map[string]time.Time) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func marshal(v any) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
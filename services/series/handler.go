@@ -0,0 +1,293 @@
+package series
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Handler maneja las series y sus partes
+type Handler struct {
+	store     types.SeriesStore
+	blogStore types.BlogStore
+	userStore types.UserStore
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.SeriesStore, blogStore types.BlogStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:     store,
+		blogStore: blogStore,
+		userStore: userStore,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/series", auth.WithJWTAuth(h.handleCreateSeries, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/series/{slug}", h.handleGetSeries).Methods(http.MethodGet)
+	router.HandleFunc("/series/{id}", auth.WithJWTAuth(h.handleUpdateSeries, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/series/{id}", auth.WithJWTAuth(h.handleDeleteSeries, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/series/{id}/items", auth.WithJWTAuth(h.handleAddPart, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/series/{id}/items/{blogId}", auth.WithJWTAuth(h.handleRemovePart, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/series/{id}/order", auth.WithJWTAuth(h.handleReorder, h.userStore)).Methods(http.MethodPut)
+}
+
+// handleGetSeries devuelve una serie con una página de sus partes publicadas
+func (h *Handler) handleGetSeries(w http.ResponseWriter, r *http.Request) {
+	series, err := h.store.GetSeriesBySlug(mux.Vars(r)["slug"])
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.writeSeriesPage(w, r, series, true)
+}
+
+func (h *Handler) handleCreateSeries(w http.ResponseWriter, r *http.Request) {
+	var payload types.CreateSeriesPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	titulo := strings.TrimSpace(payload.Titulo)
+	series := types.Series{
+		ID:            uuid.New().String(),
+		Titulo:        titulo,
+		Slug:          utils.GenerateSlug(titulo),
+		Descripcion:   payload.Descripcion,
+		ImagenPortada: payload.ImagenPortada,
+		AutorApodo:    auth.GetUserApodoFromContext(r.Context()),
+	}
+
+	if err := h.store.CreateSeries(series); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	created, err := h.store.GetSeriesByID(series.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusCreated, created)
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleUpdateSeries(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.getOwnSeries(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.UpdateSeriesPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if titulo := strings.TrimSpace(payload.Titulo); titulo != "" {
+		series.Titulo = titulo
+		series.Slug = utils.GenerateSlug(titulo)
+	}
+	if payload.Descripcion != nil {
+		series.Descripcion = *payload.Descripcion
+	}
+	if payload.ImagenPortada != nil {
+		series.ImagenPortada = *payload.ImagenPortada
+	}
+
+	if err := h.store.UpdateSeries(*series); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.writeSeries(w, r, series)
+}
+
+func (h *Handler) handleDeleteSeries(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.getOwnSeries(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteSeries(series.ID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err := utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Series deleted successfully"})
+	if err != nil {
+		return
+	}
+}
+
+// handleAddPart añade al final de la serie un blog del mismo autor. Los
+// borradores se pueden añadir; no aparecen hasta que se publican.
+func (h *Handler) handleAddPart(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.getOwnSeries(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.AddSeriesPartPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	blog, err := h.blogStore.GetBlogByID(payload.BlogID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("blog not found"))
+		return
+	}
+	if blog.AutorApodo != series.AutorApodo {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("only your own blogs can be added to a series"))
+		return
+	}
+
+	if err := h.store.AddSeriesPart(series.ID, blog.ID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.writeSeries(w, r, series)
+}
+
+func (h *Handler) handleRemovePart(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.getOwnSeries(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.RemoveSeriesPart(series.ID, mux.Vars(r)["blogId"]); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.writeSeries(w, r, series)
+}
+
+func (h *Handler) handleReorder(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.getOwnSeries(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.ReorderSeriesPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.store.ReorderSeries(series.ID, payload.BlogIDs); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.writeSeries(w, r, series)
+}
+
+// getOwnSeries obtiene la serie de la ruta y comprueba que pertenece al usuario autenticado
+func (h *Handler) getOwnSeries(w http.ResponseWriter, r *http.Request) (*types.Series, bool) {
+	series, err := h.store.GetSeriesByID(mux.Vars(r)["id"])
+	if err != nil {
+		writeStoreError(w, err)
+		return nil, false
+	}
+
+	if series.AutorApodo != auth.GetUserApodoFromContext(r.Context()) {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to modify this series"))
+		return nil, false
+	}
+
+	return series, true
+}
+
+// writeSeries responde con la serie y sus partes, incluidos los borradores,
+// porque solo la recibe su autor
+func (h *Handler) writeSeries(w http.ResponseWriter, r *http.Request, series *types.Series) {
+	h.writeSeriesPage(w, r, series, false)
+}
+
+// writeSeriesPage responde con la serie y la página de partes que indican
+// ?cursor y ?limit. TotalPartes cuenta todas, no solo las de la página.
+func (h *Handler) writeSeriesPage(w http.ResponseWriter, r *http.Request, series *types.Series, soloPublicadas bool) {
+	pagination, err := utils.ParsePagination(r, 50, 100)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	parts, err := h.store.GetSeriesParts(series.ID, soloPublicadas, int(pagination.After.Valor), pagination.After.ID, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	page := utils.NewPage(parts, pagination.Limit, partCursor)
+	series.Partes = page.Items
+	series.PartesNextCursor = page.NextCursor
+
+	series.TotalPartes, err = h.store.CountSeriesParts(series.ID, soloPublicadas)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SetPageLinks(w, r, page.NextCursor)
+	err = utils.WriteJSON(w, http.StatusOK, series)
+	if err != nil {
+		return
+	}
+}
+
+// partCursor devuelve la posición de una parte, que se ordena por su posición
+// en la serie e id
+func partCursor(p types.SeriesPart) types.Cursor {
+	return types.Cursor{ID: p.ID, Valor: int64(p.Posicion)}
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case err.Error() == "series not found", err.Error() == "blog not in series":
+		utils.WriteError(w, http.StatusNotFound, err)
+	case err.Error() == "order must contain every blog in the series exactly once":
+		utils.WriteError(w, http.StatusBadRequest, err)
+	case err.Error() == "blog already belongs to another series":
+		utils.WriteError(w, http.StatusConflict, err)
+	case strings.Contains(err.Error(), "Duplicate entry"):
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("a series with this title already exists"))
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,48 @@
+package series
+
+import "gitlab.com/pardalis/pardalis-api/types"
+
+// locate completa la navegación con la posición de blogID entre las partes
+// publicadas y sus vecinas. Devuelve false si blogID no está entre ellas.
+func locate(nav *types.SeriesNavigation, parts []types.SeriesLink, blogID string) bool {
+	for i, part := range parts {
+		if part.ID != blogID {
+			continue
+		}
+
+		nav.Posicion = i + 1
+		nav.Total = len(parts)
+		nav.Anterior, nav.Siguiente = nil, nil
+		if i > 0 {
+			prev := parts[i-1]
+			nav.Anterior = &prev
+		}
+		if i < len(parts)-1 {
+			next := parts[i+1]
+			nav.Siguiente = &next
+		}
+		return true
+	}
+
+	return false
+}
+
+// isPermutation indica si requested contiene exactamente los mismos ids que current, sin repetir
+func isPermutation(current []string, requested []string) bool {
+	if len(current) != len(requested) {
+		return false
+	}
+
+	pending := make(map[string]bool, len(current))
+	for _, id := range current {
+		pending[id] = true
+	}
+	for _, id := range requested {
+		if !pending[id] {
+			return false
+		}
+		delete(pending, id)
+	}
+
+	return true
+}
@@ -0,0 +1,54 @@
+package series
+
+import (
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestLocate(t *testing.T) {
+	parts := []types.SeriesLink{{ID: "uno"}, {ID: "dos"}, {ID: "tres"}}
+
+	tests := []struct {
+		name      string
+		blogID    string
+		found     bool
+		posicion  int
+		anterior  string
+		siguiente string
+	}{
+		{"Primera parte", "uno", true, 1, "", "dos"},
+		{"Parte intermedia", "dos", true, 2, "uno", "tres"},
+		{"Última parte", "tres", true, 3, "dos", ""},
+		{"Borrador", "cuatro", false, 0, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nav := new(types.SeriesNavigation)
+			if got := locate(nav, parts, tt.blogID); got != tt.found {
+				t.Fatalf("locate() = %v, want %v", got, tt.found)
+			}
+			if !tt.found {
+				return
+			}
+
+			if nav.Posicion != tt.posicion || nav.Total != len(parts) {
+				t.Errorf("locate() posicion = %d/%d, want %d/%d", nav.Posicion, nav.Total, tt.posicion, len(parts))
+			}
+			if id := linkID(nav.Anterior); id != tt.anterior {
+				t.Errorf("locate() anterior = %q, want %q", id, tt.anterior)
+			}
+			if id := linkID(nav.Siguiente); id != tt.siguiente {
+				t.Errorf("locate() siguiente = %q, want %q", id, tt.siguiente)
+			}
+		})
+	}
+}
+
+func linkID(link *types.SeriesLink) string {
+	if link == nil {
+		return ""
+	}
+	return link.ID
+}
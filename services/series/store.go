@@ -0,0 +1,304 @@
+// Package series implementa las series: colecciones ordenadas de blogs, como los cursos en varias partes.
+package series
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// seriesColumns son las columnas que espera scanSeries, en orden
+const seriesColumns = `
+	s.id, s.titulo, s.slug, s.descripcion, s.imagen_portada, s.autor_apodo,
+	s.fecha_creacion, s.fecha_actualizacion,
+	(SELECT COUNT(*) FROM series_blogs sb WHERE sb.serie_id = s.id)
+`
+
+// Store implementa SeriesStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// CreateSeries guarda una serie nueva sin partes
+func (s *Store) CreateSeries(series types.Series) error {
+	_, err := s.db.Exec(
+		"INSERT INTO series (id, titulo, slug, descripcion, imagen_portada, autor_apodo) VALUES (?, ?, ?, ?, ?, ?)",
+		series.ID, series.Titulo, series.Slug, series.Descripcion, series.ImagenPortada, series.AutorApodo,
+	)
+	return err
+}
+
+// GetSeriesByID obtiene una serie sin sus partes
+func (s *Store) GetSeriesByID(id string) (*types.Series, error) {
+	return s.getSeries("s.id = ?", id)
+}
+
+// GetSeriesBySlug obtiene una serie sin sus partes. Las series de usuarios en
+// la papelera no se encuentran.
+func (s *Store) GetSeriesBySlug(slug string) (*types.Series, error) {
+	return s.getSeries("s.slug = ?", slug)
+}
+
+func (s *Store) getSeries(where string, arg string) (*types.Series, error) {
+	row := s.db.QueryRow(
+		"SELECT "+seriesColumns+" FROM series s JOIN usuarios u ON u.apodo = s.autor_apodo WHERE u.eliminado_en IS NULL AND "+where,
+		arg,
+	)
+
+	series, err := scanSeries(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("series not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// UpdateSeries actualiza el título, el slug, la descripción y la portada de una serie
+func (s *Store) UpdateSeries(series types.Series) error {
+	_, err := s.db.Exec(
+		"UPDATE series SET titulo = ?, slug = ?, descripcion = ?, imagen_portada = ? WHERE id = ?",
+		series.Titulo, series.Slug, series.Descripcion, series.ImagenPortada, series.ID,
+	)
+	return err
+}
+
+// DeleteSeries elimina una serie. Sus blogs no se borran, solo dejan de formar parte de ella.
+func (s *Store) DeleteSeries(id string) error {
+	_, err := s.db.Exec("DELETE FROM series WHERE id = ?", id)
+	return err
+}
+
+// GetSeriesParts devuelve las partes de una serie posteriores al cursor, en
+// orden. Los borradores solo se incluyen si soloPublicadas es false, para que
+// el autor pueda ordenarlos.
+func (s *Store) GetSeriesParts(serieID string, soloPublicadas bool, afterPosicion int, afterID string, limit int) ([]types.SeriesPart, error) {
+	rows, err := s.db.Query(`
+        SELECT b.id, b.titulo, b.slug, b.extracto, b.imagen_portada, b.fecha_publicacion, b.estado,
+            COALESCE(cat.nombre, b.categoria), COALESCE(b.categoria_id, ''), COALESCE(cat.slug, ''),
+            b.tiempo_lectura, b.autor_apodo, b.fecha_actualizacion, sb.posicion
+        FROM series_blogs sb
+        JOIN blogs b ON b.id = sb.blog_id
+        LEFT JOIN categorias cat ON cat.id = b.categoria_id
+        WHERE sb.serie_id = ? AND b.eliminado_en IS NULL AND (? = FALSE OR b.estado = 'publicado')
+            AND (? = '' OR (sb.posicion, b.id) > (?, ?))
+        ORDER BY sb.posicion, b.id
+        LIMIT ?
+    `, serieID, soloPublicadas, afterID, afterPosicion, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	parts := []types.SeriesPart{}
+	for rows.Next() {
+		var part types.SeriesPart
+		err := rows.Scan(
+			&part.ID, &part.Titulo, &part.Slug, &part.Extracto, &part.ImagenPortada, &part.FechaPublicacion, &part.Estado,
+			&part.Categoria, &part.CategoriaID, &part.CategoriaSlug, &part.TiempoLectura, &part.AutorApodo, &part.FechaActualizacion,
+			&part.Posicion,
+		)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, rows.Err()
+}
+
+// CountSeriesParts cuenta las partes de una serie con el mismo criterio que GetSeriesParts
+func (s *Store) CountSeriesParts(serieID string, soloPublicadas bool) (int, error) {
+	var total int
+	err := s.db.QueryRow(`
+        SELECT COUNT(*)
+        FROM series_blogs sb
+        JOIN blogs b ON b.id = sb.blog_id
+        WHERE sb.serie_id = ? AND b.eliminado_en IS NULL AND (? = FALSE OR b.estado = 'publicado')
+    `, serieID, soloPublicadas).Scan(&total)
+	return total, err
+}
+
+// AddSeriesPart añade un blog al final de una serie. Si ya estaba, no cambia su
+// posición; si pertenece a otra serie, falla.
+func (s *Store) AddSeriesPart(serieID string, blogID string) error {
+	var current string
+	err := s.db.QueryRow("SELECT serie_id FROM series_blogs WHERE blog_id = ?", blogID).Scan(&current)
+	switch {
+	case err == nil && current == serieID:
+		return nil
+	case err == nil:
+		return fmt.Errorf("blog already belongs to another series")
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	_, err = s.db.Exec(`
+        INSERT INTO series_blogs (serie_id, blog_id, posicion)
+        SELECT ?, ?, COALESCE(MAX(posicion), 0) + 1
+        FROM series_blogs
+        WHERE serie_id = ?
+    `, serieID, blogID, serieID)
+	return err
+}
+
+// RemoveSeriesPart quita un blog de una serie
+func (s *Store) RemoveSeriesPart(serieID string, blogID string) error {
+	result, err := s.db.Exec("DELETE FROM series_blogs WHERE serie_id = ? AND blog_id = ?", serieID, blogID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("blog not in series")
+	}
+
+	return nil
+}
+
+// ReorderSeries asigna las posiciones de una serie según el orden de blogIDs,
+// que debe contener exactamente sus partes, publicadas o no. Las partes en la
+// papelera conservan su posición para volver a su sitio si se restauran.
+func (s *Store) ReorderSeries(serieID string, blogIDs []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`
+        SELECT sb.blog_id
+        FROM series_blogs sb
+        JOIN blogs b ON b.id = sb.blog_id
+        WHERE sb.serie_id = ? AND b.eliminado_en IS NULL
+        FOR UPDATE
+    `, serieID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	var current []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			_ = tx.Rollback()
+			return err
+		}
+		current = append(current, id)
+	}
+	if err := rows.Close(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if !isPermutation(current, blogIDs) {
+		_ = tx.Rollback()
+		return fmt.Errorf("order must contain every blog in the series exactly once")
+	}
+
+	for i, blogID := range blogIDs {
+		_, err := tx.Exec(
+			"UPDATE series_blogs SET posicion = ? WHERE serie_id = ? AND blog_id = ?",
+			i+1, serieID, blogID,
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSeriesNavigation devuelve la posición de un blog en su serie y sus partes
+// anterior y siguiente. Si el blog no pertenece a ninguna serie devuelve nil.
+func (s *Store) GetSeriesNavigation(blogID string) (*types.SeriesNavigation, error) {
+	nav := new(types.SeriesNavigation)
+	err := s.db.QueryRow(`
+        SELECT s.id, s.titulo, s.slug
+        FROM series_blogs sb
+        JOIN series s ON s.id = sb.serie_id
+        WHERE sb.blog_id = ?
+    `, blogID).Scan(&nav.ID, &nav.Titulo, &nav.Slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+        SELECT b.id, b.titulo, b.slug
+        FROM series_blogs sb
+        JOIN blogs b ON b.id = sb.blog_id
+        WHERE sb.serie_id = ? AND b.estado = 'publicado' AND b.eliminado_en IS NULL
+        ORDER BY sb.posicion
+    `, nav.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	var parts []types.SeriesLink
+	for rows.Next() {
+		var part types.SeriesLink
+		if err := rows.Scan(&part.ID, &part.Titulo, &part.Slug); err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !locate(nav, parts, blogID) {
+		// Un borrador no se muestra como parte de la serie
+		return nil, nil
+	}
+
+	return nav, nil
+}
+
+// scanSeries convierte una fila con seriesColumns en una serie
+func scanSeries(row interface{ Scan(...any) error }) (*types.Series, error) {
+	series := new(types.Series)
+	var descripcion, imagen sql.NullString
+
+	err := row.Scan(
+		&series.ID, &series.Titulo, &series.Slug, &descripcion, &imagen, &series.AutorApodo,
+		&series.FechaCreacion, &series.FechaActualizacion, &series.TotalPartes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	series.Descripcion = descripcion.String
+	series.ImagenPortada = imagen.String
+
+	return series, nil
+}
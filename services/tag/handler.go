@@ -0,0 +1,193 @@
+package tag
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// adminAction completa el error de auth.RequireAdmin en las rutas de este handler
+const adminAction = "manage tags"
+
+// Handler maneja el listado de etiquetas y las operaciones de administración
+type Handler struct {
+	store     types.TagStore
+	blogStore types.BlogStore
+	userStore types.UserStore
+	index     types.SearchIndex
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(store types.TagStore, blogStore types.BlogStore, userStore types.UserStore, index types.SearchIndex) *Handler {
+	return &Handler{
+		store:     store,
+		blogStore: blogStore,
+		userStore: userStore,
+		index:     index,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/tags", h.handleGetTags).Methods(http.MethodGet)
+	router.HandleFunc("/tags/prune", auth.WithJWTAuth(h.handlePruneTags, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/tags/{tag}/blogs", h.handleGetTagBlogs).Methods(http.MethodGet)
+	router.HandleFunc("/tags/{tag}", auth.WithJWTAuth(h.handleRenameTag, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/tags/{tag}/merge", auth.WithJWTAuth(h.handleMergeTag, h.userStore)).Methods(http.MethodPost)
+}
+
+// handleGetTags lista las etiquetas con su número de blogs. Con ?q= funciona
+// como autocompletado por prefijo.
+func (h *Handler) handleGetTags(w http.ResponseWriter, r *http.Request) {
+	pagination, err := utils.ParsePagination(r, 50, 100)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	prefix := strings.TrimSpace(r.URL.Query().Get("q"))
+	tags, err := h.store.GetTags(prefix, pagination.After, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WritePage(w, r, utils.NewPage(tags, pagination.Limit, tagCursor))
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleGetTagBlogs(w http.ResponseWriter, r *http.Request) {
+	pagination, err := utils.ParsePagination(r, 10, 50)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	blogs, err := h.store.GetBlogsByTag(mux.Vars(r)["tag"], pagination.After, pagination.Limit+1)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WritePage(w, r, utils.NewPage(blogs, pagination.Limit, types.BlogQuery{}.CursorOf))
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleRenameTag(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	var payload types.RenameTagPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	nombre := strings.TrimSpace(payload.Nombre)
+	blogIDs, err := h.store.RenameTag(mux.Vars(r)["tag"], nombre)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.reindex(blogIDs)
+
+	err = utils.WriteJSON(w, http.StatusOK, map[string]any{"nombre": nombre, "blogs_actualizados": len(blogIDs)})
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handleMergeTag(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	var payload types.MergeTagPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	destino := strings.TrimSpace(payload.Destino)
+	blogIDs, err := h.store.MergeTags(mux.Vars(r)["tag"], destino)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.reindex(blogIDs)
+
+	err = utils.WriteJSON(w, http.StatusOK, map[string]any{"nombre": destino, "blogs_actualizados": len(blogIDs)})
+	if err != nil {
+		return
+	}
+}
+
+func (h *Handler) handlePruneTags(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	deleted, err := h.store.PruneUnusedTags()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, map[string]int64{"eliminadas": deleted})
+	if err != nil {
+		return
+	}
+}
+
+// reindex actualiza en el índice de búsqueda los blogs cuyas etiquetas cambiaron.
+// Un fallo no revierte la operación; el índice se reconstruye al reiniciar.
+func (h *Handler) reindex(blogIDs []string) {
+	for _, id := range blogIDs {
+		blog, err := h.blogStore.GetBlogByID(id)
+		if err == nil {
+			err = h.index.Index(*blog)
+		}
+		if err != nil {
+			log.Printf("Error al reindexar el blog %s: %v", id, err)
+		}
+	}
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "tag not found":
+		utils.WriteError(w, http.StatusNotFound, err)
+	case "tag already exists":
+		utils.WriteError(w, http.StatusConflict, err)
+	case "cannot merge a tag into itself":
+		utils.WriteError(w, http.StatusBadRequest, err)
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// tagCursor devuelve la posición de una etiqueta, que se ordena por número de
+// blogs y nombre
+func tagCursor(t types.Tag) types.Cursor {
+	return types.Cursor{ID: t.Nombre, Valor: int64(t.TotalBlogs)}
+}
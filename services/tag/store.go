@@ -0,0 +1,247 @@
+// Package tag expone el listado de etiquetas y su administración.
+package tag
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// Store implementa TagStore
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore crea una nueva instancia de Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// GetTags devuelve las etiquetas usadas por blogs publicados, de la más usada a
+// la menos usada y por nombre, a partir del cursor. Si prefix no está vacío solo
+// incluye las que empiezan por él.
+func (s *Store) GetTags(prefix string, after types.Cursor, limit int) ([]types.Tag, error) {
+	rows, err := s.db.Query(`
+        SELECT t.id, t.nombre, COUNT(*) AS total
+        FROM blog_tags t
+        JOIN blog_posts_tags pt ON pt.tag_id = t.id
+        JOIN blogs b ON b.id = pt.blog_id AND b.estado = 'publicado' AND b.eliminado_en IS NULL
+        WHERE t.nombre LIKE ?
+        GROUP BY t.id, t.nombre
+        HAVING ? = '' OR total < ? OR (total = ? AND t.nombre > ?)
+        ORDER BY total DESC, t.nombre
+        LIMIT ?
+    `, escapeLike(prefix)+"%", after.ID, after.Valor, after.Valor, after.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	tags := []types.Tag{}
+	for rows.Next() {
+		var tag types.Tag
+		if err := rows.Scan(&tag.ID, &tag.Nombre, &tag.TotalBlogs); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetBlogsByTag devuelve los blogs publicados con una etiqueta posteriores al
+// cursor, del más reciente al más antiguo
+func (s *Store) GetBlogsByTag(tag string, after types.Cursor, limit int) ([]types.Blog, error) {
+	rows, err := s.db.Query(`
+        SELECT b.id, b.titulo, b.slug, b.extracto, b.imagen_portada, b.fecha_publicacion,
+            COALESCE(cat.nombre, b.categoria), COALESCE(b.categoria_id, ''), COALESCE(cat.slug, ''),
+            b.tiempo_lectura, b.autor_apodo, b.fecha_actualizacion
+        FROM blogs b
+        JOIN blog_posts_tags pt ON pt.blog_id = b.id
+        JOIN blog_tags t ON t.id = pt.tag_id
+        LEFT JOIN categorias cat ON cat.id = b.categoria_id
+        WHERE t.nombre = ? AND b.estado = 'publicado' AND b.eliminado_en IS NULL
+            AND (? = '' OR b.fecha_publicacion < ? OR (b.fecha_publicacion = ? AND b.id < ?))
+        ORDER BY b.fecha_publicacion DESC, b.id DESC
+        LIMIT ?
+    `, tag, after.ID, after.Fecha, after.Fecha, after.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	blogs := []types.Blog{}
+	for rows.Next() {
+		var blog types.Blog
+		err := rows.Scan(
+			&blog.ID, &blog.Titulo, &blog.Slug, &blog.Extracto, &blog.ImagenPortada, &blog.FechaPublicacion,
+			&blog.Categoria, &blog.CategoriaID, &blog.CategoriaSlug,
+			&blog.TiempoLectura, &blog.AutorApodo, &blog.FechaActualizacion,
+		)
+		if err != nil {
+			return nil, err
+		}
+		blog.Estado = "publicado"
+		blogs = append(blogs, blog)
+	}
+
+	return blogs, rows.Err()
+}
+
+// RenameTag cambia el nombre de una etiqueta. Falla si ya existe otra con el
+// nuevo nombre; en ese caso hay que fusionarlas.
+func (s *Store) RenameTag(nombre string, nuevoNombre string) ([]string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := tagID(tx, nombre)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	// La colación ignora mayúsculas, así que cambiar solo el formato es válido
+	existing, err := tagID(tx, nuevoNombre)
+	if err == nil && existing != id {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("tag already exists")
+	}
+	if err != nil && err.Error() != "tag not found" {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE blog_tags SET nombre = ? WHERE id = ?", nuevoNombre, id); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	blogIDs, err := taggedBlogs(tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return blogIDs, tx.Commit()
+}
+
+// MergeTags mueve todos los blogs de la etiqueta origen a destino, que se crea
+// si no existe, y elimina origen
+func (s *Store) MergeTags(origen string, destino string) ([]string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	sourceID, err := tagID(tx, origen)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := tx.Exec("INSERT IGNORE INTO blog_tags (id, nombre) VALUES (UUID(), ?)", destino); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	targetID, err := tagID(tx, destino)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if targetID == sourceID {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("cannot merge a tag into itself")
+	}
+
+	blogIDs, err := taggedBlogs(tx, sourceID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	// Los blogs que ya tenían ambas etiquetas conservan una sola
+	statements := []string{
+		"INSERT IGNORE INTO blog_posts_tags (blog_id, tag_id) SELECT blog_id, ? FROM blog_posts_tags WHERE tag_id = ?",
+		"DELETE FROM blog_posts_tags WHERE tag_id = ?",
+		"DELETE FROM blog_tags WHERE id = ?",
+	}
+	args := [][]interface{}{{targetID, sourceID}, {sourceID}, {sourceID}}
+	for i, stmt := range statements {
+		if _, err := tx.Exec(stmt, args[i]...); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return blogIDs, tx.Commit()
+}
+
+// PruneUnusedTags elimina las etiquetas que ningún blog usa y devuelve cuántas borró
+func (s *Store) PruneUnusedTags() (int64, error) {
+	result, err := s.db.Exec(`
+        DELETE t FROM blog_tags t
+        LEFT JOIN blog_posts_tags pt ON pt.tag_id = t.id
+        WHERE pt.tag_id IS NULL
+    `)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// tagID obtiene el id de una etiqueta por su nombre
+func tagID(tx *sql.Tx, nombre string) (string, error) {
+	var id string
+	err := tx.QueryRow("SELECT id FROM blog_tags WHERE nombre = ? FOR UPDATE", nombre).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("tag not found")
+	}
+	return id, err
+}
+
+// taggedBlogs devuelve los ids de los blogs que usan una etiqueta
+func taggedBlogs(tx *sql.Tx, tagID string) ([]string, error) {
+	rows, err := tx.Query("SELECT blog_id FROM blog_posts_tags WHERE tag_id = ?", tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// escapeLike escapa los comodines de LIKE para buscar el prefijo literal
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
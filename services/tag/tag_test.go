@@ -0,0 +1,21 @@
+package tag
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"go", "go"},
+		{"100%", `100\%`},
+		{"snake_case", `snake\_case`},
+		{`c:\temp`, `c:\\temp`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package transfer importa blogs desde otros sistemas y los exporta. El
+// formato propio es un ZIP de ficheros Markdown con front-matter YAML; además
+// se importan las exportaciones WXR de WordPress.
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// adminAction completa el error de auth.RequireAdmin en las rutas de este handler
+const adminAction = "import and export blogs"
+
+const (
+	// maxImportSize es el tamaño máximo del archivo subido
+	maxImportSize = 64 << 20
+	// formField es el campo multipart que lleva el archivo
+	formField = "archivo"
+)
+
+// Handler maneja la importación y la exportación de blogs
+type Handler struct {
+	blogStore types.BlogStore
+	userStore types.UserStore
+	importer  *Importer
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(blogStore types.BlogStore, categoryStore types.CategoryStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		blogStore: blogStore,
+		userStore: userStore,
+		importer:  NewImporter(blogStore, categoryStore, userStore),
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/admin/import", auth.WithJWTAuth(h.handleImport, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/admin/export", auth.WithJWTAuth(h.handleExport, h.userStore)).Methods(http.MethodGet)
+}
+
+// handleImport importa el archivo del campo "archivo": un ZIP de Markdown o
+// un WXR de WordPress. Con ?dry_run=true devuelve el informe sin guardar nada.
+func (h *Handler) handleImport(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	// El margen cubre las cabeceras multipart; el límite real se comprueba abajo
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize+1<<20)
+
+	file, header, err := r.FormFile(formField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.WriteError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("file must be at most %d MB", maxImportSize>>20))
+			return
+		}
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing %s file", formField))
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportSize+1))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(data) > maxImportSize {
+		utils.WriteError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("file must be at most %d MB", maxImportSize>>20))
+		return
+	}
+
+	var docs []document
+	formato := detectFormat(header.Filename, data)
+	switch formato {
+	case types.FormatoMarkdown:
+		docs, err = readMarkdownZip(data)
+	case types.FormatoWXR:
+		docs, err = readWXR(data)
+	default:
+		utils.WriteError(w, http.StatusUnsupportedMediaType, fmt.Errorf("only zip files with markdown and WordPress WXR exports can be imported"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.importer.Import(docs, formato, auth.GetUserApodoFromContext(r.Context()), dryRun)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, report)
+	if err != nil {
+		return
+	}
+}
+
+// handleExport descarga todos los blogs fuera de la papelera como un ZIP de
+// Markdown con front-matter, que handleImport acepta de vuelta
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	if !auth.RequireAdmin(w, r, h.userStore, adminAction) {
+		return
+	}
+
+	blogs, err := h.blogStore.GetAllBlogs()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Se genera entero antes de responder para poder devolver un error en JSON
+	var buf bytes.Buffer
+	if err := writeMarkdownZip(&buf, blogs); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := fmt.Sprintf("pardalis-export-%s.zip", time.Now().Format(time.DateOnly))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// detectFormat decide el formato por la extensión y, si no la hay, por el
+// contenido. Devuelve "" si no es ninguno de los dos.
+func detectFormat(filename string, data []byte) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".zip":
+		return types.FormatoMarkdown
+	case ".xml", ".wxr":
+		return types.FormatoWXR
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return types.FormatoMarkdown
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("<?xml")), bytes.HasPrefix(bytes.TrimSpace(data), []byte("<rss")):
+		return types.FormatoWXR
+	}
+	return ""
+}
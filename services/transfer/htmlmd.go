@@ -0,0 +1,305 @@
+package transfer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// paragraphBreak marca dentro del texto en línea un salto de párrafo que
+// venía como línea en blanco, como escribe WordPress sin etiquetas <p>
+const paragraphBreak = "\x00"
+
+var (
+	blankLine   = regexp.MustCompile(`\n[ \t]*\n\s*`)
+	whitespace  = regexp.MustCompile(`\s+`)
+	extraBlanks = regexp.MustCompile(`\n{3,}`)
+	// markdownSpecial son los caracteres que en el texto se escapan para que
+	// Markdown no los interprete
+	markdownSpecial = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+)
+
+// htmlToMarkdown convierte el HTML de una entrada de WordPress en el Markdown
+// que se guarda en los blogs. Cubre lo que genera el editor: párrafos,
+// encabezados, listas, citas, código, tablas, enlaces e imágenes. El resto de
+// etiquetas se sustituye por su contenido.
+func htmlToMarkdown(source string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(source), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+
+	markdown := blocks(root)
+	return strings.TrimSpace(extraBlanks.ReplaceAllString(markdown, "\n\n")), nil
+}
+
+// blocks convierte los hijos de n en bloques separados por una línea en
+// blanco. El texto en línea seguido forma un párrafo.
+func blocks(n *html.Node) string {
+	var out []string
+	var run strings.Builder
+
+	flush := func() {
+		for _, paragraph := range strings.Split(run.String(), paragraphBreak) {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				out = append(out, paragraph)
+			}
+		}
+		run.Reset()
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isBlock(c) {
+			flush()
+			if block := strings.TrimSpace(block(c)); block != "" {
+				out = append(out, block)
+			}
+			continue
+		}
+		run.WriteString(inline(c))
+	}
+	flush()
+
+	return strings.Join(out, "\n\n")
+}
+
+func isBlock(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Figure, atom.Figcaption,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Blockquote, atom.Pre, atom.Hr, atom.Table,
+		atom.Script, atom.Style:
+		return true
+	}
+	return false
+}
+
+func block(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return strings.Repeat("#", level) + " " + inlineText(n)
+	case atom.Ul, atom.Ol:
+		return list(n)
+	case atom.Blockquote:
+		return prefixLines(blocks(n), "> ", "> ")
+	case atom.Pre:
+		return codeBlock(n)
+	case atom.Hr:
+		return "* * *"
+	case atom.Table:
+		return table(n)
+	case atom.Script, atom.Style:
+		return ""
+	}
+	return blocks(n)
+}
+
+// inline convierte un nodo en línea. Los saltos de párrafo del texto se
+// conservan como paragraphBreak para que blocks los separe.
+func inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		text := blankLine.ReplaceAllString(n.Data, paragraphBreak)
+		return markdownSpecial.Replace(whitespace.ReplaceAllString(text, " "))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "  \n"
+	case atom.Strong, atom.B:
+		return wrap(children(n), "**")
+	case atom.Em, atom.I:
+		return wrap(children(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrap(children(n), "~~")
+	case atom.Code:
+		return "`" + textContent(n) + "`"
+	case atom.A:
+		text := children(n)
+		href := attr(n, "href")
+		if href == "" {
+			return text
+		}
+		return "[" + strings.TrimSpace(text) + "](" + href + ")"
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" {
+			return ""
+		}
+		return "![" + markdownSpecial.Replace(attr(n, "alt")) + "](" + src + ")"
+	}
+
+	return children(n)
+}
+
+func children(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isBlock(c) {
+			sb.WriteString(" " + strings.TrimSpace(block(c)) + " ")
+			continue
+		}
+		sb.WriteString(inline(c))
+	}
+	return sb.String()
+}
+
+// inlineText es el contenido en línea de n en una sola línea
+func inlineText(n *html.Node) string {
+	text := strings.ReplaceAll(children(n), paragraphBreak, " ")
+	return strings.TrimSpace(whitespace.ReplaceAllString(text, " "))
+}
+
+// wrap rodea el texto con el marcador dejando fuera los espacios, que
+// Markdown no acepta junto a él
+func wrap(text string, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := text[:strings.Index(text, trimmed)]
+	end := text[len(start)+len(trimmed):]
+	return start + marker + trimmed + marker + end
+}
+
+func list(n *html.Node) string {
+	var items []string
+	number := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, prefixLines(blocks(c), marker, indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+// prefixLines antepone first a la primera línea y rest a las demás
+func prefixLines(text string, first string, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeBlock convierte <pre> en un bloque cercado. El lenguaje sale de la
+// clase language-* del <code> interior, como la escribe el editor de bloques.
+func codeBlock(n *html.Node) string {
+	language := ""
+	if code := n.FirstChild; code != nil && code.DataAtom == atom.Code && code.NextSibling == nil {
+		for _, class := range strings.Fields(attr(code, "class")) {
+			if strings.HasPrefix(class, "language-") {
+				language = strings.TrimPrefix(class, "language-")
+			}
+		}
+	}
+
+	code := strings.Trim(textContent(n), "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+// table convierte una tabla en una tabla GFM. La primera fila hace de cabecera.
+func table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.DataAtom == atom.Tr {
+				var cells []string
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+						cells = append(cells, strings.ReplaceAll(inlineText(cell), "|", `\|`))
+					}
+				}
+				rows = append(rows, cells)
+				continue
+			}
+			walk(c)
+		}
+	}
+	walk(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+
+	var lines []string
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom == atom.Br {
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
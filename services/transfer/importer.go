@@ -0,0 +1,315 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/services/content"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// maxExcerptLength es la longitud del extracto que se genera cuando el
+// documento no trae uno
+const maxExcerptLength = 200
+
+// Importer crea blogs a partir de los documentos de un archivo de importación
+type Importer struct {
+	blogStore     types.BlogStore
+	categoryStore types.CategoryStore
+	userStore     types.UserStore
+}
+
+// NewImporter crea una nueva instancia de Importer
+func NewImporter(blogStore types.BlogStore, categoryStore types.CategoryStore, userStore types.UserStore) *Importer {
+	return &Importer{
+		blogStore:     blogStore,
+		categoryStore: categoryStore,
+		userStore:     userStore,
+	}
+}
+
+// importRun es el estado de una importación: lo que ya existe y lo que el
+// propio archivo va creando, para detectar slugs repetidos dentro de él
+type importRun struct {
+	dryRun      bool
+	importador  string
+	categorias  map[string]types.Category // Por slug
+	slugs       map[string]bool
+	autores     map[string]bool
+	report      *types.ImportReport
+	fechaImport time.Time
+}
+
+// Import crea un blog por documento. Un documento que falla no detiene los
+// demás: queda en el informe con su motivo. Con dryRun se valida y resuelve
+// todo igual pero no se guarda nada, ni blogs ni categorías.
+func (im *Importer) Import(docs []document, formato string, importador string, dryRun bool) (*types.ImportReport, error) {
+	report := &types.ImportReport{
+		DryRun:           dryRun,
+		Formato:          formato,
+		CategoriasNuevas: []string{},
+		Entradas:         []types.ImportEntry{},
+	}
+
+	categories, err := im.categoryStore.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &importRun{
+		dryRun:      dryRun,
+		importador:  importador,
+		categorias:  make(map[string]types.Category, len(categories)),
+		slugs:       make(map[string]bool),
+		autores:     make(map[string]bool),
+		report:      report,
+		fechaImport: time.Now(),
+	}
+	for _, category := range categories {
+		run.categorias[category.Slug] = category
+	}
+
+	for _, doc := range docs {
+		entry := im.importDocument(run, doc)
+		switch entry.Accion {
+		case types.AccionCrear:
+			report.Creados++
+		case types.AccionOmitir:
+			report.Omitidos++
+		case types.AccionError:
+			report.Errores++
+		}
+		report.Entradas = append(report.Entradas, entry)
+	}
+
+	return report, nil
+}
+
+// importDocument valida un documento y, salvo en simulación, crea su blog
+func (im *Importer) importDocument(run *importRun, doc document) types.ImportEntry {
+	entry := types.ImportEntry{
+		Origen:    doc.origen,
+		Titulo:    doc.titulo,
+		Estado:    doc.estado,
+		Categoria: doc.categoria,
+	}
+	fail := func(err error) types.ImportEntry {
+		entry.Accion = types.AccionError
+		entry.Motivo = err.Error()
+		return entry
+	}
+
+	if doc.err != nil {
+		return fail(doc.err)
+	}
+	if entry.Estado == "" {
+		entry.Estado = "borrador"
+	}
+	if err := validateDocument(doc, entry.Estado); err != nil {
+		return fail(err)
+	}
+
+	idioma, err := importLocale(doc.idioma)
+	if err != nil {
+		return fail(err)
+	}
+
+	entry.Slug = utils.GenerateSlug(doc.titulo)
+	if doc.slug != "" {
+		entry.Slug = utils.GenerateSlug(doc.slug)
+	}
+	if entry.Slug == "" {
+		return fail(fmt.Errorf("title does not produce a valid slug"))
+	}
+
+	// Un slug ocupado no es un error: el blog ya se importó o existe otro
+	if run.slugs[entry.Slug] {
+		entry.Accion = types.AccionOmitir
+		entry.Motivo = "slug is repeated in the archive"
+		return entry
+	}
+	exists, err := im.blogStore.SlugExists(entry.Slug)
+	if err != nil {
+		return fail(err)
+	}
+	if exists {
+		entry.Accion = types.AccionOmitir
+		entry.Motivo = "slug already exists"
+		return entry
+	}
+
+	entry.Autor, err = im.resolveAutor(run, doc.autor)
+	if err != nil {
+		return fail(err)
+	}
+
+	blog := types.Blog{
+		ID:               uuid.New().String(),
+		Titulo:           doc.titulo,
+		Slug:             entry.Slug,
+		Contenido:        doc.contenido,
+		Extracto:         doc.extracto,
+		ImagenPortada:    doc.portada,
+		FechaPublicacion: doc.fecha,
+		Estado:           entry.Estado,
+		AutorApodo:       entry.Autor,
+		MetaDescripcion:  doc.descripcion,
+		MetaKeywords:     doc.keywords,
+		Tags:             normalizeTags(doc.tags),
+		Version:          1,
+		Idioma:           idioma,
+	}
+	if blog.FechaPublicacion.IsZero() {
+		blog.FechaPublicacion = run.fechaImport
+	}
+	if blog.Extracto == "" {
+		blog.Extracto = excerpt(doc.contenido)
+	}
+
+	rendered, err := content.Render(blog.Contenido)
+	if err != nil {
+		return fail(err)
+	}
+	blog.ContenidoHTML = rendered.HTML
+	blog.TablaContenidos = rendered.TablaContenidos
+	blog.TiempoLectura = rendered.TiempoLectura
+
+	blog.Bloques, err = content.NormalizeBlocks(blog.Contenido, doc.bloques)
+	if err != nil {
+		return fail(err)
+	}
+
+	// La categoría va lo último para no crear categorías de documentos que fallan
+	category, err := im.resolveCategory(run, doc.categoria)
+	if err != nil {
+		return fail(err)
+	}
+	blog.CategoriaID = category.ID
+	blog.Categoria = category.Nombre
+	entry.Categoria = category.Nombre
+
+	run.slugs[entry.Slug] = true
+	entry.Accion = types.AccionCrear
+	if run.dryRun {
+		return entry
+	}
+
+	if err := im.blogStore.CreateBlog(blog); err != nil {
+		delete(run.slugs, entry.Slug)
+		return fail(err)
+	}
+	entry.BlogID = blog.ID
+
+	return entry
+}
+
+// validateDocument aplica a un documento las reglas de CreateBlogPayload
+func validateDocument(doc document, estado string) error {
+	switch {
+	case doc.titulo == "":
+		return fmt.Errorf("missing title")
+	case doc.contenido == "":
+		return fmt.Errorf("missing content")
+	case doc.categoria == "":
+		return fmt.Errorf("missing category")
+	case estado != "borrador" && estado != "publicado":
+		return fmt.Errorf("estado must be borrador or publicado")
+	}
+	return nil
+}
+
+// resolveAutor usa el autor del documento si tiene cuenta en Pardalis y, si
+// no, a quien importa
+func (im *Importer) resolveAutor(run *importRun, autor string) (string, error) {
+	if autor == "" || autor == run.importador {
+		return run.importador, nil
+	}
+
+	exists, ok := run.autores[autor]
+	if !ok {
+		_, err := im.userStore.GetUserByApodo(autor)
+		exists = err == nil
+		run.autores[autor] = exists
+	}
+	if !exists {
+		return run.importador, nil
+	}
+	return autor, nil
+}
+
+// resolveCategory busca la categoría por su slug, que es el mismo para el
+// nombre y para el slug, y la crea si no existe
+func (im *Importer) resolveCategory(run *importRun, nombre string) (types.Category, error) {
+	slug := utils.GenerateSlug(nombre)
+	if category, ok := run.categorias[slug]; ok {
+		return category, nil
+	}
+
+	category := types.Category{
+		ID:     uuid.New().String(),
+		Nombre: nombre,
+		Slug:   slug,
+	}
+	if !run.dryRun {
+		if err := im.categoryStore.CreateCategory(category); err != nil {
+			return types.Category{}, err
+		}
+	}
+
+	run.categorias[slug] = category
+	run.report.CategoriasNuevas = append(run.report.CategoriasNuevas, nombre)
+	return category, nil
+}
+
+// importLocale valida el idioma del documento; vacío es el idioma por defecto
+func importLocale(idioma string) (string, error) {
+	if idioma == "" {
+		if len(configs.Envs.Locales) == 0 {
+			return "es", nil
+		}
+		return configs.Envs.Locales[0], nil
+	}
+
+	locale := utils.MatchLocale([]string{strings.ToLower(idioma)}, configs.Envs.Locales)
+	if locale == "" {
+		return "", fmt.Errorf("unsupported locale %q", idioma)
+	}
+	return locale, nil
+}
+
+// normalizeTags quita espacios, vacíos y repetidos, y descarta las etiquetas
+// que no caben en blog_tags
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > 100 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// excerpt genera un extracto con el principio del texto, cortado entre palabras
+func excerpt(markdown string) string {
+	text := strings.Join(strings.Fields(content.PlainText(markdown)), " ")
+	if utf8.RuneCountInString(text) <= maxExcerptLength {
+		return text
+	}
+
+	runes := []rune(text)[:maxExcerptLength]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, ",.;:") + "…"
+}
@@ -0,0 +1,336 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxDocumentSize es el tamaño máximo de cada fichero descomprimido
+	maxDocumentSize = 2 << 20
+	// maxDocuments es el número máximo de documentos de un archivo
+	maxDocuments = 2000
+	// maxArchiveSize es lo que pueden ocupar todos los ficheros descomprimidos
+	maxArchiveSize = 128 << 20
+)
+
+// frontMatterDelimiter abre y cierra la cabecera YAML de un fichero Markdown
+const frontMatterDelimiter = "---"
+
+// frontMatter es la cabecera YAML de los ficheros Markdown, tanto al importar
+// como al exportar. Solo title es obligatorio.
+type frontMatter struct {
+	Title       string   `yaml:"title"`
+	Slug        string   `yaml:"slug,omitempty"`
+	Date        string   `yaml:"date,omitempty"`
+	Estado      string   `yaml:"estado,omitempty"`
+	Category    string   `yaml:"category,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Author      string   `yaml:"author,omitempty"`
+	Lang        string   `yaml:"lang,omitempty"`
+	Excerpt     string   `yaml:"excerpt,omitempty"`
+	Cover       string   `yaml:"cover,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Keywords    string   `yaml:"keywords,omitempty"`
+	// Blocks son los bloques interactivos con el mismo esquema que en la API
+	Blocks []map[string]any `yaml:"blocks,omitempty"`
+}
+
+// document es un blog leído de un archivo de importación, aún sin validar
+type document struct {
+	origen      string
+	titulo      string
+	slug        string
+	fecha       time.Time
+	estado      string
+	categoria   string
+	tags        []string
+	autor       string
+	idioma      string
+	extracto    string
+	portada     string
+	descripcion string
+	keywords    string
+	contenido   string // Markdown
+	bloques     []types.Bloque
+	err         error // El documento no se pudo leer
+}
+
+// dateLayouts son los formatos de fecha aceptados en el front-matter
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// readMarkdownZip lee los ficheros .md de un ZIP. Los que no se pueden leer
+// vuelven con err para que aparezcan en el informe.
+func readMarkdownZip(data []byte) ([]document, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip file")
+	}
+
+	var docs []document
+	var total int64
+	for _, file := range reader.File {
+		name := file.Name
+		if file.FileInfo().IsDir() || !isMarkdown(name) || isHidden(name) {
+			continue
+		}
+		if len(docs) == maxDocuments {
+			return nil, fmt.Errorf("at most %d documents can be imported at once", maxDocuments)
+		}
+
+		source, err := readZipFile(file, maxArchiveSize-total)
+		total += int64(len(source))
+		if total > maxArchiveSize {
+			return nil, fmt.Errorf("zip file must expand to at most %d MB", maxArchiveSize>>20)
+		}
+		if err != nil {
+			docs = append(docs, document{origen: name, err: err})
+			continue
+		}
+
+		docs = append(docs, parseMarkdown(name, source))
+	}
+
+	// Orden estable para que el informe no dependa de cómo se comprimió
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].origen < docs[j].origen })
+
+	return docs, nil
+}
+
+// readZipFile descomprime un fichero sin pasar de maxDocumentSize ni de lo
+// que queda del archivo, que es lo que protege de los ZIP que se expanden
+// hasta llenar la memoria. Si se pasa de remaining devuelve un byte más para
+// que quien llama lo note.
+func readZipFile(file *zip.File, remaining int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(rc, min(maxDocumentSize, remaining)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocumentSize {
+		return nil, fmt.Errorf("file must be at most %d MB", maxDocumentSize>>20)
+	}
+	return data, nil
+}
+
+func isMarkdown(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// isHidden descarta los ficheros ocultos y los que añade macOS al comprimir
+func isHidden(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMarkdown separa el front-matter del contenido. Sin front-matter el
+// documento queda sin título y la importación lo rechaza.
+func parseMarkdown(name string, source []byte) document {
+	doc := document{origen: name}
+
+	text := strings.TrimPrefix(strings.ReplaceAll(string(source), "\r\n", "\n"), "\ufeff")
+	header, body, ok := splitFrontMatter(text)
+	if !ok {
+		doc.contenido = strings.TrimSpace(text)
+		return doc
+	}
+
+	var fm frontMatter
+	if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+		doc.err = fmt.Errorf("invalid front-matter: %v", err)
+		return doc
+	}
+
+	doc.titulo = strings.TrimSpace(fm.Title)
+	doc.slug = strings.TrimSpace(fm.Slug)
+	doc.estado = strings.ToLower(strings.TrimSpace(fm.Estado))
+	doc.categoria = strings.TrimSpace(fm.Category)
+	doc.tags = fm.Tags
+	doc.autor = strings.TrimSpace(fm.Author)
+	doc.idioma = strings.TrimSpace(fm.Lang)
+	doc.extracto = strings.TrimSpace(fm.Excerpt)
+	doc.portada = strings.TrimSpace(fm.Cover)
+	doc.descripcion = strings.TrimSpace(fm.Description)
+	doc.keywords = strings.TrimSpace(fm.Keywords)
+	doc.contenido = strings.TrimSpace(body)
+
+	bloques, err := blocksFromFrontMatter(fm.Blocks)
+	if err != nil {
+		doc.err = err
+		return doc
+	}
+	doc.bloques = bloques
+
+	if fm.Date != "" {
+		fecha, err := parseDate(fm.Date)
+		if err != nil {
+			doc.err = err
+			return doc
+		}
+		doc.fecha = fecha
+	}
+
+	return doc
+}
+
+// splitFrontMatter devuelve la cabecera YAML y el cuerpo de un documento que
+// empieza por "---"
+func splitFrontMatter(text string) (string, string, bool) {
+	if !strings.HasPrefix(text, frontMatterDelimiter+"\n") {
+		return "", "", false
+	}
+
+	rest := text[len(frontMatterDelimiter)+1:]
+	if strings.HasPrefix(rest, frontMatterDelimiter+"\n") {
+		return "", rest[len(frontMatterDelimiter)+1:], true
+	}
+
+	end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+	if end == -1 {
+		if strings.HasSuffix(rest, "\n"+frontMatterDelimiter) {
+			return rest[:len(rest)-len(frontMatterDelimiter)-1], "", true
+		}
+		return "", "", false
+	}
+
+	return rest[:end], rest[end+len(frontMatterDelimiter)+2:], true
+}
+
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if fecha, err := time.Parse(layout, value); err == nil {
+			return fecha, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// renderMarkdown escribe un blog como Markdown con front-matter, el formato
+// que readMarkdownZip vuelve a importar
+func renderMarkdown(blog types.Blog) ([]byte, error) {
+	fm := frontMatter{
+		Title:       blog.Titulo,
+		Slug:        blog.Slug,
+		Date:        blog.FechaPublicacion.UTC().Format(time.RFC3339),
+		Estado:      blog.Estado,
+		Category:    blog.Categoria,
+		Tags:        blog.Tags,
+		Author:      blog.AutorApodo,
+		Lang:        blog.Idioma,
+		Excerpt:     blog.Extracto,
+		Cover:       blog.ImagenPortada,
+		Description: blog.MetaDescripcion,
+		Keywords:    blog.MetaKeywords,
+	}
+
+	blocks, err := blocksToFrontMatter(blog.Bloques)
+	if err != nil {
+		return nil, err
+	}
+	fm.Blocks = blocks
+
+	header, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(header)
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(strings.TrimSpace(blog.Contenido))
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+// blocksToFrontMatter pasa los bloques a mapas con las claves JSON de la API,
+// que es como se escriben en el front-matter
+func blocksToFrontMatter(bloques []types.Bloque) ([]map[string]any, error) {
+	if len(bloques) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(bloques)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []map[string]any
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// blocksFromFrontMatter lee los bloques del front-matter. Solo comprueba el
+// formato; la importación los valida con content.NormalizeBlocks.
+func blocksFromFrontMatter(blocks []map[string]any) ([]types.Bloque, error) {
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blocks: %v", err)
+	}
+
+	var bloques []types.Bloque
+	if err := json.Unmarshal(data, &bloques); err != nil {
+		return nil, fmt.Errorf("invalid blocks: %v", err)
+	}
+	return bloques, nil
+}
+
+// writeMarkdownZip escribe un ZIP con un fichero <slug>.md por blog
+func writeMarkdownZip(w io.Writer, blogs []types.Blog) error {
+	archive := zip.NewWriter(w)
+
+	for _, blog := range blogs {
+		source, err := renderMarkdown(blog)
+		if err != nil {
+			return err
+		}
+
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     blog.Slug + ".md",
+			Method:   zip.Deflate,
+			Modified: blog.FechaActualizacion,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(source); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,329 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	source := "---\r\n" +
+		"title: Fotosíntesis\r\n" +
+		"date: 2024-03-15\r\n" +
+		"estado: Publicado\r\n" +
+		"category: Ciencias\r\n" +
+		"tags: [biología, plantas]\r\n" +
+		"lang: en\r\n" +
+		"---\r\n" +
+		"\r\n" +
+		"# Fotosíntesis\r\n\r\nLas plantas convierten la luz.\r\n"
+
+	doc := parseMarkdown("ciencias/fotosintesis.md", []byte(source))
+	if doc.err != nil {
+		t.Fatalf("parseMarkdown() error = %v", doc.err)
+	}
+
+	want := document{
+		origen:    "ciencias/fotosintesis.md",
+		titulo:    "Fotosíntesis",
+		fecha:     time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		estado:    "publicado",
+		categoria: "Ciencias",
+		tags:      []string{"biología", "plantas"},
+		idioma:    "en",
+		contenido: "# Fotosíntesis\n\nLas plantas convierten la luz.",
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("parseMarkdown() = %+v\nwant %+v", doc, want)
+	}
+}
+
+func TestParseMarkdown_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"fecha inválida":       "---\ntitle: Hola\ndate: ayer\n---\nTexto",
+		"yaml inválido":        "---\ntitle: [sin cerrar\n---\nTexto",
+		"bloques mal formados": "---\ntitle: Hola\nblocks:\n  - tipo: [codigo]\n---\nTexto",
+	}
+
+	for name, source := range tests {
+		if doc := parseMarkdown("x.md", []byte(source)); doc.err == nil {
+			t.Errorf("%s: parseMarkdown() should fail", name)
+		}
+	}
+
+	// Sin front-matter no hay título, que es lo que rechaza la importación
+	doc := parseMarkdown("x.md", []byte("# Solo contenido"))
+	if doc.err != nil || doc.titulo != "" || doc.contenido != "# Solo contenido" {
+		t.Errorf("parseMarkdown() without front-matter = %+v", doc)
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		text       string
+		wantHeader string
+		wantBody   string
+		wantOK     bool
+	}{
+		{"---\ntitle: a\n---\nbody", "title: a", "body", true},
+		{"---\n---\nbody", "", "body", true},
+		{"---\ntitle: a\n---", "title: a", "", true},
+		{"---\ntitle: a\nbody", "", "", false},
+		{"title: a\n---\nbody", "", "", false},
+		{"---\ntitle: a\n---\nuno\n---\ndos", "title: a", "uno\n---\ndos", true},
+	}
+
+	for _, tt := range tests {
+		header, body, ok := splitFrontMatter(tt.text)
+		if header != tt.wantHeader || body != tt.wantBody || ok != tt.wantOK {
+			t.Errorf("splitFrontMatter(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.text, header, body, ok, tt.wantHeader, tt.wantBody, tt.wantOK)
+		}
+	}
+}
+
+func TestMarkdownZipRoundTrip(t *testing.T) {
+	blog := types.Blog{
+		Titulo:             "Verbos: presente",
+		Slug:               "verbos-presente",
+		Contenido:          "## Presente\n\nYo *hablo*.\n\n{{bloque:repaso}}",
+		Extracto:           "Los verbos en presente",
+		FechaPublicacion:   time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC),
+		FechaActualizacion: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		Estado:             "publicado",
+		Categoria:          "Lengua",
+		AutorApodo:         "ana",
+		Tags:               []string{"gramática"},
+		Idioma:             "es",
+		Bloques: types.Bloques{
+			{ID: "repaso", Tipo: types.BloqueTipoPregunta, Pregunta: &types.BloquePregunta{
+				Enunciado: "¿Cuál está en presente?",
+				Opciones:  []types.OpcionPregunta{{ID: "a", Texto: "hablo", Correcta: true}, {ID: "b", Texto: "hablé"}},
+			}},
+			{ID: "bloque-1", Tipo: types.BloqueTipoVideo, Video: &types.BloqueVideo{
+				URL: "https://youtu.be/dQw4w9WgXcQ", Inicio: 42, Proveedor: types.VideoYouTube, VideoID: "dQw4w9WgXcQ",
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeMarkdownZip(&buf, []types.Blog{blog}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := readMarkdownZip(buf.Bytes())
+	if err != nil {
+		t.Fatalf("readMarkdownZip() error = %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("readMarkdownZip() returned %d documents, want 1", len(docs))
+	}
+
+	want := document{
+		origen:    "verbos-presente.md",
+		titulo:    blog.Titulo,
+		slug:      blog.Slug,
+		fecha:     blog.FechaPublicacion,
+		estado:    blog.Estado,
+		categoria: blog.Categoria,
+		tags:      blog.Tags,
+		autor:     blog.AutorApodo,
+		idioma:    blog.Idioma,
+		extracto:  blog.Extracto,
+		contenido: blog.Contenido,
+		bloques:   blog.Bloques,
+	}
+	if !reflect.DeepEqual(docs[0], want) {
+		t.Errorf("round trip = %+v\nwant %+v", docs[0], want)
+	}
+}
+
+func TestReadMarkdownZip_SkipsOtherFiles(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range []string{"b.md", "a.markdown", "imagen.png", "__MACOSX/._a.md", ".oculto.md"} {
+		f, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write([]byte("---\ntitle: " + name + "\n---\nTexto"))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := readMarkdownZip(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, doc := range docs {
+		names = append(names, doc.origen)
+	}
+	if want := []string{"a.markdown", "b.md"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("readMarkdownZip() read %v, want %v", names, want)
+	}
+
+	if _, err := readMarkdownZip([]byte("no es un zip")); err == nil {
+		t.Errorf("readMarkdownZip() should reject invalid zip files")
+	}
+}
+
+func TestReadMarkdownZip_ArchiveSizeLimit(t *testing.T) {
+	// Cada fichero cabe en maxDocumentSize, pero entre todos se pasan del límite
+	content := bytes.Repeat([]byte("a"), maxDocumentSize)
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for i := 0; i <= maxArchiveSize/maxDocumentSize; i++ {
+		f, err := archive.Create(fmt.Sprintf("%03d.md", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write(content)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readMarkdownZip(buf.Bytes()); err == nil {
+		t.Errorf("readMarkdownZip() should reject archives that expand past %d MB", maxArchiveSize>>20)
+	}
+}
+
+func TestReadWXR(t *testing.T) {
+	wxr := `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Mi blog</title>
+	<item>
+		<title>Hola mundo</title>
+		<dc:creator><![CDATA[ana]]></dc:creator>
+		<content:encoded><![CDATA[<p>Primer <strong>párrafo</strong>.</p>]]></content:encoded>
+		<excerpt:encoded><![CDATA[Un saludo]]></excerpt:encoded>
+		<wp:post_date><![CDATA[2019-05-04 12:00:00]]></wp:post_date>
+		<wp:post_name><![CDATA[hola-mundo]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="general"><![CDATA[General]]></category>
+		<category domain="post_tag" nicename="intro"><![CDATA[intro]]></category>
+	</item>
+	<item>
+		<title>Acerca de</title>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+	</item>
+	<item>
+		<title>Borrado</title>
+		<wp:status><![CDATA[trash]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+	docs, err := readWXR([]byte(wxr))
+	if err != nil {
+		t.Fatalf("readWXR() error = %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("readWXR() returned %d documents, want 1", len(docs))
+	}
+
+	want := document{
+		origen:    "item 1 (hola-mundo)",
+		titulo:    "Hola mundo",
+		slug:      "hola-mundo",
+		fecha:     time.Date(2019, 5, 4, 12, 0, 0, 0, time.UTC),
+		estado:    "publicado",
+		categoria: "General",
+		tags:      []string{"intro"},
+		autor:     "ana",
+		extracto:  "Un saludo",
+		contenido: "Primer **párrafo**.",
+	}
+	if !reflect.DeepEqual(docs[0], want) {
+		t.Errorf("readWXR() = %+v\nwant %+v", docs[0], want)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"párrafos", "<p>Uno</p><p>Dos <em>tres</em></p>", "Uno\n\nDos *tres*"},
+		{"sin etiquetas p", "Uno\n\nDos\nsigue", "Uno\n\nDos sigue"},
+		{"encabezado", "<h2>Título <b>fuerte</b></h2>", "## Título **fuerte**"},
+		{"enlace e imagen", `<p><a href="https://a.com">A</a> <img src="/i.png" alt="Foto"></p>`, "[A](https://a.com) ![Foto](/i.png)"},
+		{"listas", "<ul><li>a</li><li>b<ol><li>c</li></ol></li></ul>", "- a\n- b\n\n  1. c"},
+		{"cita", "<blockquote><p>Uno</p><p>Dos</p></blockquote>", "> Uno\n>\n> Dos"},
+		{"código", `<pre><code class="language-go">if a < b {
+}</code></pre>`, "```go\nif a < b {\n}\n```"},
+		{"tabla", "<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></table>", "| A | B |\n| --- | --- |\n| 1 | 2 |"},
+		{"escapes", "<p>2*3 y a_b</p>", `2\*3 y a\_b`},
+		{"comentarios de bloques", "<!-- wp:paragraph --><p>Hola</p><!-- /wp:paragraph -->", "Hola"},
+		{"script", "<p>Hola</p><script>alert(1)</script>", "Hola"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := htmlToMarkdown(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("htmlToMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+		want     string
+	}{
+		{"blogs.zip", "", types.FormatoMarkdown},
+		{"wordpress.xml", "", types.FormatoWXR},
+		{"archivo", "PK\x03\x04resto", types.FormatoMarkdown},
+		{"archivo", "  <?xml version=\"1.0\"?>", types.FormatoWXR},
+		{"archivo.txt", "hola", ""},
+	}
+
+	for _, tt := range tests {
+		if got := detectFormat(tt.filename, []byte(tt.data)); got != tt.want {
+			t.Errorf("detectFormat(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := normalizeTags([]string{" Go ", "go", "", "SQL", strings.Repeat("x", 101)})
+	if want := []string{"Go", "SQL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeTags() = %v, want %v", got, want)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := excerpt("# Título\n\nTexto **corto**."); got != "Título Texto corto." {
+		t.Errorf("excerpt() = %q", got)
+	}
+
+	long := strings.Repeat("palabra ", 60)
+	got := excerpt(long)
+	if !strings.HasSuffix(got, "palabra…") || len([]rune(got)) > maxExcerptLength+1 {
+		t.Errorf("excerpt() = %q", got)
+	}
+}
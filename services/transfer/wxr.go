@@ -0,0 +1,132 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// wxrDateLayout es el formato de wp:post_date
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+// wxrEstados traduce wp:status a los estados de los blogs. Lo que no aparece
+// (papelera, revisiones automáticas) no se importa.
+var wxrEstados = map[string]string{
+	"publish": "publicado",
+	"future":  "borrador",
+	"draft":   "borrador",
+	"pending": "borrador",
+	"private": "borrador",
+}
+
+// wxrDocument es la parte de una exportación de WordPress que se importa
+type wxrDocument struct {
+	Items []wxrItem `xml:"channel>item"`
+}
+
+// wxrItem es una entrada. Los elementos se leen por su nombre local, sin el
+// espacio de nombres, porque la URL de wp: cambia con la versión del formato.
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Creator    string        `xml:"creator"`
+	Encoded    []wxrEncoded  `xml:"encoded"` // content:encoded y excerpt:encoded
+	PostName   string        `xml:"post_name"`
+	PostDate   string        `xml:"post_date"`
+	Status     string        `xml:"status"`
+	PostType   string        `xml:"post_type"`
+	Categories []wxrCategory `xml:"category"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Text    string `xml:",chardata"`
+}
+
+type wxrCategory struct {
+	Domain string `xml:"domain,attr"`
+	Name   string `xml:",chardata"`
+}
+
+// readWXR lee las entradas de una exportación de WordPress. Las páginas, los
+// adjuntos y lo que está en la papelera se ignoran.
+func readWXR(data []byte) ([]document, error) {
+	var wxr wxrDocument
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&wxr); err != nil {
+		return nil, fmt.Errorf("invalid WXR file: %v", err)
+	}
+
+	var docs []document
+	for i, item := range wxr.Items {
+		if item.PostType != "post" {
+			continue
+		}
+		estado, ok := wxrEstados[item.Status]
+		if !ok {
+			continue
+		}
+		if len(docs) == maxDocuments {
+			return nil, fmt.Errorf("at most %d documents can be imported at once", maxDocuments)
+		}
+
+		docs = append(docs, wxrToDocument(i, item, estado))
+	}
+
+	return docs, nil
+}
+
+func wxrToDocument(i int, item wxrItem, estado string) document {
+	doc := document{
+		origen: fmt.Sprintf("item %d", i+1),
+		titulo: strings.TrimSpace(item.Title),
+		slug:   strings.TrimSpace(item.PostName),
+		estado: estado,
+		autor:  strings.TrimSpace(item.Creator),
+	}
+
+	var content string
+	for _, encoded := range item.Encoded {
+		switch {
+		case strings.Contains(encoded.XMLName.Space, "excerpt"):
+			doc.extracto = strings.TrimSpace(encoded.Text)
+		case strings.Contains(encoded.XMLName.Space, "content"):
+			content = encoded.Text
+		}
+	}
+
+	if doc.slug != "" {
+		doc.origen += " (" + doc.slug + ")"
+	}
+
+	for _, category := range item.Categories {
+		name := strings.TrimSpace(category.Name)
+		switch category.Domain {
+		case "category":
+			// Los blogs tienen una sola categoría; se queda la primera
+			if doc.categoria == "" {
+				doc.categoria = name
+			}
+		case "post_tag":
+			doc.tags = append(doc.tags, name)
+		}
+	}
+
+	if item.PostDate != "" && !strings.HasPrefix(item.PostDate, "0000") {
+		fecha, err := time.Parse(wxrDateLayout, item.PostDate)
+		if err != nil {
+			doc.err = fmt.Errorf("invalid date %q", item.PostDate)
+			return doc
+		}
+		doc.fecha = fecha
+	}
+
+	contenido, err := htmlToMarkdown(content)
+	if err != nil {
+		doc.err = err
+		return doc
+	}
+	doc.contenido = contenido
+
+	return doc
+}
@@ -0,0 +1,216 @@
+// Package trash gestiona la papelera: los blogs y las cuentas eliminados se
+// pueden restaurar durante un periodo de retención, pasado el cual se borran.
+package trash
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/pardalis/pardalis-api/configs"
+	"gitlab.com/pardalis/pardalis-api/services/auth"
+	"gitlab.com/pardalis/pardalis-api/services/collaborator"
+	"gitlab.com/pardalis/pardalis-api/types"
+	"gitlab.com/pardalis/pardalis-api/utils"
+)
+
+// Handler maneja la papelera y la eliminación de cuentas
+type Handler struct {
+	blogStore  types.BlogStore
+	userStore  types.UserStore
+	authorizer *collaborator.Authorizer
+	index      types.SearchIndex
+	retention  time.Duration
+}
+
+// NewHandler crea una nueva instancia de Handler
+func NewHandler(blogStore types.BlogStore, userStore types.UserStore, authorizer *collaborator.Authorizer, index types.SearchIndex, retention time.Duration) *Handler {
+	return &Handler{
+		blogStore:  blogStore,
+		userStore:  userStore,
+		authorizer: authorizer,
+		index:      index,
+		retention:  retention,
+	}
+}
+
+// RegisterRoutes registra las rutas del handler
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/me/trash", auth.WithJWTAuth(h.handleGetTrash, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/blogs/{id}/restore", auth.WithJWTAuth(h.handleRestoreBlog, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/me", auth.WithJWTAuth(h.handleDeleteAccount, h.userStore)).Methods(http.MethodDelete)
+	// Una cuenta eliminada no puede autenticarse, así que se restaura con sus credenciales
+	router.HandleFunc("/me/restore", h.handleRestoreAccount).Methods(http.MethodPost)
+}
+
+// handleGetTrash lista los blogs eliminados del usuario que aún se pueden restaurar
+func (h *Handler) handleGetTrash(w http.ResponseWriter, r *http.Request) {
+	blogs, err := h.blogStore.GetDeletedBlogs(auth.GetUserApodoFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, types.Papelera{
+		Blogs:         blogs,
+		DiasRetencion: int64(h.retention / (24 * time.Hour)),
+	})
+	if err != nil {
+		return
+	}
+}
+
+// handleRestoreBlog saca un blog de la papelera. Puede hacerlo quien podía eliminarlo.
+func (h *Handler) handleRestoreBlog(w http.ResponseWriter, r *http.Request) {
+	blog, err := h.blogStore.GetDeletedBlogByID(mux.Vars(r)["id"])
+	if err != nil {
+		if err.Error() == "blog not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	canDelete, err := h.authorizer.Can(*blog, auth.GetUserApodoFromContext(r.Context()), types.PermisoEliminar)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !canDelete {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("not authorized to restore this blog"))
+		return
+	}
+
+	if err := h.blogStore.RestoreBlog(blog.ID); err != nil {
+		if err.Error() == "blog not found" {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	restored, err := h.blogStore.GetBlogByID(blog.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, restored)
+	if err != nil {
+		return
+	}
+}
+
+// handleDeleteAccount manda la cuenta del usuario y sus blogs a la papelera.
+// Pide la contraseña aunque la petición esté autenticada.
+func (h *Handler) handleDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	var payload types.DeleteAccountPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.userStore.GetUserByApodo(auth.GetUserApodoFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !auth.ComparePasswords(user.Contrasenna, []byte(payload.Contrasenna)) {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("invalid password"))
+		return
+	}
+
+	if err := h.userStore.DeleteUser(user.Apodo); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Los blogs se fueron con la cuenta; dejan de aparecer en las búsquedas
+	blogs, err := h.blogStore.GetDeletedBlogs(user.Apodo)
+	if err != nil {
+		log.Printf("Error al leer los blogs eliminados de %s: %v", user.Apodo, err)
+	}
+	for _, blog := range blogs {
+		if err := h.index.Remove(blog.ID); err != nil {
+			log.Printf("Error al eliminar el blog %s del índice: %v", blog.ID, err)
+		}
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Account moved to trash"})
+	if err != nil {
+		return
+	}
+}
+
+// handleRestoreAccount restaura una cuenta de la papelera con sus blogs y
+// devuelve un token como el inicio de sesión
+func (h *Handler) handleRestoreAccount(w http.ResponseWriter, r *http.Request) {
+	var payload types.LoginUserPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.userStore.GetDeletedUserByCorreo(payload.Correo)
+	if err != nil || !auth.ComparePasswords(user.Contrasenna, []byte(payload.Contrasenna)) {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid email or password"))
+		return
+	}
+
+	// Se leen antes de restaurar: después ya no se distinguen de los demás
+	blogs, err := h.blogStore.GetDeletedBlogs(user.Apodo)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := h.userStore.RestoreUser(user.Apodo); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, blog := range deletedWith(blogs, *user.EliminadoEn) {
+		if err := h.index.Index(blog); err != nil {
+			log.Printf("Error al indexar el blog %s: %v", blog.ID, err)
+		}
+	}
+
+	token, err := auth.CreateJWT([]byte(configs.Envs.JWTSecret), user.Apodo)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, map[string]string{"token": token})
+	if err != nil {
+		return
+	}
+}
+
+// deletedWith devuelve los blogs que se eliminaron junto con la cuenta, que
+// son los que RestoreUser devuelve a su sitio
+func deletedWith(blogs []types.Blog, eliminadoEn time.Time) []types.Blog {
+	var restored []types.Blog
+	for _, blog := range blogs {
+		if blog.EliminadoEn != nil && blog.EliminadoEn.Equal(eliminadoEn) {
+			blog.EliminadoEn = nil
+			restored = append(restored, blog)
+		}
+	}
+	return restored
+}
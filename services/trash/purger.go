@@ -0,0 +1,58 @@
+package trash
+
+import (
+	"log"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// purgeInterval es cada cuánto se vacía lo que ha superado la retención
+const purgeInterval = time.Hour
+
+// Purger borra definitivamente los blogs y las cuentas que llevan en la
+// papelera más tiempo que el periodo de retención
+type Purger struct {
+	blogStore types.BlogStore
+	userStore types.UserStore
+	retention time.Duration
+}
+
+// NewPurger crea una nueva instancia de Purger
+func NewPurger(blogStore types.BlogStore, userStore types.UserStore, retention time.Duration) *Purger {
+	return &Purger{
+		blogStore: blogStore,
+		userStore: userStore,
+		retention: retention,
+	}
+}
+
+// Start purga al arrancar y después cada purgeInterval
+func (p *Purger) Start() {
+	go func() {
+		p.purge(time.Now())
+		for now := range time.Tick(purgeInterval) {
+			p.purge(now)
+		}
+	}()
+}
+
+// purge borra primero las cuentas, que se llevan sus blogs, y después los
+// blogs eliminados de uno en uno
+func (p *Purger) purge(now time.Time) {
+	before := now.Add(-p.retention)
+
+	users, err := p.userStore.PurgeDeletedUsers(before)
+	if err != nil {
+		log.Printf("Error al purgar las cuentas eliminadas: %v", err)
+	}
+
+	blogs, err := p.blogStore.PurgeDeletedBlogs(before)
+	if err != nil {
+		log.Printf("Error al purgar los blogs eliminados: %v", err)
+	}
+
+	if users > 0 || blogs > 0 {
+		log.Printf("Papelera vaciada: %d cuentas y %d blogs", users, blogs)
+	}
+}
@@ -0,0 +1,30 @@
+package trash
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestDeletedWith(t *testing.T) {
+	cuenta := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	antes := cuenta.Add(-48 * time.Hour)
+
+	blogs := []types.Blog{
+		{ID: "con-la-cuenta", EliminadoEn: &cuenta},
+		{ID: "eliminado-antes", EliminadoEn: &antes},
+		{ID: "sin-fecha"},
+	}
+
+	got := deletedWith(blogs, cuenta.In(time.FixedZone("CEST", 2*3600)))
+	if len(got) != 1 || got[0].ID != "con-la-cuenta" {
+		t.Fatalf("deletedWith() = %+v, want only the blog deleted with the account", got)
+	}
+	if got[0].EliminadoEn != nil {
+		t.Errorf("deletedWith() kept EliminadoEn on a restored blog")
+	}
+	if blogs[0].EliminadoEn == nil {
+		t.Errorf("deletedWith() modified its input")
+	}
+}
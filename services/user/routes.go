@@ -3,6 +3,8 @@ package user
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -97,6 +99,22 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// GetUserByCorreo no mira en la papelera, pero el correo sigue ocupado hasta la purga 🗑️
+	if _, err := h.store.GetDeletedUserByCorreo(user.Correo); err == nil {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("account with email %s is pending deletion, restore it at /me/restore", user.Correo))
+		return
+	}
+
+	var fechaNacimiento *time.Time
+	if user.FechaNacimiento != "" {
+		t, err := time.Parse("2006-01-02", user.FechaNacimiento)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid fecha_nacimiento: %v", err))
+			return
+		}
+		fechaNacimiento = &t
+	}
+
 	hashedPassword, err := auth.HashPassword(user.Contrasenna)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
@@ -104,13 +122,19 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = h.store.CreateUser(types.User{
-		Apodo:       user.Apodo,
-		Nombre:      user.Nombre,
-		Correo:      user.Correo,
-		Contrasenna: hashedPassword,
+		Apodo:           user.Apodo,
+		Nombre:          user.Nombre,
+		Correo:          user.Correo,
+		Contrasenna:     hashedPassword,
+		FechaNacimiento: fechaNacimiento,
 	})
 
 	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			// El apodo ya es de otra cuenta, quizá una que está en la papelera
+			utils.WriteError(w, http.StatusConflict, fmt.Errorf("apodo %s is already taken", user.Apodo))
+			return
+		}
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
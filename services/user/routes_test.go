@@ -0,0 +1,67 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// fakeUserStore embebe la interfaz: un método que el test no espera hace panic
+type fakeUserStore struct {
+	types.UserStore
+	borrada   bool
+	createErr error
+	created   bool
+}
+
+func (s *fakeUserStore) GetUserByCorreo(string) (*types.User, error) {
+	return nil, fmt.Errorf("user not found")
+}
+
+func (s *fakeUserStore) GetDeletedUserByCorreo(correo string) (*types.User, error) {
+	if s.borrada {
+		return &types.User{Correo: correo}, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (s *fakeUserStore) CreateUser(types.User) error {
+	s.created = s.createErr == nil
+	return s.createErr
+}
+
+func TestRegisterTakenAccount(t *testing.T) {
+	tests := []struct {
+		name      string
+		borrada   bool
+		createErr error
+		status    int
+		created   bool
+	}{
+		{"cuenta nueva", false, nil, http.StatusCreated, true},
+		{"correo en la papelera", true, nil, http.StatusConflict, false},
+		{"apodo ocupado", false, fmt.Errorf("Error 1062 (23000): Duplicate entry 'luz' for key 'usuarios.PRIMARY'"), http.StatusConflict, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeUserStore{borrada: tt.borrada, createErr: tt.createErr}
+			body := `{"apodo": "luz", "nombre": "Luz", "correo": "luz@example.com", "contrasenna": "secreta"}`
+			r := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			NewHandler(store).handleRegister(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if store.created != tt.created {
+				t.Errorf("created = %v, want %v", store.created, tt.created)
+			}
+		})
+	}
+}
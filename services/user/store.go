@@ -5,6 +5,8 @@ package user
 import (
 	"database/sql"
 	"fmt"
+	"log"
+	"time"
 
 	"gitlab.com/pardalis/pardalis-api/types"
 )
@@ -24,7 +26,7 @@ func NewStore(db *sql.DB) *Store {
 // CreateUser 🐄 – Guarda un usuario en la base de datos con la ilusión de que todo saldrá bien.
 // Porque insertar registros en SQL es siempre una operación de alto riesgo. 🎲
 func (s *Store) CreateUser(user types.User) error {
-	_, err := s.db.Exec("INSERT INTO usuarios (apodo, nombre, correo, contrasenna) VALUES (?, ?, ?, ?)", user.Apodo, user.Nombre, user.Correo, user.Contrasenna)
+	_, err := s.db.Exec("INSERT INTO usuarios (apodo, nombre, correo, contrasenna, fecha_nacimiento) VALUES (?, ?, ?, ?, ?)", user.Apodo, user.Nombre, user.Correo, user.Contrasenna, user.FechaNacimiento)
 	if err != nil {
 		return err // Si algo falla, no te preocupes, solo te devolveremos un error confuso. 🤷‍♂️
 	}
@@ -34,7 +36,7 @@ func (s *Store) CreateUser(user types.User) error {
 // GetUserByCorreo 🐄 – Busca un usuario por su correo electrónico porque, obvio, eso nunca falla. ✉️
 // Spoiler: A veces sí falla. Si el correo no existe, buena suerte con eso. 🤞
 func (s *Store) GetUserByCorreo(correo string) (*types.User, error) {
-	rows, err := s.db.Query("SELECT * FROM usuarios WHERE correo = ?", correo)
+	rows, err := s.db.Query("SELECT "+userColumns+" FROM usuarios WHERE correo = ? AND eliminado_en IS NULL", correo)
 	if err != nil {
 		return nil, err // Ups, algo salió mal... seguramente no es tu culpa. O sí. 🤔
 	}
@@ -57,7 +59,7 @@ func (s *Store) GetUserByCorreo(correo string) (*types.User, error) {
 // GetUserByApodo 🐄 – Busca un usuario por su apodo. Porque todos los usuarios tienen apodos, ¿verdad? 🤷‍♀️
 // Si no lo encuentras, es que probablemente no existe. Pero bueno, sigamos buscando.
 func (s *Store) GetUserByApodo(id string) (*types.User, error) {
-	rows, err := s.db.Query("SELECT * FROM usuarios WHERE apodo = ?", id)
+	rows, err := s.db.Query("SELECT "+userColumns+" FROM usuarios WHERE apodo = ? AND eliminado_en IS NULL", id)
 	if err != nil {
 		return nil, err // Si esto falla, solo te queda rezar. 🙏
 	}
@@ -77,10 +79,174 @@ func (s *Store) GetUserByApodo(id string) (*types.User, error) {
 	return u, nil
 }
 
+// DeleteUser 🐄 – Manda la cuenta a la papelera y sus blogs detrás, todos con la misma fecha
+// para que RestoreUser sepa cuáles se fueron con ella. Los que ya estaban en la papelera no se tocan. 🗑️
+func (s *Store) DeleteUser(apodo string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("UPDATE usuarios SET eliminado_en = CURRENT_TIMESTAMP WHERE apodo = ? AND eliminado_en IS NULL", apodo)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if rowsAffected == 0 {
+		_ = tx.Rollback()
+		return fmt.Errorf("user not found") // No se puede tirar a la papelera lo que ya está en ella. 🤷
+	}
+
+	_, err = tx.Exec(`
+        UPDATE blogs b
+        JOIN usuarios u ON u.apodo = b.autor_apodo
+        SET b.eliminado_en = u.eliminado_en, b.fecha_actualizacion = b.fecha_actualizacion
+        WHERE u.apodo = ? AND b.eliminado_en IS NULL
+    `, apodo)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetDeletedUserByCorreo 🐄 – Busca una cuenta en la papelera. Solo sirve para restaurarla,
+// porque para todo lo demás el usuario no existe. 👻
+func (s *Store) GetDeletedUserByCorreo(correo string) (*types.User, error) {
+	rows, err := s.db.Query("SELECT "+userColumns+" FROM usuarios WHERE correo = ? AND eliminado_en IS NOT NULL", correo)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(rows)
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("user not found") // Ni en la papelera. Ya se purgó o nunca existió. 🕳️
+	}
+
+	return scanRowsIntoUser(rows)
+}
+
+// RestoreUser 🐄 – Saca la cuenta de la papelera junto con los blogs que se fueron con ella.
+// Los que el usuario había eliminado antes siguen en su papelera, como debe ser. ♻️
+func (s *Store) RestoreUser(apodo string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE blogs b
+        JOIN usuarios u ON u.apodo = b.autor_apodo
+        SET b.eliminado_en = NULL, b.fecha_actualizacion = b.fecha_actualizacion
+        WHERE u.apodo = ? AND b.eliminado_en = u.eliminado_en
+    `, apodo)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	result, err := tx.Exec("UPDATE usuarios SET eliminado_en = NULL WHERE apodo = ? AND eliminado_en IS NOT NULL", apodo)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if rowsAffected == 0 {
+		_ = tx.Rollback()
+		return fmt.Errorf("user not found")
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeletedUsers 🐄 – Borra de verdad las cuentas que llevan en la papelera desde antes de before.
+// Sus blogs no tienen borrado en cascada, así que se van primero. Sus comentarios se quedan eliminados
+// y sin autor, porque otros pueden haberles respondido. Sin vuelta atrás. 🔥
+func (s *Store) PurgeDeletedUsers(before time.Time) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	purge := []string{
+		// Sus reacciones se van en cascada con la cuenta, pero los totales de blog_contadores no
+		`UPDATE blog_contadores bc
+            JOIN (
+                SELECT r.blog_id,
+                    SUM(r.tipo = 'like') AS likes,
+                    SUM(r.tipo = 'util') AS utiles,
+                    SUM(r.tipo = 'confuso') AS confusos
+                FROM blog_reacciones r
+                JOIN usuarios u ON u.apodo = r.apodo
+                WHERE u.eliminado_en < ?
+                GROUP BY r.blog_id
+            ) p ON p.blog_id = bc.blog_id
+            SET bc.likes = GREATEST(bc.likes - p.likes, 0),
+                bc.utiles = GREATEST(bc.utiles - p.utiles, 0),
+                bc.confusos = GREATEST(bc.confusos - p.confusos, 0)`,
+		`UPDATE comentarios c
+            JOIN usuarios u ON u.apodo = c.autor_apodo
+            SET c.estado = 'eliminado', c.contenido = '', c.autor_apodo = NULL
+            WHERE u.eliminado_en < ?`,
+		`DELETE pt FROM blog_posts_tags pt
+            JOIN blogs b ON b.id = pt.blog_id
+            JOIN usuarios u ON u.apodo = b.autor_apodo
+            WHERE u.eliminado_en < ?`,
+		`DELETE b FROM blogs b
+            JOIN usuarios u ON u.apodo = b.autor_apodo
+            WHERE u.eliminado_en < ?`,
+	}
+	for _, query := range purge {
+		if _, err := tx.Exec(query, before); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+
+	// El resto (personalización, series, reacciones, listas...) cae en cascada
+	result, err := tx.Exec("DELETE FROM usuarios WHERE eliminado_en < ?", before)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return purged, tx.Commit()
+}
+
+// userColumns 🐄 – Las columnas que scanRowsIntoUser espera, en orden. Adiós SELECT *, no te extrañaremos. 👋
+const userColumns = "apodo, nombre, correo, contrasenna, registro, rol, fecha_nacimiento, eliminado_en"
+
 // scanRowsIntoUser 🐄 – La función que toma filas de la base de datos y las convierte en un usuario.
 // Porque los usuarios no pueden salir mágicamente de la base de datos. 🎩✨
 func scanRowsIntoUser(rows *sql.Rows) (*types.User, error) {
 	user := new(types.User)
+	var fechaNacimiento, eliminadoEn sql.NullTime
 
 	err := rows.Scan(
 		&user.Apodo,
@@ -88,10 +254,20 @@ func scanRowsIntoUser(rows *sql.Rows) (*types.User, error) {
 		&user.Correo,
 		&user.Contrasenna,
 		&user.Registro,
+		&user.Rol,
+		&fechaNacimiento,
+		&eliminadoEn,
 	)
 	if err != nil {
 		return nil, err // Oh no, algo salió mal al convertir las filas en un usuario. 😱
 	}
 
+	if fechaNacimiento.Valid {
+		user.FechaNacimiento = &fechaNacimiento.Time
+	}
+	if eliminadoEn.Valid {
+		user.EliminadoEn = &eliminadoEn.Time
+	}
+
 	return user, nil // Si todo salió bien, ¡felicidades! Has logrado obtener un usuario de la base de datos. 🎉
 }
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"gitlab.com/pardalis/pardalis-api/db"
+)
+
+// Los tests de la tienda necesitan una base de datos MySQL desechable, por ejemplo:
+//
+//	PARDALIS_TEST_DSN='root:secret@tcp(localhost:3306)/pardalis_test?parseTime=true' \
+//	    go test ./services/user -run Purge
+func openTestStore(t *testing.T) (*Store, *sql.DB) {
+	t.Helper()
+
+	dsn := os.Getenv("PARDALIS_TEST_DSN")
+	if dsn == "" {
+		t.Skip("PARDALIS_TEST_DSN no definido")
+	}
+
+	conn, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	if err := db.InitializeDatabase(conn); err != nil {
+		t.Fatal(err)
+	}
+	return NewStore(conn), conn
+}
+
+func TestPurgeDeletedUsersCounters(t *testing.T) {
+	store, conn := openTestStore(t)
+
+	const blogID = "purge-test-blog"
+	seed := []string{
+		"DELETE FROM blogs WHERE id = 'purge-test-blog'",
+		"DELETE FROM usuarios WHERE apodo IN ('purge-autora', 'purge-queda', 'purge-borrada')",
+		`INSERT INTO usuarios (apodo, nombre, correo, contrasenna) VALUES
+            ('purge-autora', 'Autora', 'purge-autora@example.com', '-'),
+            ('purge-queda', 'Queda', 'purge-queda@example.com', '-'),
+            ('purge-borrada', 'Borrada', 'purge-borrada@example.com', '-')`,
+		`INSERT INTO blogs (id, titulo, slug, contenido, extracto, estado, categoria, autor_apodo)
+            VALUES ('purge-test-blog', 'Purga', 'purge-test-blog', 'contenido', 'extracto', 'publicado', 'General', 'purge-autora')`,
+		`INSERT INTO blog_reacciones (blog_id, apodo, tipo) VALUES
+            ('purge-test-blog', 'purge-queda', 'like'),
+            ('purge-test-blog', 'purge-borrada', 'like'),
+            ('purge-test-blog', 'purge-borrada', 'util'),
+            ('purge-test-blog', 'purge-borrada', 'confuso')`,
+		"INSERT INTO blog_contadores (blog_id, likes, utiles, confusos) VALUES ('purge-test-blog', 2, 1, 1)",
+		"UPDATE usuarios SET eliminado_en = NOW() - INTERVAL 40 DAY WHERE apodo = 'purge-borrada'",
+	}
+	for _, q := range seed {
+		if _, err := conn.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	t.Cleanup(func() {
+		_, _ = conn.Exec("DELETE FROM blogs WHERE id = ?", blogID)
+		_, _ = conn.Exec("DELETE FROM usuarios WHERE apodo IN ('purge-autora', 'purge-queda', 'purge-borrada')")
+	})
+
+	if _, err := store.PurgeDeletedUsers(time.Now().AddDate(0, 0, -30)); err != nil {
+		t.Fatal(err)
+	}
+
+	var likes, utiles, confusos int
+	err := conn.QueryRow("SELECT likes, utiles, confusos FROM blog_contadores WHERE blog_id = ?", blogID).
+		Scan(&likes, &utiles, &confusos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wantLikes, wantUtiles, wantConfusos int
+	err = conn.QueryRow(`
+        SELECT COALESCE(SUM(tipo = 'like'), 0), COALESCE(SUM(tipo = 'util'), 0), COALESCE(SUM(tipo = 'confuso'), 0)
+        FROM blog_reacciones WHERE blog_id = ?
+    `, blogID).Scan(&wantLikes, &wantUtiles, &wantConfusos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if likes != wantLikes || utiles != wantUtiles || confusos != wantConfusos {
+		t.Errorf("contadores = %d/%d/%d, reacciones = %d/%d/%d", likes, utiles, confusos, wantLikes, wantUtiles, wantConfusos)
+	}
+	if wantLikes != 1 || wantUtiles != 0 || wantConfusos != 0 {
+		t.Errorf("reacciones tras la purga = %d/%d/%d, want 1/0/0", wantLikes, wantUtiles, wantConfusos)
+	}
+}
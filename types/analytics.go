@@ -0,0 +1,57 @@
+package types
+
+import "time"
+
+// BlogView es una visita a un blog ya filtrada y anonimizada. Visitante es un
+// hash que identifica al lector sin guardar su IP.
+type BlogView struct {
+	BlogID    string
+	Visitante string
+	Referente string
+	Fecha     time.Time
+}
+
+// DailyViews son los totales de un blog en un día
+type DailyViews struct {
+	Fecha             string  `json:"fecha"`
+	Vistas            int     `json:"vistas"`
+	Lecturas          int     `json:"-"` // Visitas que informaron su progreso de lectura
+	SumaProgreso      float64 `json:"-"`
+	LecturasCompletas int     `json:"lecturas_completas"`
+	LecturaPromedio   float64 `json:"lectura_promedio"`
+}
+
+// ReferrerCount son las visitas que llegaron desde un sitio
+type ReferrerCount struct {
+	Referente string `json:"referente"`
+	Vistas    int    `json:"vistas"`
+}
+
+// BlogAnalytics son las estadísticas de un blog en un periodo
+type BlogAnalytics struct {
+	BlogID            string          `json:"blog_id"`
+	Desde             string          `json:"desde"`
+	Hasta             string          `json:"hasta"`
+	Vistas            int             `json:"vistas"`
+	LecturasCompletas int             `json:"lecturas_completas"`
+	LecturaPromedio   float64         `json:"lectura_promedio"`
+	Diario            []DailyViews    `json:"diario"`
+	Referentes        []ReferrerCount `json:"referentes"`
+}
+
+// BlogStats es el resumen de un blog en el panel de estadísticas del autor
+type BlogStats struct {
+	BlogID            string  `json:"blog_id"`
+	Titulo            string  `json:"titulo"`
+	Slug              string  `json:"slug"`
+	Vistas            int     `json:"vistas"`
+	Lecturas          int     `json:"-"`
+	SumaProgreso      float64 `json:"-"`
+	LecturasCompletas int     `json:"lecturas_completas"`
+	LecturaPromedio   float64 `json:"lectura_promedio"`
+}
+
+// ReadBeaconPayload es el progreso de lectura que envía el navegador (de 0 a 1)
+type ReadBeaconPayload struct {
+	Progreso float64 `json:"progreso" validate:"gte=0,lte=1"`
+}
@@ -0,0 +1,103 @@
+package types
+
+import "encoding/json"
+
+// Tipos de bloque interactivo
+const (
+	BloqueTipoCodigo   = "codigo"   // Fragmento de código que el lector puede ejecutar
+	BloqueTipoPregunta = "pregunta" // Pregunta de opción múltiple para comprobar lo aprendido
+	BloqueTipoGaleria  = "galeria"
+	BloqueTipoVideo    = "video"
+	BloqueTipoAviso    = "aviso" // Recuadro destacado: nota, consejo o advertencia
+)
+
+// Proveedores de los bloques de vídeo. Archivo es un enlace directo a un .mp4,
+// .webm u .ogg.
+const (
+	VideoYouTube = "youtube"
+	VideoVimeo   = "vimeo"
+	VideoArchivo = "archivo"
+)
+
+// Bloque es un contenido interactivo de un blog. Tipo indica cuál de los campos
+// lleva los datos; los demás se omiten. El Markdown lo coloca con una línea
+// {{bloque:id}}; los bloques que no se citan van después del contenido, en orden.
+type Bloque struct {
+	ID       string          `json:"id" validate:"omitempty,max=64"`
+	Tipo     string          `json:"tipo" validate:"required,oneof=codigo pregunta galeria video aviso"`
+	Codigo   *BloqueCodigo   `json:"codigo,omitempty"`
+	Pregunta *BloquePregunta `json:"pregunta,omitempty"`
+	Galeria  *BloqueGaleria  `json:"galeria,omitempty"`
+	Video    *BloqueVideo    `json:"video,omitempty"`
+	Aviso    *BloqueAviso    `json:"aviso,omitempty"`
+}
+
+// Bloques son los bloques de un blog. Sin bloques se escriben como [] y no
+// como null, para que el frontend siempre reciba una lista.
+type Bloques []Bloque
+
+func (b Bloques) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Bloque(b))
+}
+
+// BloqueCodigo es un fragmento de código. Si es ejecutable, el frontend lo
+// ejecuta con Entrada y puede comparar el resultado con SalidaEsperada.
+type BloqueCodigo struct {
+	Lenguaje       string `json:"lenguaje" validate:"required,oneof=python javascript typescript go java c cpp rust ruby php bash sql html css"`
+	Codigo         string `json:"codigo" validate:"required,max=20000"`
+	Ejecutable     bool   `json:"ejecutable"`
+	Entrada        string `json:"entrada,omitempty" validate:"max=5000"`
+	SalidaEsperada string `json:"salida_esperada,omitempty" validate:"max=5000"`
+}
+
+// BloquePregunta es una pregunta de opción múltiple. Con Multiple se pueden
+// marcar varias opciones y todas las correctas forman la respuesta.
+type BloquePregunta struct {
+	Enunciado   string           `json:"enunciado" validate:"required,max=1000"`
+	Opciones    []OpcionPregunta `json:"opciones" validate:"min=2,max=8,dive"`
+	Multiple    bool             `json:"multiple"`
+	Explicacion string           `json:"explicacion,omitempty" validate:"max=2000"` // Se muestra después de responder
+}
+
+// OpcionPregunta es una respuesta posible. Su id es la letra de su posición
+// ("a", "b"...) y lo asigna el servidor.
+type OpcionPregunta struct {
+	ID       string `json:"id"`
+	Texto    string `json:"texto" validate:"required,max=500"`
+	Correcta bool   `json:"correcta"`
+}
+
+// BloqueGaleria es una serie de imágenes que se muestran juntas
+type BloqueGaleria struct {
+	Imagenes []ImagenGaleria `json:"imagenes" validate:"min=1,max=20,dive"`
+}
+
+// ImagenGaleria es una imagen de una galería. Alt es obligatorio: sin él la
+// galería no es accesible.
+type ImagenGaleria struct {
+	URL string `json:"url" validate:"required,url,max=512"`
+	Alt string `json:"alt" validate:"required,max=255"`
+	Pie string `json:"pie,omitempty" validate:"max=500"`
+}
+
+// BloqueVideo es una referencia a un vídeo. Proveedor y VideoID los calcula el
+// servidor a partir de la URL para que el frontend monte el reproductor.
+type BloqueVideo struct {
+	URL       string `json:"url" validate:"required,url,max=512"`
+	Titulo    string `json:"titulo,omitempty" validate:"max=255"`
+	Inicio    int    `json:"inicio,omitempty" validate:"min=0"` // Segundo en el que empieza
+	Proveedor string `json:"proveedor"`
+	VideoID   string `json:"video_id,omitempty"`
+}
+
+// BloqueAviso es un recuadro destacado. Texto es Markdown; TextoHTML lo
+// genera el servidor igual que ContenidoHTML.
+type BloqueAviso struct {
+	Variante  string `json:"variante" validate:"required,oneof=nota consejo advertencia peligro"`
+	Titulo    string `json:"titulo,omitempty" validate:"max=255"`
+	Texto     string `json:"texto" validate:"required,max=5000"`
+	TextoHTML string `json:"texto_html"`
+}
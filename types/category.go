@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// Category es una categoría de blogs. Las categorías forman un árbol a través
+// de ParentID y se muestran ordenadas por Orden y después por nombre.
+type Category struct {
+	ID            string     `json:"id"`
+	Nombre        string     `json:"nombre"`
+	Slug          string     `json:"slug"`
+	Descripcion   string     `json:"descripcion"`
+	ParentID      *string    `json:"parent_id"`
+	Orden         int        `json:"orden"`
+	Icono         string     `json:"icono"`
+	TotalBlogs    int        `json:"total_blogs"` // Blogs publicados en la categoría y sus subcategorías
+	FechaCreacion time.Time  `json:"fecha_creacion"`
+	Subcategorias []Category `json:"subcategorias,omitempty"`
+}
+
+type CreateCategoryPayload struct {
+	Nombre      string `json:"nombre" validate:"required,max=100"`
+	Descripcion string `json:"descripcion" validate:"max=1000"`
+	ParentID    string `json:"parent_id"`
+	Orden       int    `json:"orden"`
+	Icono       string `json:"icono" validate:"max=100"`
+}
+
+// UpdateCategoryPayload solo modifica los campos presentes en la petición.
+// Un parent_id vacío convierte la categoría en raíz.
+type UpdateCategoryPayload struct {
+	Nombre      string  `json:"nombre" validate:"omitempty,max=100"`
+	Descripcion *string `json:"descripcion" validate:"omitempty,max=1000"`
+	ParentID    *string `json:"parent_id"`
+	Orden       *int    `json:"orden"`
+	Icono       *string `json:"icono" validate:"omitempty,max=100"`
+}
@@ -0,0 +1,65 @@
+package types
+
+import "time"
+
+// Papeles de un colaborador. Los coautores firman el blog; los editores no.
+const (
+	ColaboradorCoautor = "coautor"
+	ColaboradorEditor  = "editor"
+)
+
+// Permisos sobre un blog. El autor los tiene todos; gestionar (invitar y
+// retirar colaboradores) es exclusivo suyo.
+const (
+	PermisoEditar    = "editar"
+	PermisoPublicar  = "publicar"
+	PermisoEliminar  = "eliminar"
+	PermisoGestionar = "gestionar"
+)
+
+// Estados de una invitación. Una invitación rechazada se borra.
+const (
+	InvitacionPendiente = "pendiente"
+	InvitacionAceptada  = "aceptada"
+)
+
+// Colaborador es un usuario invitado a trabajar en un blog ajeno. Solo tiene
+// permisos mientras su invitación está aceptada.
+type Colaborador struct {
+	BlogID          string     `json:"blog_id"`
+	BlogTitulo      string     `json:"blog_titulo,omitempty"`
+	Apodo           string     `json:"apodo"`
+	Rol             string     `json:"rol"`
+	Permisos        []string   `json:"permisos"`
+	Estado          string     `json:"estado"`
+	InvitadoPor     string     `json:"invitado_por"`
+	FechaInvitacion time.Time  `json:"fecha_invitacion"`
+	FechaRespuesta  *time.Time `json:"fecha_respuesta,omitempty"`
+}
+
+// Can indica si el colaborador tiene un permiso
+func (c *Colaborador) Can(permiso string) bool {
+	if c.Estado != InvitacionAceptada {
+		return false
+	}
+	for _, p := range c.Permisos {
+		if p == permiso {
+			return true
+		}
+	}
+	return false
+}
+
+// InviteCollaboratorPayload invita a un usuario. Sin permisos se aplican los
+// del papel: los coautores editan y publican, los editores solo editan.
+type InviteCollaboratorPayload struct {
+	Apodo    string   `json:"apodo" validate:"required"`
+	Rol      string   `json:"rol" validate:"required,oneof=coautor editor"`
+	Permisos []string `json:"permisos" validate:"omitempty,dive,oneof=editar publicar eliminar"`
+}
+
+// UpdateCollaboratorPayload solo modifica los campos presentes en la petición
+type UpdateCollaboratorPayload struct {
+	Rol      string   `json:"rol" validate:"omitempty,oneof=coautor editor"`
+	Permisos []string `json:"permisos" validate:"omitempty,dive,oneof=editar publicar eliminar"`
+}
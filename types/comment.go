@@ -0,0 +1,49 @@
+package types
+
+import "time"
+
+// Estados de un comentario
+const (
+	ComentarioVisible   = "visible"
+	ComentarioPendiente = "pendiente" // Retenido en la cola de moderación
+	ComentarioOculto    = "oculto"    // Ocultado o rechazado por un moderador
+	ComentarioEliminado = "eliminado" // Borrado por su autor; se conserva para no romper el hilo
+)
+
+// Comment es un comentario sobre un blog. Los comentarios sin ParentID abren un
+// hilo; las respuestas guardan en RaizID el comentario que abrió su hilo.
+type Comment struct {
+	ID            string     `json:"id"`
+	BlogID        string     `json:"blog_id"`
+	ParentID      *string    `json:"parent_id"`
+	RaizID        *string    `json:"-"`
+	AutorApodo    string     `json:"autor_apodo"`
+	Contenido     string     `json:"contenido"`
+	Estado        string     `json:"estado"`
+	Fijado        bool       `json:"fijado"`
+	Bloqueado     bool       `json:"bloqueado"`
+	FechaCreacion time.Time  `json:"fecha_creacion"`
+	FechaEdicion  *time.Time `json:"fecha_edicion,omitempty"`
+	Respuestas    []Comment  `json:"respuestas,omitempty"`
+}
+
+// CommentPage es una página de hilos de comentarios. Los hilos fijados solo
+// se devuelven en la primera página.
+type CommentPage struct {
+	Fijados    []Comment `json:"fijados,omitempty"`
+	Items      []Comment `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+type CreateCommentPayload struct {
+	Contenido string `json:"contenido" validate:"required,max=5000"`
+	ParentID  string `json:"parent_id"`
+}
+
+type UpdateCommentPayload struct {
+	Contenido string `json:"contenido" validate:"required,max=5000"`
+}
+
+type ModerateCommentPayload struct {
+	Accion string `json:"accion" validate:"required,oneof=aprobar rechazar ocultar mostrar fijar desfijar bloquear desbloquear"`
+}
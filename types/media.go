@@ -0,0 +1,75 @@
+package types
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Propósitos de un archivo subido; cada uno tiene su límite de tamaño
+const (
+	MediaPortada = "portada"
+	MediaAvatar  = "avatar"
+)
+
+// Media es una imagen subida por un usuario. La clave se deriva del contenido,
+// así que la URL no cambia mientras no cambie la imagen.
+type Media struct {
+	ID            string    `json:"id"`
+	Clave         string    `json:"clave"`
+	URL           string    `json:"url"`
+	Tipo          string    `json:"tipo"`
+	Tamano        int64     `json:"tamano"`
+	Ancho         int       `json:"ancho"`
+	Alto          int       `json:"alto"`
+	Proposito     string    `json:"proposito"`
+	AutorApodo    string    `json:"autor_apodo"`
+	FechaCreacion time.Time `json:"fecha_creacion"`
+}
+
+// Nombres de las variantes que se generan de cada imagen subida
+const (
+	VarianteMiniatura = "miniatura"
+	VarianteTarjeta   = "tarjeta"
+	VarianteHero      = "hero"
+)
+
+// ImagenVariante es una copia redimensionada de una imagen subida
+type ImagenVariante struct {
+	Nombre string `json:"nombre"`
+	Clave  string `json:"-"`
+	URL    string `json:"url"`
+	Tipo   string `json:"tipo"`
+	Ancho  int    `json:"ancho"`
+	Alto   int    `json:"alto"`
+}
+
+// ImagenResponsive reúne una imagen subida y sus variantes, de menor a mayor
+// ancho. SrcSet se puede usar tal cual en el atributo srcset de <img>;
+// Blurhash y Color sirven de marcador mientras la imagen carga.
+type ImagenResponsive struct {
+	URL       string           `json:"url"`
+	Clave     string           `json:"-"`
+	Ancho     int              `json:"ancho"`
+	Alto      int              `json:"alto"`
+	Variantes []ImagenVariante `json:"variantes"`
+	SrcSet    string           `json:"srcset"`
+	Blurhash  string           `json:"blurhash,omitempty"`
+	Color     string           `json:"color,omitempty"`
+}
+
+// ImageResolver obtiene las variantes de imágenes a partir de sus URLs. Las
+// URLs que no son de imágenes subidas no aparecen en el resultado.
+type ImageResolver interface {
+	Resolve(urls []string) (map[string]*ImagenResponsive, error)
+}
+
+// BlobStorage guarda archivos binarios por clave. Las claves usan "/" como
+// separador y nunca contienen "..". Get devuelve el error "blob not found" si
+// la clave no existe.
+type BlobStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
@@ -0,0 +1,82 @@
+package types
+
+import "time"
+
+// Decisiones de moderación, de menor a mayor gravedad
+const (
+	ModeracionPermitir = "permitir" // Se guarda sin más
+	ModeracionMarcar   = "marcar"   // Se guarda y se publica, pero entra en la cola de revisión
+	ModeracionRetener  = "retener"  // Se guarda sin publicar hasta que un administrador lo apruebe
+	ModeracionRechazar = "rechazar" // No se guarda
+)
+
+// Reglas del moderador local. La política asigna una decisión a cada una.
+const (
+	ReglaPalabras        = "palabras"
+	ReglaDatosPersonales = "datos_personales"
+	ReglaEnlaces         = "enlaces"
+)
+
+// Tipos de contenido que se moderan
+const (
+	ContenidoBlog        = "blog"
+	ContenidoComentario  = "comentario"
+	ContenidoDescripcion = "descripcion" // Personalization.Descripcion
+)
+
+// Estados de un caso de la cola de revisión
+const (
+	CasoPendiente = "pendiente"
+	CasoAprobado  = "aprobado"
+	CasoRechazado = "rechazado"
+	CasoSuperado  = "superado" // El contenido cambió antes de revisarlo
+)
+
+// ModerationRequest es el texto que se quiere guardar y quién lo escribe
+type ModerationRequest struct {
+	Tipo  string
+	Texto string
+	Autor *User
+}
+
+// ModerationFinding es una coincidencia de una regla en el texto
+type ModerationFinding struct {
+	Regla     string `json:"regla"`
+	Tipo      string `json:"tipo"` // palabra, correo, telefono, direccion o enlace
+	Fragmento string `json:"fragmento"`
+}
+
+// ModerationResult es la decisión sobre un texto y lo que la motivó
+type ModerationResult struct {
+	Decision  string              `json:"decision"`
+	Hallazgos []ModerationFinding `json:"hallazgos"`
+}
+
+// Moderator revisa los textos antes de guardarlos. La implementación local usa
+// reglas; un servicio externo puede sustituirla sin tocar los handlers.
+type Moderator interface {
+	Moderate(req ModerationRequest) (ModerationResult, error)
+}
+
+// ModerationCase es una entrada de la cola de revisión. Contenido es el texto
+// tal y como se moderó; para las descripciones retenidas es además lo que se
+// aplica si se aprueba.
+type ModerationCase struct {
+	ID            string              `json:"id"`
+	Tipo          string              `json:"tipo"`
+	RecursoID     string              `json:"recurso_id"` // Id del blog o del comentario, o apodo para las descripciones
+	AutorApodo    string              `json:"autor_apodo"`
+	Contenido     string              `json:"contenido"`
+	Decision      string              `json:"decision"`
+	Hallazgos     []ModerationFinding `json:"hallazgos"`
+	Publicar      bool                `json:"publicar"` // El autor pidió publicar el blog retenido
+	Estado        string              `json:"estado"`
+	FechaCreacion time.Time           `json:"fecha_creacion"`
+	RevisadoPor   *string             `json:"revisado_por,omitempty"`
+	FechaRevision *time.Time          `json:"fecha_revision,omitempty"`
+}
+
+// ResolveModerationCasePayload aprueba o rechaza un caso pendiente
+type ResolveModerationCasePayload struct {
+	Accion string `json:"accion" validate:"required,oneof=aprobar rechazar"`
+}
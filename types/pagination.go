@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// Cursor marca el último elemento de una página en un listado ordenado por
+// fecha e id. Valor guarda la clave de orden principal cuando el listado no
+// se ordena por fecha, como la popularidad. Un cursor vacío apunta al principio.
+type Cursor struct {
+	Fecha time.Time
+	ID    string
+	Valor int64
+}
+
+// IsZero indica si el cursor apunta al principio del listado
+func (c Cursor) IsZero() bool {
+	return c.ID == ""
+}
+
+// Pagination son los parámetros de paginación de una petición
+type Pagination struct {
+	After Cursor
+	Limit int
+	// Total indica si el cliente pidió el número total de elementos (?total=true)
+	Total bool
+}
+
+// Page es la respuesta de un listado paginado por cursor
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int   `json:"total,omitempty"`
+}
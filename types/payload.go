@@ -5,10 +5,11 @@ package types
 // RegisterUserPayload 🐄 – La carga útil de registro que verifica que tus usuarios
 // al menos tengan un nombre y correo, porque aparentemente eso es lo mínimo necesario para ser un ser humano. 😅
 type RegisterUserPayload struct {
-	Apodo       string `json:"apodo" validate:"required"`                     // Apodo 🐄 – Sí, ¿Realmente alguien escoge esto bien? ¿Los niños escogeran bien? O sera Daniel123. 🙃
-	Nombre      string `json:"nombre" validate:"required"`                    // Nombre 🐄 – Obligatorio, porque nadie quiere un usuario sin nombre... ¿verdad?
-	Correo      string `json:"correo" validate:"required,email"`              // Correo 🐄 – El correo del usuario, validado para asegurarse de que termine en "@", lo que podría ser suficiente. 🕵️‍♀️
-	Contrasenna string `json:"contrasenna" validate:"required,min=3,max=130"` // Contrasenna 🐄 – La contraseña que va de 3 a 130 caracteres, porque todos sabemos que esos son los números mágicos para la seguridad. 🧙‍♂️
+	Apodo           string `json:"apodo" validate:"required"`                                 // Apodo 🐄 – Sí, ¿Realmente alguien escoge esto bien? ¿Los niños escogeran bien? O sera Daniel123. 🙃
+	Nombre          string `json:"nombre" validate:"required"`                                // Nombre 🐄 – Obligatorio, porque nadie quiere un usuario sin nombre... ¿verdad?
+	Correo          string `json:"correo" validate:"required,email"`                          // Correo 🐄 – El correo del usuario, validado para asegurarse de que termine en "@", lo que podría ser suficiente. 🕵️‍♀️
+	Contrasenna     string `json:"contrasenna" validate:"required,min=3,max=130"`             // Contrasenna 🐄 – La contraseña que va de 3 a 130 caracteres, porque todos sabemos que esos son los números mágicos para la seguridad. 🧙‍♂️
+	FechaNacimiento string `json:"fecha_nacimiento" validate:"omitempty,datetime=2006-01-02"` // FechaNacimiento 🐄 – Opcional, pero sin ella te moderamos como a un menor, por si acaso. 👶
 }
 
 // LoginUserPayload 🐄 – La carga útil para iniciar sesión que define lo absolutamente
@@ -19,16 +20,30 @@ type LoginUserPayload struct {
 	Contrasenna string `json:"contrasenna" validate:"required"`  // Contrasenna 🐄 – La contraseña que has decidido que debe ser requerida, pero por suerte para los hackers, no tienes reglas de complejidad. 🔑
 }
 
+// DeleteAccountPayload pide la contraseña para que nadie borre una cuenta con un token robado
+type DeleteAccountPayload struct {
+	Contrasenna string `json:"contrasenna" validate:"required"`
+}
+
 type CreateBlogPayload struct {
 	Titulo          string   `json:"titulo" validate:"required"`
 	Contenido       string   `json:"contenido" validate:"required"`
 	Extracto        string   `json:"extracto" validate:"required"`
 	ImagenPortada   string   `json:"imagen_portada"`
-	Categoria       string   `json:"categoria" validate:"required"`
-	TiempoLectura   int      `json:"tiempo_lectura" validate:"required,min=1"`
+	CategoriaID     string   `json:"categoria_id" validate:"required"`
 	MetaDescripcion string   `json:"meta_descripcion"`
 	MetaKeywords    string   `json:"meta_keywords"`
 	Tags            []string `json:"tags"`
+	Idioma          string   `json:"idioma"` // Vacío es el idioma por defecto
+	Bloques         []Bloque `json:"bloques" validate:"max=50,dive"`
+}
+
+// PatchBlogDocument es la representación editable de un blog sobre la que se
+// aplica un JSON Merge Patch. El resultado se valida con las mismas reglas que
+// la creación, más el estado.
+type PatchBlogDocument struct {
+	CreateBlogPayload
+	Estado string `json:"estado" validate:"required,oneof=borrador publicado"`
 }
 
 type UpdateBlogPayload struct {
@@ -36,10 +51,10 @@ type UpdateBlogPayload struct {
 	Contenido       string   `json:"contenido"`
 	Extracto        string   `json:"extracto"`
 	ImagenPortada   string   `json:"imagen_portada"`
-	Categoria       string   `json:"categoria"`
-	TiempoLectura   int      `json:"tiempo_lectura" validate:"min=1"`
+	CategoriaID     string   `json:"categoria_id"`
 	Estado          string   `json:"estado" validate:"oneof=borrador publicado"`
 	MetaDescripcion string   `json:"meta_descripcion"`
 	MetaKeywords    string   `json:"meta_keywords"`
 	Tags            []string `json:"tags"`
+	Bloques         []Bloque `json:"bloques" validate:"omitempty,max=50,dive"` // Sin el campo no cambian; [] los quita todos
 }
@@ -8,6 +8,10 @@ type Personalization struct {
 	Descripcion        string    `json:"descripcion"`
 	Foto               string    `json:"foto"`
 	FechaActualizacion time.Time `json:"fecha_actualizacion"`
+	Version            int       `json:"version"`
+
+	// Avatar son las variantes de Foto si es una imagen subida
+	Avatar *ImagenResponsive `json:"avatar,omitempty"`
 }
 
 // PersonalizationStore define la interfaz para operaciones de la base de datos
@@ -19,8 +23,10 @@ type PersonalizationStore interface {
 
 // PersonalizationResponse es la estructura para las respuestas HTTP
 type PersonalizationResponse struct {
-	Descripcion string `json:"descripcion"`
-	Foto        string `json:"foto"`
+	Descripcion string            `json:"descripcion"`
+	Foto        string            `json:"foto"`
+	Avatar      *ImagenResponsive `json:"avatar,omitempty"`
+	Version     int               `json:"version"`
 }
 
 // ToResponse convierte un Personalization a PersonalizationResponse
@@ -28,5 +34,7 @@ func (p *Personalization) ToResponse() PersonalizationResponse {
 	return PersonalizationResponse{
 		Descripcion: p.Descripcion,
 		Foto:        p.Foto,
+		Avatar:      p.Avatar,
+		Version:     p.Version,
 	}
 }
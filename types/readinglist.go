@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+// ReadingList es una lista de lectura con nombre creada por un usuario. Las
+// listas públicas pueden compartirse con cualquiera que tenga su id.
+type ReadingList struct {
+	ID                 string    `json:"id"`
+	Apodo              string    `json:"apodo"`
+	Nombre             string    `json:"nombre"`
+	Descripcion        string    `json:"descripcion"`
+	Publica            bool      `json:"publica"`
+	TotalBlogs         int       `json:"total_blogs"`
+	FechaCreacion      time.Time `json:"fecha_creacion"`
+	FechaActualizacion time.Time `json:"fecha_actualizacion"`
+	Blogs              []Blog    `json:"blogs,omitempty"`
+}
+
+// Bookmark es un blog de los marcadores junto con la fecha en que se guardó,
+// que es lo que ordena el listado
+type Bookmark struct {
+	Blog
+	FechaGuardado time.Time `json:"fecha_guardado"`
+}
+
+type CreateReadingListPayload struct {
+	Nombre      string `json:"nombre" validate:"required,max=100"`
+	Descripcion string `json:"descripcion" validate:"max=500"`
+	Publica     bool   `json:"publica"`
+}
+
+// UpdateReadingListPayload solo modifica los campos presentes en la petición
+type UpdateReadingListPayload struct {
+	Nombre      string  `json:"nombre" validate:"omitempty,max=100"`
+	Descripcion *string `json:"descripcion" validate:"omitempty,max=500"`
+	Publica     *bool   `json:"publica"`
+}
+
+type AddReadingListItemPayload struct {
+	BlogID string `json:"blog_id" validate:"required"`
+}
+
+// ReorderReadingListPayload contiene todos los blogs de la lista en el orden deseado
+type ReorderReadingListPayload struct {
+	BlogIDs []string `json:"blog_ids" validate:"required,min=1"`
+}
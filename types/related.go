@@ -0,0 +1,26 @@
+package types
+
+// RelatedBlog es una recomendación de lectura con los motivos que la justifican
+type RelatedBlog struct {
+	Blog       Blog     `json:"blog"`
+	Puntuacion float64  `json:"puntuacion"`
+	Motivos    []string `json:"motivos"`
+}
+
+// Motivos de una recomendación
+const (
+	MotivoTags      = "tags"
+	MotivoCategoria = "categoria"
+	MotivoSerie     = "serie"
+	MotivoLectores  = "lectores"
+	MotivoContenido = "contenido"
+)
+
+// RelatedCandidate es un blog publicado que comparte alguna señal con otro
+type RelatedCandidate struct {
+	Blog            Blog
+	TagsCompartidas int
+	MismaCategoria  bool
+	MismaSerie      bool
+	Colectores      int // Visitantes recientes que leyeron ambos blogs
+}
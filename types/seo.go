@@ -0,0 +1,12 @@
+package types
+
+// SEOMetadata agrupa las etiquetas que el frontend SSR inserta en el <head> de un blog
+type SEOMetadata struct {
+	Titulo      string            `json:"titulo"`
+	Descripcion string            `json:"descripcion"`
+	Keywords    []string          `json:"keywords"`
+	Canonical   string            `json:"canonical"`
+	OpenGraph   map[string]string `json:"open_graph"` // og:* -> content
+	Twitter     map[string]string `json:"twitter"`    // twitter:* -> content
+	JSONLD      map[string]any    `json:"json_ld"`    // Datos estructurados schema.org BlogPosting
+}
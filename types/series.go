@@ -0,0 +1,63 @@
+package types
+
+import "time"
+
+// Series es una colección ordenada de blogs de un mismo autor, como un curso en varias partes
+type Series struct {
+	ID                 string       `json:"id"`
+	Titulo             string       `json:"titulo"`
+	Slug               string       `json:"slug"`
+	Descripcion        string       `json:"descripcion"`
+	ImagenPortada      string       `json:"imagen_portada"`
+	AutorApodo         string       `json:"autor_apodo"`
+	TotalPartes        int          `json:"total_partes"`
+	FechaCreacion      time.Time    `json:"fecha_creacion"`
+	FechaActualizacion time.Time    `json:"fecha_actualizacion"`
+	Partes             []SeriesPart `json:"partes,omitempty"`
+	PartesNextCursor   string       `json:"partes_next_cursor,omitempty"` // Cursor de la siguiente página de partes
+}
+
+// SeriesPart es una parte de una serie. Posicion solo ordena y pagina las
+// partes: con borradores de por medio no coincide con la que ven los lectores.
+type SeriesPart struct {
+	Blog
+	Posicion int `json:"-"`
+}
+
+type CreateSeriesPayload struct {
+	Titulo        string `json:"titulo" validate:"required,max=255"`
+	Descripcion   string `json:"descripcion" validate:"max=2000"`
+	ImagenPortada string `json:"imagen_portada" validate:"omitempty,url,max=512"`
+}
+
+// UpdateSeriesPayload solo modifica los campos presentes en la petición
+type UpdateSeriesPayload struct {
+	Titulo        string  `json:"titulo" validate:"omitempty,max=255"`
+	Descripcion   *string `json:"descripcion" validate:"omitempty,max=2000"`
+	ImagenPortada *string `json:"imagen_portada" validate:"omitempty,max=512"`
+}
+
+type AddSeriesPartPayload struct {
+	BlogID string `json:"blog_id" validate:"required"`
+}
+
+// ReorderSeriesPayload contiene todas las partes de la serie en el orden deseado
+type ReorderSeriesPayload struct {
+	BlogIDs []string `json:"blog_ids" validate:"required,min=1"`
+}
+
+// SeriesLink identifica una serie o una de sus partes
+type SeriesLink struct {
+	ID     string `json:"id"`
+	Titulo string `json:"titulo"`
+	Slug   string `json:"slug"`
+}
+
+// SeriesNavigation sitúa un blog dentro de su serie. Solo cuenta las partes publicadas.
+type SeriesNavigation struct {
+	SeriesLink
+	Posicion  int         `json:"posicion"`
+	Total     int         `json:"total"`
+	Anterior  *SeriesLink `json:"anterior"`
+	Siguiente *SeriesLink `json:"siguiente"`
+}
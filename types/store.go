@@ -2,17 +2,24 @@
 // Aquí, definimos tipos que probablemente complicarán tu vida más de lo necesario. ¡Disfruta! 🥳
 package types
 
+import "time"
+
 // UserStore 🐄 – La interfaz que promete gestionar a tus usuarios con métodos que
 // probablemente no implementaste correctamente. Pero oye, la intención es lo que cuenta. 🎯
 type UserStore interface {
-	GetUserByApodo(apodo string) (*User, error)   // GetUserByApodo 🐄 – Encuentra al usuario por su apodo... suponiendo que el apodo sea lo suficientemente único y memorable como para ser útil. 🤔
-	GetUserByCorreo(correo string) (*User, error) // GetUserByCorreo 🐄 – Encuentra al usuario por su correo electrónico, porque la gente ama recordar múltiples credenciales. 🔍
-	CreateUser(User) error                        // CreateUser 🐄 – Crea un usuario, o al menos lo intenta, hasta que las validaciones fallan y todo explota. 💣
+	GetUserByApodo(apodo string) (*User, error)          // GetUserByApodo 🐄 – Encuentra al usuario por su apodo... suponiendo que el apodo sea lo suficientemente único y memorable como para ser útil. 🤔
+	GetUserByCorreo(correo string) (*User, error)        // GetUserByCorreo 🐄 – Encuentra al usuario por su correo electrónico, porque la gente ama recordar múltiples credenciales. 🔍
+	CreateUser(User) error                               // CreateUser 🐄 – Crea un usuario, o al menos lo intenta, hasta que las validaciones fallan y todo explota. 💣
+	DeleteUser(apodo string) error                       // DeleteUser 🐄 – Manda la cuenta y sus blogs a la papelera, por si mañana se arrepiente. 🗑️
+	GetDeletedUserByCorreo(correo string) (*User, error) // GetDeletedUserByCorreo 🐄 – Busca en la papelera, el único sitio donde GetUserByCorreo no mira. 🔦
+	RestoreUser(apodo string) error                      // RestoreUser 🐄 – Saca la cuenta de la papelera junto con los blogs que se fueron con ella. ♻️
+	PurgeDeletedUsers(before time.Time) (int64, error)   // PurgeDeletedUsers 🐄 – Vacía la papelera de cuentas. Esta vez va en serio. 🔥
 }
 
 type BlogStore interface {
 	GetBlogBySlug(slug string) (*Blog, error)
-	GetBlogs(page, limit int, categoria string) ([]Blog, error)
+	GetBlogs(q BlogQuery) ([]Blog, error)
+	CountBlogs(q BlogQuery) (int, error)
 	CreateBlog(blog Blog) error
 	UpdateBlog(blog Blog) error
 	DeleteBlog(id string) error
@@ -20,4 +27,146 @@ type BlogStore interface {
 	AddBlogTag(blogID string, tag string) error
 	RemoveBlogTag(blogID string, tag string) error
 	GetBlogByID(id string) (*Blog, error)
+	GetTranslation(grupo string, idioma string) (*Blog, error)
+	SlugExists(slug string) (bool, error)
+	GetAllBlogs() ([]Blog, error)
+	GetPublishedBlogs() ([]Blog, error)
+	GetPublishedBlogSummaries() ([]Blog, error)
+	GetDeletedBlogs(autorApodo string) ([]Blog, error)
+	GetDeletedBlogByID(id string) (*Blog, error)
+	RestoreBlog(id string) error
+	PurgeDeletedBlogs(before time.Time) (int64, error)
+}
+
+// TagStore define las operaciones de administración de etiquetas. Las que
+// cambian etiquetas devuelven los ids de los blogs afectados para reindexarlos.
+type TagStore interface {
+	GetTags(prefix string, after Cursor, limit int) ([]Tag, error)
+	GetBlogsByTag(tag string, after Cursor, limit int) ([]Blog, error)
+	RenameTag(nombre string, nuevoNombre string) ([]string, error)
+	MergeTags(origen string, destino string) ([]string, error)
+	PruneUnusedTags() (int64, error)
+}
+
+// SeriesStore define las operaciones de la base de datos para series
+type SeriesStore interface {
+	CreateSeries(series Series) error
+	GetSeriesByID(id string) (*Series, error)
+	GetSeriesBySlug(slug string) (*Series, error)
+	UpdateSeries(series Series) error
+	DeleteSeries(id string) error
+	GetSeriesParts(serieID string, soloPublicadas bool, afterPosicion int, afterID string, limit int) ([]SeriesPart, error)
+	CountSeriesParts(serieID string, soloPublicadas bool) (int, error)
+	AddSeriesPart(serieID string, blogID string) error
+	RemoveSeriesPart(serieID string, blogID string) error
+	ReorderSeries(serieID string, blogIDs []string) error
+	GetSeriesNavigation(blogID string) (*SeriesNavigation, error)
+}
+
+// CategoryStore define las operaciones de la base de datos para categorías
+type CategoryStore interface {
+	GetCategories() ([]Category, error)
+	GetCategoryByID(id string) (*Category, error)
+	CreateCategory(category Category) error
+	UpdateCategory(category Category) error
+	DeleteCategory(id string) error
+}
+
+// CommentStore define las operaciones de la base de datos para comentarios.
+// Las consultas paginadas reciben la fecha e id del último elemento visto.
+type CommentStore interface {
+	CreateComment(comment Comment) error
+	GetCommentByID(id string) (*Comment, error)
+	UpdateCommentContent(id string, contenido string, estado string) error
+	SetCommentState(id string, estado string) error
+	SetCommentFlags(id string, fijado bool, bloqueado bool) error
+	GetPinnedThreads(blogID string) ([]Comment, error)
+	GetThreads(blogID string, afterFecha time.Time, afterID string, limit int) ([]Comment, error)
+	GetReplies(raizIDs []string) ([]Comment, error)
+	GetPendingComments(blogAutor string, afterFecha time.Time, afterID string, limit int) ([]Comment, error)
+}
+
+// ReactionStore define las operaciones de la base de datos para reacciones.
+// AddReaction y RemoveReaction mantienen los totales del blog en la misma transacción.
+type ReactionStore interface {
+	AddReaction(blogID string, apodo string, tipo string) error
+	RemoveReaction(blogID string, apodo string, tipo string) error
+	GetReactionCounts(blogID string) (ReactionCounts, error)
+	GetUserReactions(blogID string, apodo string) ([]string, error)
+}
+
+// ReadingListStore define las operaciones de la base de datos para marcadores y listas de lectura
+type ReadingListStore interface {
+	AddBookmark(apodo string, blogID string) error
+	RemoveBookmark(apodo string, blogID string) error
+	GetBookmarks(apodo string, afterFecha time.Time, afterID string, limit int) ([]Bookmark, error)
+	CreateReadingList(list ReadingList) error
+	GetReadingListByID(id string) (*ReadingList, error)
+	GetReadingLists(apodo string, afterFecha time.Time, afterID string, limit int) ([]ReadingList, error)
+	UpdateReadingList(list ReadingList) error
+	DeleteReadingList(id string) error
+	GetReadingListItems(listID string) ([]Blog, error)
+	AddReadingListItem(listID string, blogID string) error
+	RemoveReadingListItem(listID string, blogID string) error
+	ReorderReadingList(listID string, blogIDs []string) error
+}
+
+// AnalyticsStore guarda las visitas en tablas agregadas por día. Las consultas
+// de estadísticas leen solo esos agregados, nunca las visitas individuales.
+type AnalyticsStore interface {
+	RecordView(view BlogView, window time.Duration) (bool, error)
+	RecordReadProgress(blogID string, visitante string, progreso float64) error
+	PruneVisitors(before time.Time) error
+	GetDailyViews(blogID string, desde time.Time, hasta time.Time) ([]DailyViews, error)
+	GetReferrers(blogID string, desde time.Time, hasta time.Time, limit int) ([]ReferrerCount, error)
+	GetAuthorStats(apodo string, desde time.Time, hasta time.Time) ([]BlogStats, error)
+}
+
+// SearchIndex indexa los blogs publicados para la búsqueda de texto completo
+type SearchIndex interface {
+	Index(blog Blog) error
+	Remove(id string) error
+	Search(query string, after Cursor, limit int) ([]SearchResult, error)
+}
+
+// SimilarityIndex compara el contenido de los blogs indexados. Version cambia
+// con cada blog añadido o eliminado, así que sirve para invalidar cachés.
+type SimilarityIndex interface {
+	Similar(id string, limit int) ([]SearchResult, error)
+	Version() uint64
+}
+
+// RelatedStore obtiene las señales de la base de datos para recomendar blogs
+type RelatedStore interface {
+	GetRelatedCandidates(blog Blog, limit int) ([]RelatedCandidate, error)
+}
+
+// MediaStore define las operaciones de la base de datos para los archivos subidos
+type MediaStore interface {
+	CreateMedia(media Media) error
+	GetPendingMedia(limit int) ([]Media, error)
+	SaveVariants(clave string, variantes []ImagenVariante, blurhash string, color string) error
+	GetImages(claves []string) (map[string]*ImagenResponsive, error)
+}
+
+// CollaboratorStore define las operaciones de la base de datos para los colaboradores de los blogs
+type CollaboratorStore interface {
+	InviteCollaborator(c Colaborador) error
+	GetCollaborator(blogID string, apodo string) (*Colaborador, error)
+	GetCollaborators(blogID string) ([]Colaborador, error)
+	GetInvitations(apodo string, afterFecha time.Time, afterID string, limit int) ([]Colaborador, error)
+	AcceptInvitation(blogID string, apodo string) error
+	UpdateCollaborator(c Colaborador) error
+	RemoveCollaborator(blogID string, apodo string) error
+}
+
+// ModerationStore guarda la cola de revisión de la moderación. Cada recurso
+// tiene como mucho un caso pendiente: crear uno nuevo supera al anterior.
+type ModerationStore interface {
+	CreateCase(c ModerationCase) error
+	SupersedeCases(tipo string, recursoID string) error
+	GetCaseByID(id string) (*ModerationCase, error)
+	GetPendingCase(tipo string, recursoID string) (*ModerationCase, error)
+	GetPendingCases(tipo string, afterFecha time.Time, afterID string, limit int) ([]ModerationCase, error)
+	ResolveCase(id string, estado string, revisor string) error
 }
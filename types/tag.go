@@ -0,0 +1,18 @@
+package types
+
+// Tag es una etiqueta con el número de blogs publicados que la usan
+type Tag struct {
+	ID         string `json:"id"`
+	Nombre     string `json:"nombre"`
+	TotalBlogs int    `json:"total_blogs"`
+}
+
+// RenameTagPayload es el nuevo nombre de una etiqueta
+type RenameTagPayload struct {
+	Nombre string `json:"nombre" validate:"required,max=100"`
+}
+
+// MergeTagPayload indica la etiqueta que absorbe a la de la ruta. Si no existe, se crea.
+type MergeTagPayload struct {
+	Destino string `json:"destino" validate:"required,max=100"`
+}
@@ -0,0 +1,40 @@
+package types
+
+// Formatos de importación
+const (
+	FormatoMarkdown = "markdown" // ZIP de ficheros Markdown con front-matter YAML
+	FormatoWXR      = "wxr"      // Exportación de WordPress
+)
+
+// Acciones de una entrada del informe de importación
+const (
+	AccionCrear  = "crear"
+	AccionOmitir = "omitir"
+	AccionError  = "error"
+)
+
+// ImportEntry es lo que la importación hace, o haría en una simulación, con
+// un documento del archivo
+type ImportEntry struct {
+	Origen    string `json:"origen"` // Fichero del ZIP o elemento del WXR
+	Titulo    string `json:"titulo"`
+	Slug      string `json:"slug"`
+	Estado    string `json:"estado"`
+	Categoria string `json:"categoria"`
+	Autor     string `json:"autor"`
+	Accion    string `json:"accion"`
+	Motivo    string `json:"motivo,omitempty"`
+	BlogID    string `json:"blog_id,omitempty"` // Solo si se creó
+}
+
+// ImportReport resume una importación. Con DryRun no se ha guardado nada y
+// el informe describe lo que pasaría.
+type ImportReport struct {
+	DryRun           bool          `json:"dry_run"`
+	Formato          string        `json:"formato"`
+	Creados          int           `json:"creados"`
+	Omitidos         int           `json:"omitidos"`
+	Errores          int           `json:"errores"`
+	CategoriasNuevas []string      `json:"categorias_nuevas"`
+	Entradas         []ImportEntry `json:"entradas"`
+}
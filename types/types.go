@@ -2,16 +2,44 @@
 // Aquí, definimos tipos que probablemente complicarán tu vida más de lo necesario. ¡Disfruta! 🥳
 package types
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // User 🐄 – El usuario con toda la información "crucial" que has decidido almacenar.
 // Contiene desde el apodo como un número (sí, un número, ¡viva la creatividad!) hasta la fecha de registro que nadie nunca mirará. 🕵️‍♂️
 type User struct {
-	Apodo       string    `json:"apodo"`
-	Nombre      string    `json:"nombre"`
-	Correo      string    `json:"correo"`
-	Contrasenna string    `json:"-"`
-	Registro    time.Time `json:"-"`
+	Apodo           string     `json:"apodo"`
+	Nombre          string     `json:"nombre"`
+	Correo          string     `json:"correo"`
+	Contrasenna     string     `json:"-"`
+	Registro        time.Time  `json:"-"`
+	Rol             string     `json:"rol"`
+	FechaNacimiento *time.Time `json:"-"`
+	EliminadoEn     *time.Time `json:"-"` // Cuentas en la papelera: no pueden iniciar sesión
+}
+
+// Roles de usuario
+const (
+	RolEstudiante = "estudiante"
+	RolProfesor   = "profesor"
+	RolAdmin      = "admin"
+)
+
+// EsModerador indica si el usuario puede moderar contenido ajeno (profesores y administradores)
+func (u *User) EsModerador() bool {
+	return u.Rol == RolProfesor || u.Rol == RolAdmin
+}
+
+// EsMenor indica si el usuario tiene menos de 18 años en la fecha dada.
+// Si no conocemos su fecha de nacimiento lo tratamos como menor, que es lo
+// prudente para la moderación.
+func (u *User) EsMenor(now time.Time) bool {
+	if u.FechaNacimiento == nil {
+		return true
+	}
+	return u.FechaNacimiento.AddDate(18, 0, 0).After(now)
 }
 
 // UserResponse - Estructura específica para respuestas HTTP
@@ -19,6 +47,7 @@ type UserResponse struct {
 	Apodo  string `json:"apodo"`
 	Nombre string `json:"nombre"`
 	Correo string `json:"correo"`
+	Rol    string `json:"rol"`
 }
 
 // ToResponse - Convierte un User a UserResponse
@@ -27,22 +56,132 @@ func (u *User) ToResponse() UserResponse {
 		Apodo:  u.Apodo,
 		Nombre: u.Nombre,
 		Correo: u.Correo,
+		Rol:    u.Rol,
 	}
 }
 
 type Blog struct {
-	ID               string    `json:"id"`
-	Titulo           string    `json:"titulo"`
-	Slug             string    `json:"slug"`
-	Contenido        string    `json:"contenido"`
-	Extracto         string    `json:"extracto"`
-	ImagenPortada    string    `json:"imagen_portada"`
-	FechaPublicacion time.Time `json:"fecha_publicacion"`
-	Estado           string    `json:"estado"`
-	Categoria        string    `json:"categoria"`
-	TiempoLectura    int       `json:"tiempo_lectura"`
-	AutorApodo       string    `json:"autor_apodo"`
-	MetaDescripcion  string    `json:"meta_descripcion"`
-	MetaKeywords     string    `json:"meta_keywords"`
-	Tags             []string  `json:"tags"`
+	ID                 string            `json:"id"`
+	Titulo             string            `json:"titulo"`
+	Slug               string            `json:"slug"`
+	Contenido          string            `json:"contenido"`
+	ContenidoHTML      string            `json:"contenido_html,omitempty"`
+	Extracto           string            `json:"extracto"`
+	ImagenPortada      string            `json:"imagen_portada"`
+	Portada            *ImagenResponsive `json:"portada,omitempty"`
+	FechaPublicacion   time.Time         `json:"fecha_publicacion"`
+	FechaActualizacion time.Time         `json:"fecha_actualizacion"`
+	Estado             string            `json:"estado"`
+	Categoria          string            `json:"categoria"`
+	CategoriaID        string            `json:"categoria_id"`
+	CategoriaSlug      string            `json:"categoria_slug"`
+	TiempoLectura      int               `json:"tiempo_lectura"`
+	AutorApodo         string            `json:"autor_apodo"`
+	Autores            []string          `json:"autores"` // Firma: el autor y los coautores
+	MetaDescripcion    string            `json:"meta_descripcion"`
+	MetaKeywords       string            `json:"meta_keywords"`
+	Tags               []string          `json:"tags"`
+	TablaContenidos    []TocEntry        `json:"tabla_contenidos,omitempty"`
+	Bloques            Bloques           `json:"bloques"` // Contenido interactivo que el Markdown cita con {{bloque:id}}
+	Reacciones         ReactionCounts    `json:"reacciones"`
+	Serie              *SeriesNavigation `json:"serie,omitempty"`
+	EliminadoEn        *time.Time        `json:"eliminado_en,omitempty"` // Solo en la papelera
+	Version            int               `json:"version,omitempty"`      // Aumenta con cada UpdateBlog; es el ETag del blog
+	Idioma             string            `json:"idioma"`
+	GrupoTraduccion    string            `json:"-"`                      // Comparten grupo el original y sus traducciones
+	Traducciones       []Traduccion      `json:"traducciones,omitempty"` // Las demás versiones publicadas
+}
+
+// Traduccion es otra versión publicada de un blog, con su propio slug
+type Traduccion struct {
+	ID     string `json:"id"`
+	Idioma string `json:"idioma"`
+	Slug   string `json:"slug"`
+	Titulo string `json:"titulo"`
+}
+
+// Papelera son los blogs eliminados de un usuario que aún se pueden restaurar.
+// Pasados DiasRetencion días desde EliminadoEn se borran definitivamente.
+type Papelera struct {
+	Blogs         []Blog `json:"blogs"`
+	DiasRetencion int64  `json:"dias_retencion"`
+}
+
+// Tipos de reacción que un lector puede dejar en un blog
+const (
+	ReaccionLike    = "like"
+	ReaccionUtil    = "util"
+	ReaccionConfuso = "confuso"
+)
+
+// Órdenes disponibles para los listados de blogs
+const (
+	OrdenRecientes    = "recientes"
+	OrdenAntiguos     = "antiguos"
+	OrdenPopulares    = "populares" // Por likes y reacciones útiles
+	OrdenLecturaCorta = "lectura_corta"
+	OrdenLecturaLarga = "lectura_larga"
+)
+
+// BlogQuery describe un listado de blogs publicados. Los filtros vacíos no se aplican.
+type BlogQuery struct {
+	Categoria    string   // Slug de la categoría; incluye sus subcategorías
+	Tags         []string // Basta con una de ellas, salvo que TodasLasTags sea true
+	TodasLasTags bool
+	Autor        string
+	Desde        time.Time // Fecha de publicación mínima, inclusive
+	Hasta        time.Time // Fecha de publicación máxima, exclusive
+	TiempoMin    int       // Minutos de lectura, inclusive
+	TiempoMax    int
+	Orden        string
+	Idioma       string // Idioma preferido: una sola versión por grupo de traducción
+	After        Cursor
+	Limit        int
+}
+
+// CursorOf devuelve la posición de un blog en el listado, según su orden
+func (q BlogQuery) CursorOf(blog Blog) Cursor {
+	c := Cursor{Fecha: blog.FechaPublicacion, ID: blog.ID}
+	switch q.Orden {
+	case OrdenPopulares:
+		c.Valor = int64(blog.Reacciones.Like + blog.Reacciones.Util)
+	case OrdenLecturaCorta, OrdenLecturaLarga:
+		c.Valor = int64(blog.TiempoLectura)
+	}
+	return c
+}
+
+// ReactionCounts son los totales de cada tipo de reacción de un blog
+type ReactionCounts struct {
+	Like    int `json:"like"`
+	Util    int `json:"util"`
+	Confuso int `json:"confuso"`
+}
+
+// ReactionSummary son los totales de un blog junto con las reacciones del usuario actual
+type ReactionSummary struct {
+	Reacciones ReactionCounts `json:"reacciones"`
+	Mias       []string       `json:"mias"`
+}
+
+// TocEntry es una entrada de la tabla de contenidos generada a partir de los encabezados
+type TocEntry struct {
+	Nivel  int    `json:"nivel"`
+	Titulo string `json:"titulo"`
+	ID     string `json:"id"`
+}
+
+// SearchResult es un blog encontrado por la búsqueda, con su puntuación y
+// fragmentos resaltados con <mark> por campo (titulo, extracto, contenido, tags)
+type SearchResult struct {
+	Blog       Blog              `json:"blog"`
+	Puntuacion float64           `json:"puntuacion"`
+	Fragmentos map[string]string `json:"fragmentos"`
+}
+
+// CursorOf devuelve la posición del resultado en la búsqueda, que se ordena
+// por puntuación, fecha de publicación e id. Valor guarda los bits de la
+// puntuación para que la página siguiente empiece exactamente detrás.
+func (r SearchResult) CursorOf() Cursor {
+	return Cursor{Fecha: r.Blog.FechaPublicacion, ID: r.Blog.ID, Valor: int64(math.Float64bits(r.Puntuacion))}
 }
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ETag calcula un ETag fuerte a partir del contenido de la respuesta
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// CheckNotModified establece ETag y Last-Modified y, si la petición condicional
+// (If-None-Match / If-Modified-Since) indica que el cliente ya tiene esta versión,
+// responde 304 Not Modified y devuelve true
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match tiene prioridad sobre If-Modified-Since (RFC 9110, sección 13.2.2)
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagMatches(inm, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err == nil && !lastModified.Truncate(time.Second).After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// etagMatches compara con la lista de If-None-Match usando comparación débil
+func etagMatches(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// VersionETag es el ETag de un recurso editable: su número de versión
+func VersionETag(version int) string {
+	return `"v` + strconv.Itoa(version) + `"`
+}
+
+// CheckIfMatch protege una actualización de las escrituras concurrentes.
+// Establece el ETag de la versión actual y exige que If-Match la incluya:
+// sin la cabecera responde 428 y si la versión no coincide 412. En ambos casos
+// devuelve false y el handler solo tiene que terminar.
+func CheckIfMatch(w http.ResponseWriter, r *http.Request, version int) bool {
+	etag := VersionETag(version)
+	w.Header().Set("ETag", etag)
+
+	im := r.Header.Get("If-Match")
+	if im == "" {
+		WriteError(w, http.StatusPreconditionRequired, fmt.Errorf("missing If-Match header"))
+		return false
+	}
+
+	if !etagMatchesStrong(im, etag) {
+		WriteVersionConflict(w, version)
+		return false
+	}
+
+	return true
+}
+
+// WriteVersionConflict responde 412 con la versión actual, para que el
+// cliente recargue el recurso antes de volver a guardar
+func WriteVersionConflict(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", VersionETag(version))
+	_ = WriteJSON(w, http.StatusPreconditionFailed, map[string]any{
+		"error":   "resource was modified by another request",
+		"version": version,
+	})
+}
+
+// etagMatchesStrong compara con la lista de If-Match usando comparación fuerte:
+// un ETag débil nunca coincide (RFC 9110, sección 13.1.1)
+func etagMatchesStrong(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	etag := ETag([]byte("contenido"))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{
+			name:    "Sin cabeceras condicionales",
+			headers: map[string]string{},
+			want:    false,
+		},
+		{
+			name:    "ETag coincide",
+			headers: map[string]string{"If-None-Match": etag},
+			want:    true,
+		},
+		{
+			name:    "ETag débil en lista",
+			headers: map[string]string{"If-None-Match": `"otro", W/` + etag},
+			want:    true,
+		},
+		{
+			name:    "ETag distinto",
+			headers: map[string]string{"If-None-Match": `"otro"`},
+			want:    false,
+		},
+		{
+			name:    "ETag distinto gana a fecha",
+			headers: map[string]string{"If-None-Match": `"otro"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			want:    false,
+		},
+		{
+			name:    "No modificado desde",
+			headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			want:    true,
+		},
+		{
+			name:    "Modificado después",
+			headers: map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			got := CheckNotModified(w, req, etag, lastModified)
+			if got != tt.want {
+				t.Errorf("CheckNotModified() = %v, want %v", got, tt.want)
+			}
+			if got && w.Code != http.StatusNotModified {
+				t.Errorf("CheckNotModified() status = %v, want 304", w.Code)
+			}
+			if w.Header().Get("ETag") != etag {
+				t.Errorf("ETag header = %q, want %q", w.Header().Get("ETag"), etag)
+			}
+			if w.Header().Get("Last-Modified") != lastModified.Format(http.TimeFormat) {
+				t.Errorf("Last-Modified header = %q", w.Header().Get("Last-Modified"))
+			}
+		})
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    bool
+		status  int
+	}{
+		{name: "Sin If-Match", ifMatch: "", want: false, status: http.StatusPreconditionRequired},
+		{name: "Versión actual", ifMatch: `"v3"`, want: true, status: http.StatusOK},
+		{name: "Versión en lista", ifMatch: `"v2", "v3"`, want: true, status: http.StatusOK},
+		{name: "Cualquiera", ifMatch: "*", want: true, status: http.StatusOK},
+		{name: "Versión antigua", ifMatch: `"v2"`, want: false, status: http.StatusPreconditionFailed},
+		{name: "ETag débil", ifMatch: `W/"v3"`, want: false, status: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			got := CheckIfMatch(w, req, 3)
+			if got != tt.want {
+				t.Errorf("CheckIfMatch() = %v, want %v", got, tt.want)
+			}
+			if w.Code != tt.status {
+				t.Errorf("CheckIfMatch() status = %v, want %v", w.Code, tt.status)
+			}
+			if w.Header().Get("ETag") != `"v3"` {
+				t.Errorf("ETag header = %q, want \"v3\"", w.Header().Get("ETag"))
+			}
+			if tt.status == http.StatusPreconditionFailed && !strings.Contains(w.Body.String(), `"version":3`) {
+				t.Errorf("CheckIfMatch() body = %s, want the current version", w.Body.String())
+			}
+		})
+	}
+}
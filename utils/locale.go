@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage devuelve los idiomas de una cabecera Accept-Language
+// ordenados por preferencia (q), en minúsculas. Los de q=0 se descartan.
+func ParseAcceptLanguage(header string) []string {
+	type languageRange struct {
+		tag string
+		q   float64
+	}
+
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, languageRange{tag: tag, q: q})
+	}
+
+	// Estable para que, a igual q, mande el orden de la cabecera
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	tags := make([]string, len(ranges))
+	for i, r := range ranges {
+		tags[i] = r.tag
+	}
+	return tags
+}
+
+// MatchLocale devuelve el primer idioma de supported que satisface alguna de
+// las preferencias, en su orden. "es-MX" acepta "es" y "*" acepta cualquiera.
+// Si ninguna coincide devuelve "".
+func MatchLocale(preferred []string, supported []string) string {
+	for _, tag := range preferred {
+		if tag == "*" && len(supported) > 0 {
+			return supported[0]
+		}
+		for _, locale := range supported {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+		// Sin la variante exacta sirve el idioma base
+		if base, _, ok := strings.Cut(tag, "-"); ok {
+			for _, locale := range supported {
+				if strings.EqualFold(base, locale) {
+					return locale
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"es", []string{"es"}},
+		{"en-US,en;q=0.9,es;q=0.8", []string{"en-us", "en", "es"}},
+		{"es;q=0.5, qu", []string{"qu", "es"}},
+		{"en;q=0, es", []string{"es"}},
+		{"fr;q=0.7, de;q=0.7", []string{"fr", "de"}},
+		{"es;q=abc, en", []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	supported := []string{"es", "en", "qu"}
+
+	tests := []struct {
+		preferred []string
+		want      string
+	}{
+		{nil, ""},
+		{[]string{"en"}, "en"},
+		{[]string{"es-mx"}, "es"},
+		{[]string{"fr", "qu"}, "qu"},
+		{[]string{"fr", "de"}, ""},
+		{[]string{"*"}, "es"},
+		{[]string{"EN"}, "en"},
+	}
+
+	for _, tt := range tests {
+		if got := MatchLocale(tt.preferred, supported); got != tt.want {
+			t.Errorf("MatchLocale(%v) = %q, want %q", tt.preferred, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatchContentType es el tipo de las peticiones PATCH con JSON Merge Patch
+const MergePatchContentType = "application/merge-patch+json"
+
+// MergePatch aplica un JSON Merge Patch (RFC 7396) a target. Un null en el
+// parche elimina la clave; un objeto se fusiona recursivamente y cualquier
+// otro valor, arrays incluidos, sustituye al original.
+func MergePatch(target []byte, patch []byte) ([]byte, error) {
+	var t any
+	if len(bytes.TrimSpace(target)) > 0 {
+		if err := decodeNumbers(target, &t); err != nil {
+			return nil, err
+		}
+	}
+
+	var p any
+	if err := decodeNumbers(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %v", err)
+	}
+
+	return json.Marshal(mergeValue(t, p))
+}
+
+func mergeValue(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergeValue(targetObject[name], value)
+	}
+
+	return targetObject
+}
+
+// decodeNumbers decodifica conservando los números tal cual, sin pasar por float64
+func decodeNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
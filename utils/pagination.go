@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+// EncodeCursor convierte un cursor en una cadena opaca para la URL
+func EncodeCursor(c types.Cursor) string {
+	raw := c.Fecha.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	if c.Valor != 0 {
+		raw += "|" + strconv.FormatInt(c.Valor, 10)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor interpreta un cursor creado con EncodeCursor. La cadena vacía
+// devuelve el cursor vacío, que apunta al principio.
+func DecodeCursor(cursor string) (types.Cursor, error) {
+	if cursor == "" {
+		return types.Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return types.Cursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) < 2 || len(parts) > 3 || parts[1] == "" {
+		return types.Cursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	c := types.Cursor{ID: parts[1]}
+	c.Fecha, err = time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return types.Cursor{}, fmt.Errorf("invalid cursor")
+	}
+	if len(parts) == 3 {
+		c.Valor, err = strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return types.Cursor{}, fmt.Errorf("invalid cursor")
+		}
+	}
+
+	return c, nil
+}
+
+// ParsePagination lee ?cursor, ?limit y ?total. Un limit ausente o fuera de
+// rango se sustituye por defaultLimit; un cursor inválido es un error.
+func ParsePagination(r *http.Request, defaultLimit, maxLimit int) (types.Pagination, error) {
+	query := r.URL.Query()
+
+	after, err := DecodeCursor(query.Get("cursor"))
+	if err != nil {
+		return types.Pagination{}, err
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	total, _ := strconv.ParseBool(query.Get("total"))
+
+	return types.Pagination{After: after, Limit: limit, Total: total}, nil
+}
+
+// NewPage construye una página a partir de items obtenidos con limit+1: el
+// elemento sobrante solo indica que existe una página siguiente
+func NewPage[T any](items []T, limit int, cursorOf func(T) types.Cursor) types.Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	page := types.Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = EncodeCursor(cursorOf(page.Items[limit-1]))
+	}
+
+	return page
+}
+
+// SetPageLinks añade la cabecera Link (RFC 8288) con las páginas first y next
+// de la petición actual
+func SetPageLinks(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	links := []string{pageLink(r, "", "first")}
+	if nextCursor != "" {
+		links = append(links, pageLink(r, nextCursor, "next"))
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// WritePage responde con la página en JSON y sus cabeceras Link
+func WritePage[T any](w http.ResponseWriter, r *http.Request, page types.Page[T]) error {
+	SetPageLinks(w, r, page.NextCursor)
+	return WriteJSON(w, http.StatusOK, page)
+}
+
+// pageLink devuelve la URL de la petición con otro cursor, como valor de Link
+func pageLink(r *http.Request, cursor, rel string) string {
+	query := r.URL.Query()
+	query.Del("cursor")
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	target := r.URL.Path
+	if encoded := query.Encode(); encoded != "" {
+		target += "?" + encoded
+	}
+
+	return fmt.Sprintf(`<%s>; rel="%s"`, target, rel)
+}
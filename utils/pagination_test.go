@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitlab.com/pardalis/pardalis-api/types"
+)
+
+func TestCursor(t *testing.T) {
+	tests := []types.Cursor{
+		{Fecha: time.Date(2024, 9, 10, 12, 0, 0, 123456000, time.UTC), ID: "abc"},
+		{Fecha: time.Date(2024, 9, 10, 12, 0, 0, 0, time.UTC), ID: "def", Valor: 42},
+	}
+
+	for _, c := range tests {
+		got, err := DecodeCursor(EncodeCursor(c))
+		if err != nil {
+			t.Fatalf("DecodeCursor() error = %v", err)
+		}
+		if !got.Fecha.Equal(c.Fecha) || got.ID != c.ID || got.Valor != c.Valor {
+			t.Errorf("DecodeCursor() = %+v, want %+v", got, c)
+		}
+	}
+
+	if c, err := DecodeCursor(""); err != nil || !c.IsZero() {
+		t.Errorf("DecodeCursor(\"\") = %+v, %v, want zero cursor", c, err)
+	}
+	if _, err := DecodeCursor("no es un cursor"); err == nil {
+		t.Errorf("DecodeCursor() accepted an invalid cursor")
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	cursorOf := func(id string) types.Cursor { return types.Cursor{ID: id} }
+
+	page := NewPage([]string{"a", "b", "c"}, 2, cursorOf)
+	if len(page.Items) != 2 || page.NextCursor == "" {
+		t.Fatalf("NewPage() = %+v, want 2 items and a next cursor", page)
+	}
+	if c, _ := DecodeCursor(page.NextCursor); c.ID != "b" {
+		t.Errorf("NewPage() next cursor points to %q, want b", c.ID)
+	}
+
+	if last := NewPage([]string{"a"}, 2, cursorOf); last.NextCursor != "" {
+		t.Errorf("NewPage() last page has next cursor %q", last.NextCursor)
+	}
+	if empty := NewPage[string](nil, 2, cursorOf); empty.Items == nil {
+		t.Errorf("NewPage() items = nil, want empty slice")
+	}
+}
+
+func TestSetPageLinks(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/v1/blogs?orden=populares&cursor=old&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	SetPageLinks(w, r, "next")
+
+	want := `</api/v1/blogs?limit=5&orden=populares>; rel="first", ` +
+		`</api/v1/blogs?cursor=next&limit=5&orden=populares>; rel="next"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Errorf("Link = %s, want %s", got, want)
+	}
+}
@@ -2,20 +2,150 @@
 package utils
 
 import (
-	"regexp"
+	"crypto/sha1"
+	"encoding/hex"
 	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
+// SlugOptions controla cómo se construye un slug
+type SlugOptions struct {
+	RemoveStopwords bool // Elimina palabras vacías en español ("de", "la", "el"...)
+	MaxLength       int  // Longitud máxima; se corta en el último límite de palabra. 0 = sin límite
+}
+
+// DefaultSlugOptions son las opciones que usa GenerateSlug
+var DefaultSlugOptions = SlugOptions{
+	RemoveStopwords: false,
+	MaxLength:       80,
+}
+
+// symbolWords traduce símbolos comunes a palabras en lugar de descartarlos
+var symbolWords = map[rune]string{
+	'&': "y",
+	'@': "arroba",
+	'%': "por ciento",
+	'+': "mas",
+	'€': "euros",
+	'$': "dolares",
+	'£': "libras",
+	'°': "grados",
+}
+
+// transliterations cubre letras que la descomposición Unicode no reduce a ASCII
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d",
+	'ł': "l", 'þ': "th", 'ı': "i", 'ŀ': "l",
+	// Cirílico
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+	// Griego
+	'α': "a", 'β': "b", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// spanishStopwords son las palabras que se eliminan cuando RemoveStopwords está activo
+var spanishStopwords = map[string]bool{
+	"a": true, "al": true, "ante": true, "con": true, "de": true, "del": true,
+	"e": true, "el": true, "en": true, "es": true, "la": true, "las": true,
+	"lo": true, "los": true, "o": true, "para": true, "por": true, "que": true,
+	"se": true, "sin": true, "sobre": true, "su": true, "sus": true, "u": true,
+	"un": true, "una": true, "unas": true, "unos": true, "y": true,
+}
+
+// GenerateSlug convierte un título en un slug legible usando DefaultSlugOptions
 func GenerateSlug(title string) string {
-	// Convertir a minúsculas
-	slug := strings.ToLower(title)
+	return GenerateSlugWithOptions(title, DefaultSlugOptions)
+}
 
-	// Reemplazar caracteres especiales y espacios con guiones
-	reg := regexp.MustCompile(`[^a-z0-9]+`)
-	slug = reg.ReplaceAllString(slug, "-")
+// GenerateSlugWithOptions convierte un título en un slug: normaliza Unicode,
+// translitera acentos, ñ, ü y símbolos, opcionalmente quita palabras vacías
+// y corta en un límite de palabra. Si el título no contiene nada transliterable
+// se devuelve un slug estable derivado de su hash.
+func GenerateSlugWithOptions(title string, opts SlugOptions) string {
+	words := slugWords(title)
 
-	// Eliminar guiones del inicio y final
-	slug = strings.Trim(slug, "-")
+	if opts.RemoveStopwords {
+		filtered := words[:0:0]
+		for _, w := range words {
+			if !spanishStopwords[w] {
+				filtered = append(filtered, w)
+			}
+		}
+		// Si todo eran palabras vacías preferimos conservarlas
+		if len(filtered) > 0 {
+			words = filtered
+		}
+	}
+
+	slug := joinWithLimit(words, opts.MaxLength)
+	if slug == "" && strings.TrimSpace(title) != "" {
+		sum := sha1.Sum([]byte(title))
+		slug = "post-" + hex.EncodeToString(sum[:])[:10]
+	}
 
 	return slug
 }
+
+// slugWords devuelve las palabras ASCII en minúsculas que componen el título
+func slugWords(title string) []string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(norm.NFKD.String(title)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Marca diacrítica separada por NFKD: "ó" -> "o" + "́"
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			// Los apóstrofos no separan palabras: "o'neill" -> "oneill"
+			continue
+		default:
+			if word, ok := symbolWords[r]; ok {
+				b.WriteString(" " + word + " ")
+			} else if word, ok := transliterations[r]; ok {
+				b.WriteString(word)
+			} else {
+				b.WriteRune(' ')
+			}
+		}
+	}
+
+	return strings.Fields(b.String())
+}
+
+// joinWithLimit une las palabras con guiones sin pasar de maxLength,
+// cortando siempre en un límite de palabra
+func joinWithLimit(words []string, maxLength int) string {
+	var b strings.Builder
+
+	for _, w := range words {
+		sep := 0
+		if b.Len() > 0 {
+			sep = 1
+		}
+
+		if maxLength > 0 && b.Len()+sep+len(w) > maxLength {
+			// Una primera palabra más larga que el límite se trunca
+			if b.Len() == 0 {
+				b.WriteString(w[:maxLength])
+			}
+			break
+		}
+
+		if sep == 1 {
+			b.WriteByte('-')
+		}
+		b.WriteString(w)
+	}
+
+	return b.String()
+}
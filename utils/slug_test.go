@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{
+			name:  "Acentos",
+			title: "Introducción a la programación",
+			want:  "introduccion-a-la-programacion",
+		},
+		{
+			name:  "Eñe y diéresis",
+			title: "El pingüino y el año nuevo",
+			want:  "el-pinguino-y-el-ano-nuevo",
+		},
+		{
+			name:  "Mayúsculas acentuadas",
+			title: "ÁRBOLES ÉPICOS ÑANDÚ",
+			want:  "arboles-epicos-nandu",
+		},
+		{
+			name:  "Signos de puntuación españoles",
+			title: "¿Qué es un bucle? ¡Aprende ya!",
+			want:  "que-es-un-bucle-aprende-ya",
+		},
+		{
+			name:  "Símbolos comunes",
+			title: "Gatos & perros: 100% diversión",
+			want:  "gatos-y-perros-100-por-ciento-diversion",
+		},
+		{
+			name:  "Apóstrofos",
+			title: "O'Neill’s guide",
+			want:  "oneills-guide",
+		},
+		{
+			name:  "Ligaduras y letras especiales",
+			title: "Straße ﬁnal Æsir",
+			want:  "strasse-final-aesir",
+		},
+		{
+			name:  "Cirílico",
+			title: "Привет мир",
+			want:  "privet-mir",
+		},
+		{
+			name:  "Griego",
+			title: "Αλφα",
+			want:  "alfa",
+		},
+		{
+			name:  "Espacios y guiones repetidos",
+			title: "  --hola   mundo--  ",
+			want:  "hola-mundo",
+		},
+		{
+			name:  "Vacío",
+			title: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenerateSlug(tt.title); got != tt.want {
+				t.Errorf("GenerateSlug(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSlugWithOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		opts  SlugOptions
+		want  string
+	}{
+		{
+			name:  "Sin palabras vacías",
+			title: "Introducción a la programación con Go",
+			opts:  SlugOptions{RemoveStopwords: true},
+			want:  "introduccion-programacion-go",
+		},
+		{
+			name:  "Solo palabras vacías se conservan",
+			title: "De la a la",
+			opts:  SlugOptions{RemoveStopwords: true},
+			want:  "de-la-a-la",
+		},
+		{
+			name:  "Corte en límite de palabra",
+			title: "Introducción a la programación",
+			opts:  SlugOptions{MaxLength: 20},
+			want:  "introduccion-a-la",
+		},
+		{
+			name:  "Límite exacto",
+			title: "hola mundo",
+			opts:  SlugOptions{MaxLength: 10},
+			want:  "hola-mundo",
+		},
+		{
+			name:  "Primera palabra más larga que el límite",
+			title: "Electroencefalografista",
+			opts:  SlugOptions{MaxLength: 8},
+			want:  "electroe",
+		},
+		{
+			name:  "Sin límite",
+			title: strings.Repeat("palabra ", 30),
+			opts:  SlugOptions{},
+			want:  strings.TrimSuffix(strings.Repeat("palabra-", 30), "-"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenerateSlugWithOptions(tt.title, tt.opts); got != tt.want {
+				t.Errorf("GenerateSlugWithOptions(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSlug_NonTransliterable(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+	}{
+		{name: "Chino", title: "你好世界"},
+		{name: "Emoji", title: "🐄🐄🐄"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateSlug(tt.title)
+			if !strings.HasPrefix(got, "post-") || len(got) != len("post-")+10 {
+				t.Errorf("GenerateSlug(%q) = %q, want stable post- fallback", tt.title, got)
+			}
+			if again := GenerateSlug(tt.title); again != got {
+				t.Errorf("GenerateSlug(%q) not stable: %q != %q", tt.title, got, again)
+			}
+		})
+	}
+}
+
+func TestGenerateSlug_MaxLength(t *testing.T) {
+	title := strings.Repeat("programación ", 20)
+	got := GenerateSlug(title)
+
+	if len(got) > DefaultSlugOptions.MaxLength {
+		t.Errorf("GenerateSlug() length = %d, want <= %d", len(got), DefaultSlugOptions.MaxLength)
+	}
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("GenerateSlug() = %q, must not end with a dash", got)
+	}
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// AbsoluteURL une el host público con una ruta. Las URLs que ya son absolutas se devuelven sin cambios.
+func AbsoluteURL(host, path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(host, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// BlogURL construye el enlace público absoluto a un blog
+func BlogURL(host, slug string) string {
+	return AbsoluteURL(host, "/blogs/"+url.PathEscape(slug))
+}